@@ -12,6 +12,10 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# Images of commands loading Go plugins need glibc, and are built with
+# --build-arg=RUNTIME_IMAGE=gcr.io/distroless/base:nonroot.
+ARG RUNTIME_IMAGE=gcr.io/distroless/static:nonroot
+
 FROM open-match-base-build as builder
 
 WORKDIR /go/src/open-match.dev/open-match
@@ -22,7 +26,7 @@ RUN --mount=type=cache,target=/go/pkg/mod \
     --mount=type=cache,target=/root/.cache/go-build \
     make "build/cmd/${IMAGE_TITLE}"
 
-FROM gcr.io/distroless/static:nonroot
+FROM ${RUNTIME_IMAGE}
 ARG IMAGE_TITLE
 WORKDIR /app/
 
@@ -90,6 +90,9 @@ func (s *matchFunctionService) Run(req *pb.RunRequest, stream pb.MatchFunction_R
 // makeMatches tries to handle backfills at first, then it makes full matches, at the end it makes a match with backfill
 // if tickets left
 func makeMatches(profile *pb.MatchProfile, pool *pb.Pool, tickets []*pb.Ticket, backfills []*pb.Backfill) ([]*pb.Match, error) {
+	// Place the tickets closest to their matchmaking deadline first.
+	matchfunction.SortByMatchmakingDeadline(tickets)
+
 	var matches []*pb.Match
 	newMatches, remainingTickets, err := handleBackfills(profile, tickets, backfills, len(matches))
 	if err != nil {
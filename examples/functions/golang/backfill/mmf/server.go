@@ -24,6 +24,9 @@ import (
 	"net"
 
 	"google.golang.org/grpc"
+	// Accept snappy compressed calls, for Open Match configured with
+	// rpcCompression: snappy.
+	_ "open-match.dev/open-match/pkg/encoding/snappy"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -41,13 +41,21 @@ type matchFunctionService struct {
 }
 
 func makeMatches(poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error) {
-	tickets := map[string]*pb.Ticket{}
+	seen := map[string]struct{}{}
+	var tickets []*pb.Ticket
 	for _, pool := range poolTickets {
 		for _, ticket := range pool {
-			tickets[ticket.GetId()] = ticket
+			if _, ok := seen[ticket.GetId()]; ok {
+				continue
+			}
+			seen[ticket.GetId()] = struct{}{}
+			tickets = append(tickets, ticket)
 		}
 	}
 
+	// Pair the tickets closest to their matchmaking deadline first.
+	matchfunction.SortByMatchmakingDeadline(tickets)
+
 	var matches []*pb.Match
 
 	t := time.Now().Format("2006-01-02T15:04:05.00")
@@ -92,6 +100,16 @@ func (s *matchFunctionService) Run(req *pb.RunRequest, stream pb.MatchFunction_R
 		return err
 	}
 
+	// Don't propose more matches than there are game servers to host them.
+	available, ok, err := matchfunction.QueryAvailableCapacity(stream.Context(), s.queryServiceClient, "")
+	if err != nil {
+		log.Printf("Failed to query server capacity, got %s", err.Error())
+		return err
+	}
+	if ok && len(proposals) > available {
+		proposals = proposals[:available]
+	}
+
 	log.Printf("Streaming %v proposals to Open Match", len(proposals))
 	// Stream the generated proposals back to Open Match.
 	for _, proposal := range proposals {
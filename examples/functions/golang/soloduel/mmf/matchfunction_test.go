@@ -16,7 +16,11 @@ package mmf
 
 import (
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 
 	"github.com/stretchr/testify/require"
@@ -53,3 +57,25 @@ func TestMakeMatches(t *testing.T) {
 		require.Equal(matchName, match.MatchFunction)
 	}
 }
+
+func TestMakeMatchesPrioritizesDeadline(t *testing.T) {
+	require := require.New(t)
+
+	withDeadline := func(id string, d time.Duration) *pb.Ticket {
+		ts, err := ptypes.TimestampProto(time.Now().Add(d))
+		require.Nil(err)
+		a, err := ptypes.MarshalAny(&pb.MatchmakingDeadline{Deadline: ts})
+		require.Nil(err)
+		return &pb.Ticket{Id: id, Extensions: map[string]*any.Any{matchfunction.MatchmakingDeadlineKey: a}}
+	}
+
+	poolNameToTickets := map[string][]*pb.Ticket{
+		"pool1": {{Id: "1"}, withDeadline("2", time.Minute), {Id: "3"}},
+		"pool2": {withDeadline("4", time.Second)},
+	}
+
+	matches, err := makeMatches(poolNameToTickets)
+	require.Nil(err)
+	require.Equal(2, len(matches))
+	require.ElementsMatch([]string{"4", "2"}, []string{matches[0].Tickets[0].Id, matches[0].Tickets[1].Id})
+}
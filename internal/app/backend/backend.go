@@ -19,9 +19,12 @@ import (
 	"go.opencensus.io/stats/view"
 	"google.golang.org/grpc"
 	"open-match.dev/open-match/internal/appmain"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/pubsub"
 	"open-match.dev/open-match/internal/rpc"
 	"open-match.dev/open-match/internal/statestore"
 	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/internal/worker"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -73,12 +76,39 @@ var (
 
 // BindService creates the backend service and binds it to the serving harness.
 func BindService(p *appmain.Params, b *appmain.Bindings) error {
+	idGen, err := config.GetIDs(p.Config()).NewGenerator()
+	if err != nil {
+		return err
+	}
+	events, err := pubsub.New(p.Config())
+	if err != nil {
+		return err
+	}
+	if events != nil {
+		b.AddCloserErr(events.Close)
+	}
 	service := &backendService{
+		cfg:          p.Config(),
 		synchronizer: newSynchronizerClient(p.Config()),
-		store:        statestore.New(p.Config()),
+		store:        pubsub.WithTicketEvents(statestore.New(p.Config()), events),
 		cc:           rpc.NewClientCache(p.Config()),
+		idGen:        idGen,
+		decisions:    b.DecisionLog(),
 	}
 
+	workers := worker.NewPool(p.Config())
+	b.AddCloser(workers.Close)
+	warm := &warmPool{cfg: p.Config(), cc: service.cc}
+	warm.start(workers)
+	startReleasingOrphanedClaims(p.Config(), service.store, workers)
+
+	b.AddDependency("redis", service.store.HealthCheck)
+	b.AddDependency("synchronizer", service.synchronizer.waitForReady)
+	// Only the match functions configured at startup are reported, the list
+	// isn't re-read like it is for warming.
+	for _, endpoint := range config.GetBackend(p.Config()).WarmMatchFunctions {
+		b.AddDependencyProbe("matchFunction "+endpoint, warm.probe(endpoint))
+	}
 	b.AddHealthCheckFunc(service.store.HealthCheck)
 	b.AddHandleFunc(func(s *grpc.Server) {
 		pb.RegisterBackendServiceServer(s, service)
@@ -30,25 +30,33 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/pkg/errors"
-	"github.com/rs/xid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/appmain"
 	"open-match.dev/open-match/internal/appmain/contextcause"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/faultinject"
 	"open-match.dev/open-match/internal/ipb"
 	"open-match.dev/open-match/internal/rpc"
 	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/errorinfo"
+	"open-match.dev/open-match/pkg/idgen"
 	"open-match.dev/open-match/pkg/pb"
 )
 
 // The service implementing the Backend API that is called to generate matches
 // and make assignments for Tickets.
 type backendService struct {
+	cfg          config.View
 	synchronizer *synchronizerClient
 	store        statestore.Service
 	cc           *rpc.ClientCache
+	idGen        idgen.Generator
+	decisions    *appmain.DecisionLog
 }
 
 var (
@@ -64,12 +72,54 @@ var (
 // FetchMatches immediately returns an error if it encounters any execution failures.
 //   - If the synchronizer is enabled, FetchMatch will then call the synchronizer to deduplicate proposals with overlapped tickets.
 func (s *backendService) FetchMatches(req *pb.FetchMatchesRequest, stream pb.BackendService_FetchMatchesServer) error {
+	d := newFetchDecision(req)
+	err := s.fetchMatches(req, &decisionStream{BackendService_FetchMatchesServer: stream, d: d}, d)
+	d.record(s.decisions, err)
+	return err
+}
+
+// fetchMatches implements FetchMatches, noting what it did in d.
+func (s *backendService) fetchMatches(req *pb.FetchMatchesRequest, stream pb.BackendService_FetchMatchesServer, d *fetchDecision) error {
 	if req.Config == nil {
 		return status.Error(codes.InvalidArgument, ".config is required")
 	}
 	if req.Profile == nil {
 		return status.Error(codes.InvalidArgument, ".profile is required")
 	}
+	if req.Profile.MaxMatches < 0 {
+		return status.Error(codes.InvalidArgument, ".profile.max_matches must not be negative")
+	}
+	if req.Profile.MaxTickets < 0 {
+		return status.Error(codes.InvalidArgument, ".profile.max_tickets must not be negative")
+	}
+	if err := authorizePartitions(stream.Context(), s.cfg, req.GetProfile()); err != nil {
+		return err
+	}
+	if err := checkBlackouts(s.cfg, req.GetProfile()); err != nil {
+		return err
+	}
+	paused, err := s.profilePaused(stream.Context(), req.GetProfile())
+	if err != nil {
+		return err
+	}
+	if paused {
+		// Not an error, tickets keep waiting for the profile to be resumed.
+		d.Paused = true
+		stream.SetTrailer(metadata.Pairs(errorinfo.TrailerReason, errorinfo.ReasonPaused))
+		return nil
+	}
+
+	lease, err := s.holdClaims(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer lease.release()
+
+	limiter := newProposalLimiter(req.GetProfile())
+	d.limiter = limiter
+	if req.Profile.EvaluationExempt {
+		return s.fetchMatchesWithoutSynchronizer(req, stream, lease, limiter)
+	}
 
 	// Error group for handling the synchronizer calls only.
 	eg, ctx := errgroup.WithContext(stream.Context())
@@ -88,10 +138,10 @@ func (s *backendService) FetchMatches(req *pb.FetchMatchesRequest, stream pb.Bac
 	m := &sync.Map{}
 
 	eg.Go(func() error {
-		return synchronizeSend(ctx, syncStream, m, proposals)
+		return synchronizeSend(ctx, syncStream, m, limiter, proposals, s.store, lease.owner)
 	})
 	eg.Go(func() error {
-		return synchronizeRecv(ctx, syncStream, m, stream, startMmfs, cancelMmfs, s.store)
+		return synchronizeRecv(ctx, syncStream, m, stream, req.GetIncludeRejections(), startMmfs, cancelMmfs, s.store, s.idGen, lease)
 	})
 
 	var mmfErr error
@@ -99,24 +149,178 @@ func (s *backendService) FetchMatches(req *pb.FetchMatchesRequest, stream pb.Bac
 	case <-mmfCtx.Done():
 		mmfErr = fmt.Errorf("mmf was never started")
 	case <-startMmfs:
-		mmfErr = callMmf(mmfCtx, s.cc, req, proposals)
+		mmfErr = callMmf(mmfCtx, s.cfg, s.cc, req, proposals)
 	}
 
 	syncErr := eg.Wait()
 
 	// TODO: Send mmf error in FetchSummary instead of erroring call.
 	if syncErr != nil || mmfErr != nil {
-		return fmt.Errorf(
+		err := fmt.Errorf(
 			"error(s) in FetchMatches call. syncErr=[%v], mmfErr=[%v]",
 			syncErr,
 			mmfErr,
 		)
+		if syncErr == nil {
+			return matchFunctionError(req, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// authorizePartitions returns PermissionDenied unless the calling director may
+// fetch matches from the partitions of every pool of the profile.
+func authorizePartitions(ctx context.Context, cfg config.View, profile *pb.MatchProfile) error {
+	client := rpc.ClientIdentity(ctx)
+	partitions := config.GetPartitions(cfg)
+	for _, pool := range profile.GetPools() {
+		if partitions.Allowed(client, pool.GetPartition()) {
+			continue
+		}
+		logger.WithFields(logrus.Fields{
+			"client":    client,
+			"partition": pool.GetPartition(),
+			"profile":   profile.GetName(),
+		}).Warning("Backend client is not allowed to fetch matches from the partition, rejecting call.")
+		return status.Errorf(codes.PermissionDenied, "backend client %q is not allowed partition %q of pool %q by %s", client, pool.GetPartition(), pool.GetName(), config.KeyPartitionClients)
 	}
+	return nil
+}
+
+// checkBlackouts returns FailedPrecondition while the profile, or any of its
+// pools, is closed by a blackout window, without running the match function.
+func checkBlackouts(cfg config.View, profile *pb.MatchProfile) error {
+	blackouts := config.GetBlackouts(cfg)
+	now := time.Now()
+	if until, ok := blackouts.Active(config.BlackoutProfile, profile.GetName(), now); ok {
+		end := until.UTC().Format(time.RFC3339)
+		return errorinfo.Errorf(codes.FailedPrecondition, errorinfo.ReasonBlackout, map[string]string{
+			errorinfo.MetadataProfileName: profile.GetName(),
+			errorinfo.MetadataBlackoutEnd: end,
+		}, "profile %q is closed by a blackout window until %s", profile.GetName(), end)
+	}
+	for _, pool := range profile.GetPools() {
+		if until, ok := blackouts.Active(config.BlackoutPool, pool.GetName(), now); ok {
+			end := until.UTC().Format(time.RFC3339)
+			return errorinfo.Errorf(codes.FailedPrecondition, errorinfo.ReasonBlackout, map[string]string{
+				errorinfo.MetadataProfileName: profile.GetName(),
+				errorinfo.MetadataPoolName:    pool.GetName(),
+				errorinfo.MetadataBlackoutEnd: end,
+			}, "pool %q of profile %q is closed by a blackout window until %s", pool.GetName(), profile.GetName(), end)
+		}
+	}
+	return nil
+}
+
+// fetchMatchesWithoutSynchronizer runs the match function for an evaluation
+// exempt profile and returns its proposals directly, without waiting for a
+// synchronization cycle or calling the evaluator.
+func (s *backendService) fetchMatchesWithoutSynchronizer(req *pb.FetchMatchesRequest, stream pb.BackendService_FetchMatchesServer, lease *claimLease, limiter *proposalLimiter) error {
+	eg, ctx := errgroup.WithContext(stream.Context())
+	proposals := make(chan *pb.Match)
+	// Without the synchronizer, the maximum tickets per match of the profile
+	// is enforced here.
+	maxTickets := config.GetSynchronizer(s.cfg).MaxTickets(req.GetProfile().GetName())
+
+	eg.Go(func() error {
+		if err := callMmf(ctx, s.cfg, s.cc, req, proposals); err != nil {
+			return matchFunctionError(req, err)
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		seen := make(map[string]struct{})
+		for p := range proposals {
+			if _, ok := seen[p.GetMatchId()]; ok {
+				return fmt.Errorf("MatchMakingFunction returned same match_id twice: \"%s\"", p.GetMatchId())
+			}
+			seen[p.GetMatchId()] = struct{}{}
+			if maxTickets > 0 && len(p.GetTickets()) > maxTickets {
+				logger.WithFields(logrus.Fields{
+					"match_id":    p.GetMatchId(),
+					"profile":     req.GetProfile().GetName(),
+					"tickets":     len(p.GetTickets()),
+					"max_tickets": maxTickets,
+				}).Warning("Match has more tickets than the maximum per match. Rejecting match.")
+				if req.GetIncludeRejections() {
+					err := stream.Send(&pb.FetchMatchesResponse{Rejection: &pb.MatchRejection{
+						MatchId:    p.GetMatchId(),
+						MaxTickets: int32(maxTickets),
+					}})
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if !limiter.admit(p) {
+				continue
+			}
+
+			skipped, err := s.store.ClaimTickets(ctx, lease.owner, []*pb.Match{p})
+			if err != nil {
+				return err
+			}
+			if len(skipped) > 0 {
+				logger.WithFields(logrus.Fields{
+					"match_id": p.GetMatchId(),
+				}).Info("dropped match proposal with reserved tickets")
+				continue
+			}
 
+			err = sendMatch(ctx, p, stream, s.store, s.idGen, lease)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	err := eg.Wait()
+	if err != nil {
+		return fmt.Errorf("error in FetchMatches call for evaluation exempt profile: %w", err)
+	}
 	return nil
 }
 
-func synchronizeSend(ctx context.Context, syncStream synchronizerStream, m *sync.Map, proposals <-chan *pb.Match) error {
+// proposalLimiter enforces a profile's max_matches and max_tickets on the
+// proposals from a single match function run.
+type proposalLimiter struct {
+	profile    string
+	maxMatches int
+	maxTickets int
+	matches    int
+	tickets    int
+	dropped    int
+}
+
+func newProposalLimiter(profile *pb.MatchProfile) *proposalLimiter {
+	return &proposalLimiter{
+		profile:    profile.GetName(),
+		maxMatches: int(profile.GetMaxMatches()),
+		maxTickets: int(profile.GetMaxTickets()),
+	}
+}
+
+// admit reports whether the proposal fits within the profile's limits, and
+// counts it against them if so.
+func (l *proposalLimiter) admit(p *pb.Match) bool {
+	if l.maxMatches > 0 && l.matches >= l.maxMatches {
+		l.dropped++
+		return false
+	}
+	if l.maxTickets > 0 && l.tickets+len(p.GetTickets()) > l.maxTickets {
+		l.dropped++
+		return false
+	}
+	l.matches++
+	l.tickets += len(p.GetTickets())
+	return true
+}
+
+func synchronizeSend(ctx context.Context, syncStream synchronizerStream, m *sync.Map, limiter *proposalLimiter, proposals <-chan *pb.Match, store statestore.Service, claimOwner string) error {
 sendProposals:
 	for {
 		select {
@@ -126,17 +330,32 @@ sendProposals:
 			if !ok {
 				break sendProposals
 			}
+			if !limiter.admit(p) {
+				continue
+			}
 			_, loaded := m.LoadOrStore(p.GetMatchId(), p)
 			if loaded {
 				return fmt.Errorf("MatchMakingFunction returned same match_id twice: \"%s\"", p.GetMatchId())
 			}
-			err := syncStream.Send(&ipb.SynchronizeRequest{Proposal: p})
+			err := syncStream.Send(&ipb.SynchronizeRequest{Proposal: p, ClaimOwner: claimOwner, Profile: limiter.profile})
 			if err != nil {
 				return fmt.Errorf("error sending proposal to synchronizer: %w", err)
 			}
+			statestore.LogTicketEvent(ctx, store, ticketIDs(p), &pb.TicketEvent{
+				Type:         pb.TicketEvent_PROPOSED,
+				MatchId:      p.GetMatchId(),
+				MatchProfile: p.GetMatchProfile(),
+			})
 		}
 	}
 
+	if limiter.dropped > 0 {
+		logger.WithFields(logrus.Fields{
+			"profile": limiter.profile,
+			"dropped": limiter.dropped,
+		}).Info("dropped match proposals exceeding the profile's max_matches or max_tickets")
+	}
+
 	err := syncStream.CloseSend()
 	if err != nil {
 		return fmt.Errorf("error closing send stream of proposals to synchronizer: %w", err)
@@ -144,7 +363,7 @@ sendProposals:
 	return nil
 }
 
-func synchronizeRecv(ctx context.Context, syncStream synchronizerStream, m *sync.Map, stream pb.BackendService_FetchMatchesServer, startMmfs chan<- struct{}, cancelMmfs contextcause.CancelErrFunc, store statestore.Service) error {
+func synchronizeRecv(ctx context.Context, syncStream synchronizerStream, m *sync.Map, stream pb.BackendService_FetchMatchesServer, includeRejections bool, startMmfs chan<- struct{}, cancelMmfs contextcause.CancelErrFunc, store statestore.Service, idGen idgen.Generator, lease *claimLease) error {
 	var startMmfsOnce sync.Once
 
 	for {
@@ -166,51 +385,123 @@ func synchronizeRecv(ctx context.Context, syncStream synchronizerStream, m *sync
 			cancelMmfs(errors.New("match function ran longer than proposal window, canceling"))
 		}
 
+		if r := resp.GetRejection(); r != nil {
+			logger.WithFields(logrus.Fields{
+				"match_id":              r.GetMatchId(),
+				"colliding_ticket_ids":  r.GetCollidingTicketIds(),
+				"colliding_backfill_id": r.GetCollidingBackfillId(),
+				"winning_match_id":      r.GetWinningMatchId(),
+				"max_tickets":           r.GetMaxTickets(),
+			}).Debug("Evaluator rejected match.")
+
+			if includeRejections {
+				err = stream.Send(&pb.FetchMatchesResponse{Rejection: r})
+				if err != nil {
+					return fmt.Errorf("error sending rejection to FetchMatches caller: %w", err)
+				}
+			}
+			continue
+		}
+
 		if v, ok := m.Load(resp.GetMatchId()); ok {
 			match, ok := v.(*pb.Match)
 			if !ok {
 				return fmt.Errorf("error casting sync map value into *pb.Match: %w", err)
 			}
 
-			backfill := match.GetBackfill()
-			if backfill != nil {
-				ticketIds := make([]string, 0, len(match.Tickets))
+			err = sendMatch(ctx, match, stream, store, idGen, lease)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
 
-				for _, t := range match.Tickets {
-					ticketIds = append(ticketIds, t.Id)
-				}
+// sendMatch stores the match's backfill, if any, confirms the claims of its
+// tickets, and returns the match to the caller of FetchMatches.  A match whose
+// backfill was concurrently updated or deleted is dropped, and its tickets are
+// released.
+func sendMatch(ctx context.Context, match *pb.Match, stream pb.BackendService_FetchMatchesServer, store statestore.Service, idGen idgen.Generator, lease *claimLease) error {
+	backfill := match.GetBackfill()
+	if backfill != nil {
+		ticketIds := make([]string, 0, len(match.Tickets))
+
+		for _, t := range match.Tickets {
+			ticketIds = append(ticketIds, t.Id)
+		}
 
-				err = createOrUpdateBackfill(ctx, backfill, ticketIds, store)
+		err := createOrUpdateBackfill(ctx, backfill, ticketIds, store, idGen)
+		if err != nil {
+			e, ok := status.FromError(err)
+			if err == errBackfillGenerationMismatch || (ok && e.Code() == codes.NotFound) {
+				err = doReleaseTickets(ctx, ticketIds, store)
 				if err != nil {
-					e, ok := status.FromError(err)
-					if err == errBackfillGenerationMismatch || (ok && e.Code() == codes.NotFound) {
-						err = doReleaseTickets(ctx, ticketIds, store)
-						if err != nil {
-							logger.WithError(err).Errorf("failed to remove match tickets from pending release: %v", ticketIds)
-						}
-
-						continue
-					}
-
-					return errors.Wrapf(err, "failed to handle match backfill: %s", match.MatchId)
+					logger.WithError(err).Errorf("failed to remove match tickets from pending release: %v", ticketIds)
 				}
-			}
 
-			stats.Record(ctx, totalBytesPerMatch.M(int64(proto.Size(match))))
-			stats.Record(ctx, ticketsPerMatch.M(int64(len(match.GetTickets()))))
-			err = stream.Send(&pb.FetchMatchesResponse{Match: match})
-			if err != nil {
-				return fmt.Errorf("error sending match to caller of backend: %w", err)
+				return nil
 			}
+
+			return errors.Wrapf(err, "failed to handle match backfill: %s", match.MatchId)
 		}
 	}
+
+	err := lease.confirm(ctx, match)
+	if err != nil {
+		return errors.Wrapf(err, "failed to confirm the ticket claims of match: %s", match.MatchId)
+	}
+
+	stats.Record(ctx, totalBytesPerMatch.M(int64(proto.Size(match))))
+	stats.Record(ctx, ticketsPerMatch.M(int64(len(match.GetTickets()))))
+	err = stream.Send(&pb.FetchMatchesResponse{Match: match})
+	if err != nil {
+		return fmt.Errorf("error sending match to caller of backend: %w", err)
+	}
+	statestore.LogTicketEvent(ctx, store, ticketIDs(match), &pb.TicketEvent{
+		Type:         pb.TicketEvent_MATCHED,
+		MatchId:      match.GetMatchId(),
+		MatchProfile: match.GetMatchProfile(),
+	})
+	return nil
+}
+
+func ticketIDs(match *pb.Match) []string {
+	ids := make([]string, 0, len(match.GetTickets()))
+	for _, t := range match.GetTickets() {
+		ids = append(ids, t.GetId())
+	}
+	return ids
+}
+
+// matchFunctionError annotates an error caused by the match function with
+// which match function and profile it was.
+func matchFunctionError(req *pb.FetchMatchesRequest, err error) error {
+	s := status.Convert(err)
+	return errorinfo.New(s.Code(), errorinfo.ReasonMatchFunctionFailed, map[string]string{
+		errorinfo.MetadataMatchFunctionHost: fmt.Sprintf("%s:%d", req.GetConfig().GetHost(), req.GetConfig().GetPort()),
+		errorinfo.MetadataProfileName:       req.GetProfile().GetName(),
+	}, s.Message())
 }
 
 // callMmf triggers execution of MMFs to fetch match proposals.
-func callMmf(ctx context.Context, cc *rpc.ClientCache, req *pb.FetchMatchesRequest, proposals chan<- *pb.Match) error {
+func callMmf(ctx context.Context, cfg config.View, cc *rpc.ClientCache, req *pb.FetchMatchesRequest, proposals chan<- *pb.Match) error {
 	defer close(proposals)
 	address := fmt.Sprintf("%s:%d", req.GetConfig().GetHost(), req.GetConfig().GetPort())
 
+	malformed, err := faultinject.Get(cfg, faultinject.TargetMatchFunction).Inject(ctx)
+	if err != nil {
+		return err
+	}
+	if malformed {
+		for _, p := range faultinject.MalformedProposals(req.GetProfile()) {
+			select {
+			case proposals <- p:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
 	switch req.GetConfig().GetType() {
 	case pb.FunctionConfig_GRPC:
 		return callGrpcMmf(ctx, cc, req.GetProfile(), address, proposals)
@@ -252,6 +543,12 @@ func callGrpcMmf(ctx context.Context, cc *rpc.ClientCache, profile *pb.MatchProf
 			}
 			return err
 		}
+		if resp.GetProposal() == nil {
+			logger.WithFields(logrus.Fields{
+				"profile": profile.GetName(),
+			}).Warning("match function streamed a response with no proposal, dropping it")
+			continue
+		}
 		select {
 		case proposals <- resp.GetProposal():
 		case <-ctx.Done():
@@ -312,6 +609,12 @@ func callHTTPMmf(ctx context.Context, cc *rpc.ClientCache, profile *pb.MatchProf
 		if err := jsonpb.UnmarshalString(string(item.Result), resp); err != nil {
 			return status.Errorf(codes.Unavailable, "failed to execute json.Unmarshal(%s, &resp): %v", item.Result, err)
 		}
+		if resp.GetProposal() == nil {
+			logger.WithFields(logrus.Fields{
+				"profile": profile.GetName(),
+			}).Warning("match function streamed a response with no proposal, dropping it")
+			continue
+		}
 		select {
 		case proposals <- resp.GetProposal():
 		case <-ctx.Done():
@@ -322,6 +625,155 @@ func callHTTPMmf(ctx context.Context, cc *rpc.ClientCache, profile *pb.MatchProf
 	return nil
 }
 
+// UpdateServerCapacity publishes the number of matches a game server fleet can currently host.
+func (s *backendService) UpdateServerCapacity(ctx context.Context, req *pb.UpdateServerCapacityRequest) (*pb.UpdateServerCapacityResponse, error) {
+	if req.GetCapacity() == nil {
+		return nil, status.Error(codes.InvalidArgument, ".capacity is required")
+	}
+	if req.GetCapacity().GetFleet() == "" {
+		return nil, status.Error(codes.InvalidArgument, ".capacity.fleet is required")
+	}
+	if req.GetCapacity().GetAvailable() < 0 {
+		return nil, status.Error(codes.InvalidArgument, ".capacity.available must not be negative")
+	}
+
+	capacity, ok := proto.Clone(req.GetCapacity()).(*pb.ServerCapacity)
+	if !ok {
+		return nil, status.Error(codes.Internal, "failed to clone input server capacity proto")
+	}
+	capacity.UpdateTime = ptypes.TimestampNow()
+
+	err := s.store.UpdateServerCapacity(ctx, capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateServerCapacityResponse{}, nil
+}
+
+// GetServerCapacity returns the unexpired capacity published for a region, or for all regions.
+func (s *backendService) GetServerCapacity(ctx context.Context, req *pb.GetServerCapacityRequest) (*pb.GetServerCapacityResponse, error) {
+	capacities, err := s.store.GetServerCapacity(ctx, req.GetRegion())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetServerCapacityResponse{Capacities: capacities}, nil
+}
+
+// GetTicketTimeline returns the lifecycle events recorded for a ticket.
+func (s *backendService) GetTicketTimeline(ctx context.Context, req *pb.GetTicketTimelineRequest) (*pb.GetTicketTimelineResponse, error) {
+	if req.GetTicketId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, ".ticket_id is required")
+	}
+	if err := authorizeClient(ctx, config.GetBackend(s.cfg).TimelineClients, config.KeyTicketTimelineClients, "read ticket timelines"); err != nil {
+		return nil, err
+	}
+
+	events, err := s.store.GetTicketTimeline(ctx, req.GetTicketId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTicketTimelineResponse{Events: events}, nil
+}
+
+// authorizeClient returns PermissionDenied unless the caller is one of the
+// clients of the config key, for calls which reveal or change the state of
+// every director's tickets and profiles.
+func authorizeClient(ctx context.Context, clients []string, key, action string) error {
+	client := rpc.ClientIdentity(ctx)
+	for _, allowed := range clients {
+		if allowed == "*" || allowed == client {
+			return nil
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"client": client,
+	}).Warningf("Backend client is not allowed to %s, rejecting call.", action)
+	return status.Errorf(codes.PermissionDenied, "backend client %q is not listed in %s", client, key)
+}
+
+// GetTicketCounts returns the number of tickets created, assigned, deleted and
+// expired per minute.
+func (s *backendService) GetTicketCounts(ctx context.Context, req *pb.GetTicketCountsRequest) (*pb.GetTicketCountsResponse, error) {
+	if req.GetMinutes() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, ".minutes must not be negative, got %d", req.GetMinutes())
+	}
+
+	// Minutes are kept for the retention after they end, so the current minute
+	// is retained on top of the full minutes of the retention.
+	retention := config.GetStateStore(s.cfg).TicketCountsRetention
+	retained := 0
+	if retention > 0 {
+		retained = int(retention/time.Minute) + 1
+	}
+	minutes := int(req.GetMinutes())
+	if minutes == 0 || minutes > retained {
+		minutes = retained
+	}
+
+	counts, err := s.store.GetTicketCounts(ctx, minutes)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTicketCountsResponse{Counts: counts}, nil
+}
+
+// ListPendingTickets returns the pending tickets, and the matches which claimed them.
+// Only tickets of the partitions the caller may use are returned.
+func (s *backendService) ListPendingTickets(ctx context.Context, req *pb.ListPendingTicketsRequest) (*pb.ListPendingTicketsResponse, error) {
+	if err := authorizeClient(ctx, config.GetBackend(s.cfg).PendingTicketsClients, config.KeyPendingTicketsClients, "list pending tickets"); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.store.GetPendingTickets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*pb.PendingTicket
+	for _, p := range pending {
+		if req.GetMatchProfile() == "" || p.GetMatchProfile() == req.GetMatchProfile() {
+			tickets = append(tickets, p)
+		}
+	}
+	tickets, err = filterPendingPartitions(ctx, s.cfg, s.store, tickets)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListPendingTicketsResponse{Tickets: tickets}, nil
+}
+
+// filterPendingPartitions drops the pending tickets of partitions the caller
+// may not use, and those since deleted, whose partition is unknown.
+func filterPendingPartitions(ctx context.Context, cfg config.View, store statestore.Service, pending []*pb.PendingTicket) ([]*pb.PendingTicket, error) {
+	partitions := config.GetPartitions(cfg)
+	if len(partitions.Clients) == 0 || len(pending) == 0 {
+		return pending, nil
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.GetTicketId())
+	}
+	tickets, err := store.GetTickets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	client := rpc.ClientIdentity(ctx)
+	allowed := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		allowed[t.GetId()] = partitions.Allowed(client, t.GetPartition())
+	}
+	filtered := make([]*pb.PendingTicket, 0, len(pending))
+	for _, p := range pending {
+		if allowed[p.GetTicketId()] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
+
 func (s *backendService) ReleaseTickets(ctx context.Context, req *pb.ReleaseTicketsRequest) (*pb.ReleaseTicketsResponse, error) {
 	err := doReleaseTickets(ctx, req.GetTicketIds(), s.store)
 	if err != nil {
@@ -339,9 +791,32 @@ func doReleaseTickets(ctx context.Context, ticketIds []string, store statestore.
 	}
 
 	stats.Record(ctx, ticketsReleased.M(int64(len(ticketIds))))
+	statestore.LogTicketEvent(ctx, store, ticketIds, &pb.TicketEvent{Type: pb.TicketEvent_RELEASED})
 	return nil
 }
 
+// ReserveTickets moves active tickets to pending until the reservation expires.
+func (s *backendService) ReserveTickets(ctx context.Context, req *pb.ReserveTicketsRequest) (*pb.ReserveTicketsResponse, error) {
+	if req.GetTtl() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, ".ttl is required")
+	}
+	ttl, err := ptypes.Duration(req.GetTtl())
+	if err != nil || ttl <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, ".ttl must be a positive duration")
+	}
+	if limit := config.GetBackend(s.cfg).MaxReservationTTL; ttl > limit {
+		return nil, status.Errorf(codes.InvalidArgument, ".ttl must be at most %s, the %s config", limit, config.KeyMaxReservationTTL)
+	}
+
+	resp, err := s.store.ReserveTickets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	statestore.LogTicketEvent(ctx, s.store, resp.GetTicketIds(), &pb.TicketEvent{Type: pb.TicketEvent_RESERVED})
+	return resp, nil
+}
+
 func (s *backendService) ReleaseAllTickets(ctx context.Context, req *pb.ReleaseAllTicketsRequest) (*pb.ReleaseAllTicketsResponse, error) {
 	err := s.store.ReleaseAllTickets(ctx)
 	if err != nil {
@@ -366,9 +841,45 @@ func (s *backendService) AssignTickets(ctx context.Context, req *pb.AssignTicket
 	return resp, nil
 }
 
-func createOrUpdateBackfill(ctx context.Context, backfill *pb.Backfill, ticketIds []string, store statestore.Service) error {
+// AssignMatch sets the Assignment of every Ticket of a previously fetched match.
+func (s *backendService) AssignMatch(ctx context.Context, req *pb.AssignMatchRequest) (*pb.AssignMatchResponse, error) {
+	if req.GetMatchId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, ".match_id is required")
+	}
+	if req.GetAssignment() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, ".assignment is required")
+	}
+
+	ids, err := s.store.GetMatchRoster(ctx, req.GetMatchId())
+	if err != nil {
+		return nil, err
+	}
+
+	assignResp, err := doAssignTickets(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{{TicketIds: ids, Assignment: req.GetAssignment()}},
+	}, s.store)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]struct{}, len(assignResp.GetFailures()))
+	for _, f := range assignResp.GetFailures() {
+		failed[f.GetTicketId()] = struct{}{}
+	}
+	resp := &pb.AssignMatchResponse{Failures: assignResp.GetFailures()}
+	for _, id := range ids {
+		if _, ok := failed[id]; !ok {
+			resp.TicketIds = append(resp.TicketIds, id)
+		}
+	}
+
+	stats.Record(ctx, ticketsAssigned.M(int64(len(resp.GetTicketIds()))))
+	return resp, nil
+}
+
+func createOrUpdateBackfill(ctx context.Context, backfill *pb.Backfill, ticketIds []string, store statestore.Service, idGen idgen.Generator) error {
 	if backfill.Id == "" {
-		backfill.Id = xid.New().String()
+		backfill.Id = idGen.NewID()
 		backfill.CreateTime = ptypes.TimestampNow()
 		backfill.Generation = 1
 		err := store.CreateBackfill(ctx, backfill, ticketIds)
@@ -422,12 +933,15 @@ func doAssignTickets(ctx context.Context, req *pb.AssignTicketsRequest, store st
 		return nil, err
 	}
 
+	assigned := make([]string, 0, len(tickets))
 	for _, ticket := range tickets {
 		err = recordTimeToAssignment(ctx, ticket)
 		if err != nil {
 			logger.WithError(err).Errorf("failed to record time to assignment for ticket %s", ticket.Id)
 		}
+		assigned = append(assigned, ticket.Id)
 	}
+	statestore.LogTicketEvent(ctx, store, assigned, &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED})
 
 	ids := []string{}
 
@@ -0,0 +1,138 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// claimLease records the tickets claimed for a FetchMatches call under an
+// owner id, and renews the owner's lease while the call runs.  When the call
+// returns, the tickets of the matches it didn't return are released.  If the
+// backend dies first, the lease expires and releaseOrphanedClaims on another
+// backend releases them.
+type claimLease struct {
+	owner string
+	store statestore.Service
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// holdClaims takes the lease of a new owner, and renews it until release is
+// called.
+func (s *backendService) holdClaims(ctx context.Context) (*claimLease, error) {
+	l := &claimLease{
+		owner: s.idGen.NewID(),
+		store: s.store,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	timeout := config.GetStateStore(s.cfg).ClaimLeaseTimeout
+	err := s.store.RenewClaimLease(ctx, l.owner, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(timeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				err := s.store.RenewClaimLease(context.Background(), l.owner, timeout)
+				if err != nil {
+					logger.WithFields(logrus.Fields{
+						"error": err.Error(),
+						"owner": l.owner,
+					}).Warning("failed to renew the claim lease of FetchMatches call")
+				}
+			}
+		}
+	}()
+	return l, nil
+}
+
+// release stops renewing the lease, and releases the tickets of the matches
+// which were claimed but never confirmed.  Failures are logged, the claims are
+// then released once the lease expires.
+func (l *claimLease) release() {
+	close(l.stop)
+	<-l.done
+
+	// The call's context is usually done by now.
+	ctx := context.Background()
+	ids, err := l.store.ReleaseClaims(ctx, l.owner)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+			"owner": l.owner,
+		}).Warning("failed to release the unreturned tickets of FetchMatches call")
+		return
+	}
+	recordClaimsReleased(ctx, l.store, ids)
+}
+
+// confirm keeps the tickets of the match pending after the call returns.  It
+// must succeed before the match is returned, so that the tickets of a returned
+// match are never released.
+func (l *claimLease) confirm(ctx context.Context, match *pb.Match) error {
+	return l.store.ConfirmClaims(ctx, l.owner, ticketIDs(match))
+}
+
+// startReleasingOrphanedClaims runs releaseOrphanedClaims every
+// claimLeaseTimeout.
+func startReleasingOrphanedClaims(cfg config.View, store statestore.Service, workers *worker.Pool) {
+	workers.Every("release_orphaned_claims", func() time.Duration {
+		return config.GetStateStore(cfg).ClaimLeaseTimeout
+	}, func(ctx context.Context) error {
+		return releaseOrphanedClaims(ctx, store)
+	})
+}
+
+// releaseOrphanedClaims releases the tickets claimed for FetchMatches calls
+// whose backend stopped renewing their lease without returning them.
+func releaseOrphanedClaims(ctx context.Context, store statestore.Service) error {
+	ids, err := store.ReleaseOrphanedClaims(ctx)
+	recordClaimsReleased(ctx, store, ids)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		logger.WithFields(logrus.Fields{
+			"ticket_ids": ids,
+		}).Info("released tickets claimed by FetchMatches calls whose backend died")
+	}
+	return nil
+}
+
+func recordClaimsReleased(ctx context.Context, store statestore.Service, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	stats.Record(ctx, ticketsReleased.M(int64(len(ids))))
+	statestore.LogTicketEvent(ctx, store, ids, &pb.TicketEvent{Type: pb.TicketEvent_RELEASED})
+}
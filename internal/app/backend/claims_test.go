@@ -0,0 +1,88 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/statestore"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	"open-match.dev/open-match/pkg/idgen"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestClaimLease(t *testing.T) {
+	ctx := context.Background()
+	s, closer := newClaimsService(t)
+	defer closer()
+
+	lease, err := s.holdClaims(ctx)
+	require.NoError(t, err)
+	returned := &pb.Match{MatchId: "a", Tickets: []*pb.Ticket{{Id: "1"}}}
+	unreturned := &pb.Match{MatchId: "b", Tickets: []*pb.Ticket{{Id: "2"}}}
+	requireClaimed(t, ctx, s.store, lease.owner, []*pb.Match{returned, unreturned})
+	require.NoError(t, lease.confirm(ctx, returned))
+
+	// The lease is held, so its claims aren't orphaned.
+	require.NoError(t, releaseOrphanedClaims(ctx, s.store))
+	requirePending(t, s.store, "1", "2")
+
+	lease.release()
+	requirePending(t, s.store, "1")
+}
+
+func TestReleaseOrphanedClaims(t *testing.T) {
+	ctx := context.Background()
+	s, closer := newClaimsService(t)
+	defer closer()
+
+	// The owner's backend died without taking, or after losing, its lease.
+	requireClaimed(t, ctx, s.store, "dead", []*pb.Match{
+		{MatchId: "a", Tickets: []*pb.Ticket{{Id: "1"}, {Id: "2"}}},
+	})
+	requirePending(t, s.store, "1", "2")
+
+	require.NoError(t, releaseOrphanedClaims(ctx, s.store))
+	requirePending(t, s.store)
+}
+
+func newClaimsService(t *testing.T) (*backendService, func()) {
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	idGen, err := idgen.New(idgen.XID, idgen.Options{})
+	require.NoError(t, err)
+	return &backendService{cfg: cfg, store: store, idGen: idGen}, closer
+}
+
+// requireClaimed claims the tickets of every match for owner.
+func requireClaimed(t *testing.T, ctx context.Context, store statestore.Service, owner string, matches []*pb.Match) {
+	t.Helper()
+	skipped, err := store.ClaimTickets(ctx, owner, matches)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+}
+
+func requirePending(t *testing.T, store statestore.Service, ids ...string) {
+	pending, err := store.GetPendingIDSet(context.Background())
+	require.NoError(t, err)
+	want := map[string]struct{}{}
+	for _, id := range ids {
+		want[id] = struct{}{}
+	}
+	require.Equal(t, want, pending)
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"open-match.dev/open-match/internal/appmain"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// fetchMatchesLog is the decision log in which every FetchMatches call is
+// summarized.
+const fetchMatchesLog = "fetchMatches"
+
+// fetchDecision summarizes what a FetchMatches call did, for /decisionz.
+type fetchDecision struct {
+	Profile          string  `json:"profile"`
+	MatchFunction    string  `json:"matchFunction"`
+	EvaluationExempt bool    `json:"evaluationExempt,omitempty"`
+	Paused           bool    `json:"paused,omitempty"`
+	Proposals        int     `json:"proposals"`
+	DroppedProposals int     `json:"droppedProposals,omitempty"`
+	Matches          int     `json:"matches"`
+	Tickets          int     `json:"tickets"`
+	Rejections       int     `json:"rejections,omitempty"`
+	DurationMs       float64 `json:"durationMs"`
+	Error            string  `json:"error,omitempty"`
+
+	start   time.Time
+	limiter *proposalLimiter
+}
+
+func newFetchDecision(req *pb.FetchMatchesRequest) *fetchDecision {
+	d := &fetchDecision{
+		Profile:          req.GetProfile().GetName(),
+		EvaluationExempt: req.GetProfile().GetEvaluationExempt(),
+		start:            time.Now(),
+	}
+	if c := req.GetConfig(); c != nil {
+		d.MatchFunction = fmt.Sprintf("%s://%s:%d", strings.ToLower(c.GetType().String()), c.GetHost(), c.GetPort())
+	}
+	return d
+}
+
+// record completes the summary with the outcome of the call, and adds it to
+// the decision log.  It must only be called once the call's goroutines have
+// finished with the proposal limiter.
+func (d *fetchDecision) record(log *appmain.DecisionLog, err error) {
+	d.DurationMs = float64(time.Since(d.start)) / float64(time.Millisecond)
+	if d.limiter != nil {
+		d.Proposals = d.limiter.matches
+		d.DroppedProposals = d.limiter.dropped
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	log.Record(fetchMatchesLog, d)
+}
+
+// decisionStream counts the matches and rejections returned to the caller of
+// FetchMatches.
+type decisionStream struct {
+	pb.BackendService_FetchMatchesServer
+	d *fetchDecision
+}
+
+func (s *decisionStream) Send(resp *pb.FetchMatchesResponse) error {
+	err := s.BackendService_FetchMatchesServer.Send(resp)
+	if err != nil {
+		return err
+	}
+	if m := resp.GetMatch(); m != nil {
+		s.d.Matches++
+		s.d.Tickets += len(m.GetTickets())
+	}
+	if resp.GetRejection() != nil {
+		s.d.Rejections++
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// +build faultinject
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/faultinject"
+	"open-match.dev/open-match/internal/rpc"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestCallMmfFaults(t *testing.T) {
+	cfg := viper.New()
+	cc := rpc.NewClientCache(cfg)
+	req := &pb.FetchMatchesRequest{
+		Config:  &pb.FunctionConfig{Host: "om-function", Port: 50502, Type: pb.FunctionConfig_GRPC},
+		Profile: &pb.MatchProfile{Name: "profile"},
+	}
+	call := func(ctx context.Context) ([]*pb.Match, error) {
+		proposals := make(chan *pb.Match)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- callMmf(ctx, cfg, cc, req, proposals)
+		}()
+		var got []*pb.Match
+		for p := range proposals {
+			got = append(got, p)
+		}
+		return got, <-errc
+	}
+
+	cfg.Set("faultInjection.matchFunction.mode", faultinject.ModeFail)
+	proposals, err := call(context.Background())
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Empty(t, proposals)
+
+	cfg.Set("faultInjection.matchFunction.mode", faultinject.ModeHang)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = call(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// The match function is still called after the malformed proposals, and
+	// fails as the function type is unsupported.
+	cfg.Set("faultInjection.matchFunction.mode", faultinject.ModeMalformed)
+	req.Config.Type = -1
+	proposals, err = call(context.Background())
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, faultinject.MalformedProposals(req.Profile), proposals)
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// PauseProfiles stops match production for the match profiles, until they are
+// resumed.
+func (s *backendService) PauseProfiles(ctx context.Context, req *pb.PauseProfilesRequest) (*pb.PauseProfilesResponse, error) {
+	if err := authorizeClient(ctx, config.GetBackend(s.cfg).ProfilePauseClients, config.KeyProfilePauseClients, "pause match profiles"); err != nil {
+		return nil, err
+	}
+	if err := validateProfileNames(req.GetProfiles()); err != nil {
+		return nil, err
+	}
+
+	err := s.store.PauseProfiles(ctx, req.GetProfiles())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"profiles": req.GetProfiles(),
+	}).Info("Paused matchmaking for match profiles.")
+	return &pb.PauseProfilesResponse{}, nil
+}
+
+// ResumeProfiles restarts match production for match profiles paused by
+// PauseProfiles.  Profiles paused by the config can't be resumed.
+func (s *backendService) ResumeProfiles(ctx context.Context, req *pb.ResumeProfilesRequest) (*pb.ResumeProfilesResponse, error) {
+	if err := authorizeClient(ctx, config.GetBackend(s.cfg).ProfilePauseClients, config.KeyProfilePauseClients, "resume match profiles"); err != nil {
+		return nil, err
+	}
+	if err := validateProfileNames(req.GetProfiles()); err != nil {
+		return nil, err
+	}
+	configured := configuredPausedProfiles(s.cfg)
+	for _, name := range req.GetProfiles() {
+		if _, ok := configured[name]; ok {
+			return nil, status.Errorf(codes.FailedPrecondition, "profile %q is paused by %s, and can only be resumed by changing it", name, config.KeyPausedProfiles)
+		}
+	}
+
+	err := s.store.ResumeProfiles(ctx, req.GetProfiles())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"profiles": req.GetProfiles(),
+	}).Info("Resumed matchmaking for match profiles.")
+	return &pb.ResumeProfilesResponse{}, nil
+}
+
+// ListPausedProfiles returns the paused match profiles.
+func (s *backendService) ListPausedProfiles(ctx context.Context, req *pb.ListPausedProfilesRequest) (*pb.ListPausedProfilesResponse, error) {
+	paused, err := s.store.GetPausedProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := configuredPausedProfiles(s.cfg)
+	resp := &pb.ListPausedProfilesResponse{
+		Profiles:           paused,
+		ConfiguredProfiles: make([]string, 0, len(configured)),
+	}
+	for name := range configured {
+		resp.ConfiguredProfiles = append(resp.ConfiguredProfiles, name)
+		i := sort.SearchStrings(paused, name)
+		if i == len(paused) || paused[i] != name {
+			resp.Profiles = append(resp.Profiles, name)
+		}
+	}
+	sort.Strings(resp.Profiles)
+	sort.Strings(resp.ConfiguredProfiles)
+	return resp, nil
+}
+
+// profilePaused returns whether match production is paused for the profile.
+func (s *backendService) profilePaused(ctx context.Context, profile *pb.MatchProfile) (bool, error) {
+	if _, ok := configuredPausedProfiles(s.cfg)[profile.GetName()]; ok {
+		return true, nil
+	}
+
+	paused, err := s.store.GetPausedProfiles(ctx)
+	if err != nil {
+		return false, err
+	}
+	i := sort.SearchStrings(paused, profile.GetName())
+	return i < len(paused) && paused[i] == profile.GetName(), nil
+}
+
+func configuredPausedProfiles(cfg config.View) map[string]struct{} {
+	names := config.GetBackend(cfg).PausedProfiles
+	configured := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		configured[name] = struct{}{}
+	}
+	return configured
+}
+
+func validateProfileNames(names []string) error {
+	if len(names) == 0 {
+		return status.Error(codes.InvalidArgument, ".profiles is required")
+	}
+	for _, name := range names {
+		if name == "" {
+			return status.Error(codes.InvalidArgument, ".profiles must not contain empty names")
+		}
+	}
+	return nil
+}
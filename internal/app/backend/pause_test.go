@@ -0,0 +1,101 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestConfiguredPausedProfiles(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(config.KeyPausedProfiles, []string{"ranked", "arena"})
+	cfg.Set(config.KeyProfilePauseClients, []string{"*"})
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	s := &backendService{cfg: cfg, store: store}
+	ctx := utilTesting.NewContext(t)
+
+	_, err := s.PauseProfiles(ctx, &pb.PauseProfilesRequest{Profiles: []string{"casual", "ranked"}})
+	require.NoError(t, err)
+
+	list, err := s.ListPausedProfiles(ctx, &pb.ListPausedProfilesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"arena", "casual", "ranked"}, list.Profiles)
+	require.Equal(t, []string{"arena", "ranked"}, list.ConfiguredProfiles)
+
+	for name, want := range map[string]bool{"ranked": true, "arena": true, "casual": true, "solo": false} {
+		paused, err := s.profilePaused(ctx, &pb.MatchProfile{Name: name})
+		require.NoError(t, err)
+		require.Equal(t, want, paused, name)
+	}
+
+	// Profiles paused by the config stay paused.
+	_, err = s.ResumeProfiles(ctx, &pb.ResumeProfilesRequest{Profiles: []string{"casual", "ranked"}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	paused, err := s.profilePaused(ctx, &pb.MatchProfile{Name: "casual"})
+	require.NoError(t, err)
+	require.True(t, paused)
+
+	_, err = s.ResumeProfiles(ctx, &pb.ResumeProfilesRequest{Profiles: []string{"casual"}})
+	require.NoError(t, err)
+	paused, err = s.profilePaused(ctx, &pb.MatchProfile{Name: "casual"})
+	require.NoError(t, err)
+	require.False(t, paused)
+}
+
+func TestPauseProfilesClients(t *testing.T) {
+	addr, err := net.ResolveTCPAddr("tcp", "10.0.0.1:1234")
+	require.NoError(t, err)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+
+	cfg := viper.New()
+	cfg.Set(config.KeyProfilePauseClients, []string{"10.0.0.2"})
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	s := &backendService{cfg: cfg, store: store}
+
+	_, err = s.PauseProfiles(ctx, &pb.PauseProfilesRequest{Profiles: []string{"ranked"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.ResumeProfiles(ctx, &pb.ResumeProfilesRequest{Profiles: []string{"ranked"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	paused, err := s.profilePaused(ctx, &pb.MatchProfile{Name: "ranked"})
+	require.NoError(t, err)
+	require.False(t, paused)
+
+	cfg.Set(config.KeyProfilePauseClients, []string{"10.0.0.2", "10.0.0.1"})
+	_, err = s.PauseProfiles(ctx, &pb.PauseProfilesRequest{Profiles: []string{"ranked"}})
+	require.NoError(t, err)
+	paused, err = s.profilePaused(ctx, &pb.MatchProfile{Name: "ranked"})
+	require.NoError(t, err)
+	require.True(t, paused)
+
+	_, err = s.ResumeProfiles(ctx, &pb.ResumeProfilesRequest{Profiles: []string{"ranked"}})
+	require.NoError(t, err)
+	paused, err = s.profilePaused(ctx, &pb.MatchProfile{Name: "ranked"})
+	require.NoError(t, err)
+	require.False(t, paused)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestListPendingTickets(t *testing.T) {
+	addr, err := net.ResolveTCPAddr("tcp", "10.0.0.1:1234")
+	require.NoError(t, err)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	s := &backendService{cfg: cfg, store: store}
+
+	tickets := []*pb.Ticket{
+		{Id: "1", Partition: "studio-a"},
+		{Id: "2", Partition: "studio-b"},
+		{Id: "3"},
+	}
+	for _, ticket := range tickets {
+		require.NoError(t, store.CreateTicket(ctx, ticket))
+	}
+	require.NoError(t, store.AddTicketsToPendingRelease(ctx, []*pb.Match{{MatchId: "a", Tickets: tickets}}))
+
+	_, err = s.ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	cfg.Set(config.KeyPendingTicketsClients, []string{"10.0.0.1"})
+	resp, err := s.ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"1", "2", "3"}, pendingIDs(resp))
+
+	// Tickets of other clients' partitions are left out.
+	cfg.Set(config.KeyPartitionClients, []string{"10.0.0.1=studio-a", "10.0.0.2=studio-b"})
+	resp, err = s.ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"1", "3"}, pendingIDs(resp))
+}
+
+func pendingIDs(resp *pb.ListPendingTicketsResponse) []string {
+	var ids []string
+	for _, p := range resp.GetTickets() {
+		ids = append(ids, p.GetTicketId())
+	}
+	return ids
+}
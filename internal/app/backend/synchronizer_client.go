@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 
+	"google.golang.org/grpc"
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/ipb"
 	"open-match.dev/open-match/internal/rpc"
@@ -26,7 +27,7 @@ func newSynchronizerClient(cfg config.View) *synchronizerClient {
 			}
 		}
 
-		return ipb.NewSynchronizerClient(conn), close, nil
+		return conn, close, nil
 	}
 
 	return &synchronizerClient{
@@ -41,9 +42,20 @@ type synchronizerStream interface {
 }
 
 func (sc *synchronizerClient) synchronize(ctx context.Context) (synchronizerStream, error) {
-	client, err := sc.cacher.Get()
+	conn, err := sc.cacher.Get()
 	if err != nil {
 		return nil, err
 	}
-	return client.(ipb.SynchronizerClient).Synchronize(ctx)
+	return ipb.NewSynchronizerClient(conn.(*grpc.ClientConn)).Synchronize(ctx)
+}
+
+// waitForReady blocks until the connection to the synchronizer is
+// established, or ctx is done.
+func (sc *synchronizerClient) waitForReady(ctx context.Context) error {
+	v, err := sc.cacher.Get()
+	if err != nil {
+		return err
+	}
+
+	return rpc.WaitForReady(ctx, v.(*grpc.ClientConn))
 }
@@ -0,0 +1,136 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/connectivity"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/rpc"
+	"open-match.dev/open-match/internal/worker"
+)
+
+// warmPool pre-dials the configured match functions into the client cache
+// used by FetchMatches, and keeps health checking them, so that the first
+// FetchMatches after a deploy doesn't pay for connection establishment and
+// the TLS handshake.  The list is re-read on every check, so match functions
+// added by a config change are dialed without a restart.
+type warmPool struct {
+	cfg config.View
+	cc  *rpc.ClientCache
+}
+
+// start runs warm every warmMatchFunctionsInterval.
+func (w *warmPool) start(workers *worker.Pool) {
+	workers.Every("warm_match_functions", func() time.Duration {
+		return config.GetBackend(w.cfg).WarmMatchFunctionsInterval
+	}, w.warm)
+}
+
+// probe returns a check which reports whether the match function at
+// endpoint is reachable, for the dependencyz page.  Unlike warm, it waits
+// for a gRPC connection to be established.
+func (w *warmPool) probe(endpoint string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return errors.Errorf("invalid endpoint %q", endpoint)
+		}
+
+		switch u.Scheme {
+		case "grpc":
+			conn, err := w.cc.GetGRPC(u.Host)
+			if err != nil {
+				return err
+			}
+			return rpc.WaitForReady(ctx, conn)
+		case "http":
+			return w.checkHTTP(ctx, u.Host)
+		default:
+			return errors.Errorf("invalid scheme %q", u.Scheme)
+		}
+	}
+}
+
+// warm dials every configured match function not yet in the client cache,
+// and health checks them all.  Returns an error if any are unhealthy.
+func (w *warmPool) warm(ctx context.Context) error {
+	unhealthy := 0
+	endpoints := config.GetBackend(w.cfg).WarmMatchFunctions
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			logger.WithFields(logrus.Fields{
+				"endpoint": endpoint,
+			}).Error("Invalid warm match function endpoint, expected grpc://host:port or http://host:port.")
+			continue
+		}
+
+		switch u.Scheme {
+		case "grpc":
+			err = w.checkGRPC(u.Host)
+		case "http":
+			err = w.checkHTTP(ctx, u.Host)
+		default:
+			logger.WithFields(logrus.Fields{
+				"endpoint": endpoint,
+			}).Error("Invalid warm match function scheme, expected grpc or http.")
+			continue
+		}
+
+		if err != nil {
+			unhealthy++
+			logger.WithFields(logrus.Fields{
+				"endpoint": endpoint,
+				"error":    err.Error(),
+			}).Warning("Warm match function is unhealthy.")
+		}
+	}
+
+	if unhealthy > 0 {
+		return errors.Errorf("%d of %d warm match functions are unhealthy", unhealthy, len(endpoints))
+	}
+	return nil
+}
+
+// checkGRPC dials the match function if needed.  gRPC keeps the connection
+// established and reconnects on its own, so the check only reports it.
+func (w *warmPool) checkGRPC(address string) error {
+	conn, err := w.cc.GetGRPC(address)
+	if err != nil {
+		return err
+	}
+
+	if state := conn.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+		return errors.Errorf("connection is %s", state)
+	}
+	return nil
+}
+
+// checkHTTP calls the match function's health check, which also keeps the
+// client's idle connection open.
+func (w *warmPool) checkHTTP(ctx context.Context, address string) error {
+	client, baseURL, err := w.cc.GetHTTP(address)
+	if err != nil {
+		return err
+	}
+
+	return rpc.CheckHTTPHealth(ctx, client, baseURL)
+}
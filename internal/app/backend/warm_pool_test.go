@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/rpc"
+	"open-match.dev/open-match/internal/telemetry"
+)
+
+func TestWarmPoolHTTP(t *testing.T) {
+	var checks int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, telemetry.HealthCheckEndpoint, r.URL.Path)
+		atomic.AddInt32(&checks, 1)
+	}))
+	defer ts.Close()
+
+	cfg := viper.New()
+	cfg.Set(config.KeyWarmMatchFunctions, []string{ts.URL})
+	w := &warmPool{cfg: cfg, cc: rpc.NewClientCache(cfg)}
+
+	require.NoError(t, w.warm(context.Background()))
+	require.NoError(t, w.warm(context.Background()))
+	require.Equal(t, int32(2), atomic.LoadInt32(&checks))
+
+	// FetchMatches reuses the warmed client.
+	address := strings.TrimPrefix(ts.URL, "http://")
+	c1, _, err := w.cc.GetHTTP(address)
+	require.NoError(t, err)
+	c2, _, err := w.cc.GetHTTP(address)
+	require.NoError(t, err)
+	require.True(t, c1 == c2)
+}
+
+func TestWarmPoolGRPC(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	go s.Serve(l)
+	defer s.Stop()
+
+	cfg := viper.New()
+	cfg.Set(config.KeyWarmMatchFunctions, []string{"grpc://" + l.Addr().String()})
+	w := &warmPool{cfg: cfg, cc: rpc.NewClientCache(cfg)}
+
+	// Warming alone establishes the connection, before any call is made.
+	require.NoError(t, w.warm(context.Background()))
+	conn, err := w.cc.GetGRPC(l.Addr().String())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		require.True(t, conn.WaitForStateChange(ctx, state), "connection never became ready")
+	}
+}
+
+func TestWarmPoolProbe(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	go s.Serve(l)
+	defer s.Stop()
+
+	cfg := viper.New()
+	w := &warmPool{cfg: cfg, cc: rpc.NewClientCache(cfg)}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The gRPC probe waits for the connection to be established.
+	require.NoError(t, w.probe("grpc://"+l.Addr().String())(ctx))
+	conn, err := w.cc.GetGRPC(l.Addr().String())
+	require.NoError(t, err)
+	require.Equal(t, connectivity.Ready, conn.GetState())
+
+	require.EqualError(t, w.probe(ts.URL)(ctx), "health check returned 503 Service Unavailable")
+	require.Error(t, w.probe("tcp://"+l.Addr().String())(ctx))
+}
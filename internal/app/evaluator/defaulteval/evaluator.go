@@ -27,6 +27,7 @@ import (
 	"go.opencensus.io/stats/view"
 	"open-match.dev/open-match/internal/app/evaluator"
 	"open-match.dev/open-match/internal/appmain"
+	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -48,11 +49,18 @@ var (
 type matchInp struct {
 	match *pb.Match
 	inp   *pb.DefaultEvaluationCriteria
+	// boost is the sum of the boosts of the match's tickets, added to the
+	// score when deciding which of the colliding matches wins.
+	boost float64
+}
+
+func (m *matchInp) score() float64 {
+	return m.inp.GetScore() + m.boost
 }
 
 // BindService define the initialization steps for this evaluator
 func BindService(p *appmain.Params, b *appmain.Bindings) error {
-	if err := evaluator.BindServiceFor(evaluate)(p, b); err != nil {
+	if err := evaluator.BindRejectingServiceFor(evaluate)(p, b); err != nil {
 		return err
 	}
 	b.RegisterViews(collidedMatchesPerEvaluateView)
@@ -61,7 +69,8 @@ func BindService(p *appmain.Params, b *appmain.Bindings) error {
 
 // evaluate sorts the matches by DefaultEvaluationCriteria.Score (optional),
 // then returns matches which don't collide with previously returned matches.
-func evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+// Colliding matches are explained on rejected.
+func evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string, rejected chan<- *pb.MatchRejection) error {
 	matches := make([]*matchInp, 0)
 	nilEvaluationInputs := 0
 
@@ -87,6 +96,7 @@ func evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string) error
 		matches = append(matches, &matchInp{
 			match: m,
 			inp:   inp,
+			boost: ticketBoost(m),
 		})
 	}
 
@@ -113,9 +123,32 @@ func evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string) error
 		out <- id
 	}
 
+	for _, r := range d.rejections {
+		rejected <- r
+	}
+
 	return nil
 }
 
+// ticketBoost returns the sum of the boosts of the match's tickets.  Invalid
+// boosts are ignored, the frontend rejects tickets created with them.
+func ticketBoost(m *pb.Match) float64 {
+	total := 0.0
+	for _, t := range m.GetTickets() {
+		boost, err := matchfunction.GetBoost(t)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"match_id":  m.GetMatchId(),
+				"ticket_id": t.GetId(),
+				"error":     err,
+			}).Warning("Ignoring ticket's invalid boost.")
+			continue
+		}
+		total += boost
+	}
+	return total
+}
+
 type collidingMatch struct {
 	id    string
 	score float64
@@ -123,6 +156,7 @@ type collidingMatch struct {
 
 type decollider struct {
 	resultIDs     []string
+	rejections    []*pb.MatchRejection
 	ticketsUsed   map[string]*collidingMatch
 	backfillsUsed map[string]*collidingMatch
 }
@@ -133,38 +167,60 @@ func (d *decollider) maybeAdd(m *matchInp) {
 			logger.WithFields(logrus.Fields{
 				"match_id":              m.match.GetMatchId(),
 				"backfill_id":           m.match.Backfill.Id,
-				"match_score":           m.inp.GetScore(),
+				"match_score":           m.score(),
 				"colliding_match_id":    cm.id,
 				"colliding_match_score": cm.score,
 			}).Info("Higher quality match with colliding backfill found. Rejecting match.")
+			d.rejections = append(d.rejections, &pb.MatchRejection{
+				MatchId:             m.match.GetMatchId(),
+				CollidingBackfillId: m.match.Backfill.Id,
+				WinningMatchId:      cm.id,
+			})
 			return
 		}
 	}
 
+	var rejection *pb.MatchRejection
 	for _, t := range m.match.GetTickets() {
-		if cm, ok := d.ticketsUsed[t.Id]; ok {
+		cm, ok := d.ticketsUsed[t.Id]
+		if !ok {
+			continue
+		}
+		if rejection == nil {
 			logger.WithFields(logrus.Fields{
 				"match_id":              m.match.GetMatchId(),
 				"ticket_id":             t.GetId(),
-				"match_score":           m.inp.GetScore(),
+				"match_score":           m.score(),
 				"colliding_match_id":    cm.id,
 				"colliding_match_score": cm.score,
 			}).Info("Higher quality match with colliding ticket found. Rejecting match.")
-			return
+			rejection = &pb.MatchRejection{
+				MatchId:        m.match.GetMatchId(),
+				WinningMatchId: cm.id,
+			}
 		}
+		// Only report the tickets claimed by the same winning match, so the
+		// rejection points at a single culprit.
+		if cm.id == rejection.WinningMatchId {
+			rejection.CollidingTicketIds = append(rejection.CollidingTicketIds, t.GetId())
+		}
+	}
+	if rejection != nil {
+		d.rejections = append(d.rejections, rejection)
+		return
 	}
 
 	if m.match.Backfill != nil && m.match.Backfill.Id != "" {
 		d.backfillsUsed[m.match.Backfill.Id] = &collidingMatch{
 			id:    m.match.GetMatchId(),
-			score: m.inp.GetScore(),
+			score: m.score(),
 		}
 	}
 
 	for _, t := range m.match.GetTickets() {
 		d.ticketsUsed[t.Id] = &collidingMatch{
 			id:    m.match.GetMatchId(),
-			score: m.inp.GetScore(),
+			score: m.score(),
 		}
 	}
 
@@ -181,6 +237,11 @@ func (m byScore) Swap(i, j int) {
 	m[i], m[j] = m[j], m[i]
 }
 
+// Less orders matches by their boosted score.  Boosts still break ties
+// between matches without evaluation criteria, whose score is -Inf.
 func (m byScore) Less(i, j int) bool {
-	return m[i].inp.Score > m[j].inp.Score
+	if si, sj := m[i].score(), m[j].score(); si != sj {
+		return si > sj
+	}
+	return m[i].boost > m[j].boost
 }
@@ -16,12 +16,14 @@ package defaulteval
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -184,12 +186,13 @@ func TestEvaluate(t *testing.T) {
 			t.Parallel()
 			in := make(chan *pb.Match, 10)
 			out := make(chan string, 10)
+			rejected := make(chan *pb.MatchRejection, 10)
 			for _, m := range test.testMatches {
 				in <- m
 			}
 			close(in)
 
-			err := evaluate(context.Background(), in, out)
+			err := evaluate(context.Background(), in, out, rejected)
 			require.Nil(t, err)
 
 			gotMatchIDs := []string{}
@@ -205,3 +208,152 @@ func TestEvaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateRejections(t *testing.T) {
+	score := func(s float64) map[string]*any.Any {
+		return map[string]*any.Any{
+			"evaluation_input": mustAny(&pb.DefaultEvaluationCriteria{
+				Score: s,
+			}),
+		}
+	}
+
+	ticket12Score10 := &pb.Match{
+		MatchId:    "ticket12Score10",
+		Tickets:    []*pb.Ticket{{Id: "1"}, {Id: "2"}},
+		Extensions: score(10),
+	}
+	ticket123Score5 := &pb.Match{
+		MatchId:    "ticket123Score5",
+		Tickets:    []*pb.Ticket{{Id: "1"}, {Id: "2"}, {Id: "3"}},
+		Extensions: score(5),
+	}
+	ticket4Backfill1Score20 := &pb.Match{
+		MatchId:    "ticket4Backfill1Score20",
+		Tickets:    []*pb.Ticket{{Id: "4"}},
+		Backfill:   &pb.Backfill{Id: "1"},
+		Extensions: score(20),
+	}
+	ticket5Backfill1Score1 := &pb.Match{
+		MatchId:    "ticket5Backfill1Score1",
+		Tickets:    []*pb.Ticket{{Id: "5"}},
+		Backfill:   &pb.Backfill{Id: "1"},
+		Extensions: score(1),
+	}
+
+	in := make(chan *pb.Match, 10)
+	out := make(chan string, 10)
+	rejected := make(chan *pb.MatchRejection, 10)
+	for _, m := range []*pb.Match{ticket123Score5, ticket12Score10, ticket5Backfill1Score1, ticket4Backfill1Score20} {
+		in <- m
+	}
+	close(in)
+
+	err := evaluate(context.Background(), in, out, rejected)
+	require.Nil(t, err)
+	close(out)
+	close(rejected)
+
+	gotMatchIDs := []string{}
+	for id := range out {
+		gotMatchIDs = append(gotMatchIDs, id)
+	}
+	require.ElementsMatch(t, []string{ticket12Score10.MatchId, ticket4Backfill1Score20.MatchId}, gotMatchIDs)
+
+	gotRejections := []*pb.MatchRejection{}
+	for r := range rejected {
+		gotRejections = append(gotRejections, r)
+	}
+	require.Len(t, gotRejections, 2)
+	require.True(t, proto.Equal(&pb.MatchRejection{
+		MatchId:            ticket123Score5.MatchId,
+		CollidingTicketIds: []string{"1", "2"},
+		WinningMatchId:     ticket12Score10.MatchId,
+	}, gotRejections[0]), gotRejections[0])
+	require.True(t, proto.Equal(&pb.MatchRejection{
+		MatchId:             ticket5Backfill1Score1.MatchId,
+		CollidingBackfillId: "1",
+		WinningMatchId:      ticket4Backfill1Score20.MatchId,
+	}, gotRejections[1]), gotRejections[1])
+}
+
+func TestEvaluateBoost(t *testing.T) {
+	boosted := func(id string, boost float64) *pb.Ticket {
+		return &pb.Ticket{
+			Id: id,
+			Extensions: map[string]*any.Any{
+				matchfunction.BoostKey: mustAny(&pb.TicketBoost{Boost: boost}),
+			},
+		}
+	}
+	score := func(s float64) map[string]*any.Any {
+		return map[string]*any.Any{
+			"evaluation_input": mustAny(&pb.DefaultEvaluationCriteria{
+				Score: s,
+			}),
+		}
+	}
+
+	tests := []struct {
+		description string
+		testMatches []*pb.Match
+		wantMatchID string
+	}{
+		{
+			description: "boost outweighs a higher score",
+			testMatches: []*pb.Match{
+				{MatchId: "score10", Tickets: []*pb.Ticket{{Id: "1"}, {Id: "2"}}, Extensions: score(10)},
+				{MatchId: "score5Boost3x2", Tickets: []*pb.Ticket{{Id: "1"}, boosted("3", 3), boosted("4", 3)}, Extensions: score(5)},
+			},
+			wantMatchID: "score5Boost3x2",
+		},
+		{
+			description: "higher score outweighs a smaller boost",
+			testMatches: []*pb.Match{
+				{MatchId: "score10", Tickets: []*pb.Ticket{{Id: "1"}, {Id: "2"}}, Extensions: score(10)},
+				{MatchId: "score5Boost3", Tickets: []*pb.Ticket{{Id: "1"}, boosted("3", 3)}, Extensions: score(5)},
+			},
+			wantMatchID: "score10",
+		},
+		{
+			description: "boost breaks ties between matches without criteria",
+			testMatches: []*pb.Match{
+				{MatchId: "noCriteria", Tickets: []*pb.Ticket{{Id: "1"}}},
+				{MatchId: "noCriteriaBoost1", Tickets: []*pb.Ticket{{Id: "1"}, boosted("2", 1)}},
+			},
+			wantMatchID: "noCriteriaBoost1",
+		},
+		{
+			description: "invalid boost is ignored",
+			testMatches: []*pb.Match{
+				{MatchId: "score10", Tickets: []*pb.Ticket{{Id: "1"}}, Extensions: score(10)},
+				{MatchId: "score5BoostNaN", Tickets: []*pb.Ticket{{Id: "1"}, boosted("2", math.NaN())}, Extensions: score(5)},
+			},
+			wantMatchID: "score10",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			in := make(chan *pb.Match, 10)
+			out := make(chan string, 10)
+			rejected := make(chan *pb.MatchRejection, 10)
+			for _, m := range test.testMatches {
+				in <- m
+			}
+			close(in)
+
+			err := evaluate(context.Background(), in, out, rejected)
+			require.Nil(t, err)
+			close(out)
+
+			gotMatchIDs := []string{}
+			for id := range out {
+				gotMatchIDs = append(gotMatchIDs, id)
+			}
+			require.Equal(t, []string{test.wantMatchID}, gotMatchIDs)
+		})
+	}
+}
@@ -16,6 +16,8 @@
 package evaluator
 
 import (
+	"context"
+
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"google.golang.org/grpc"
@@ -44,6 +46,14 @@ var (
 
 // BindServiceFor creates the evaluator service and binds it to the serving harness.
 func BindServiceFor(eval Evaluator) appmain.Bind {
+	return BindRejectingServiceFor(func(ctx context.Context, in <-chan *pb.Match, out chan<- string, _ chan<- *pb.MatchRejection) error {
+		return eval(ctx, in, out)
+	})
+}
+
+// BindRejectingServiceFor creates the evaluator service for an evaluator which
+// explains its rejections, and binds it to the serving harness.
+func BindRejectingServiceFor(eval RejectingEvaluator) appmain.Bind {
 	return func(p *appmain.Params, b *appmain.Bindings) error {
 		b.AddHandleFunc(func(s *grpc.Server) {
 			pb.RegisterEvaluatorServer(s, &evaluatorService{eval})
@@ -30,10 +30,15 @@ import (
 // and the Evaluator will return an accepted list of Matches.
 type Evaluator func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error
 
+// RejectingEvaluator is an Evaluator which also explains the matches it
+// rejects, by sending a MatchRejection for them on rejected.  Rejections are
+// returned to directors which request them, to help debug why matches vanish.
+type RejectingEvaluator func(ctx context.Context, in <-chan *pb.Match, out chan<- string, rejected chan<- *pb.MatchRejection) error
+
 // evaluatorService implements pb.EvaluatorServer, the server generated by
 // compiling the protobuf, by fulfilling the pb.EvaluatorServer interface.
 type evaluatorService struct {
-	evaluate Evaluator
+	evaluate RejectingEvaluator
 }
 
 // Evaluate is this harness's implementation of the gRPC call defined in
@@ -43,6 +48,7 @@ func (s *evaluatorService) Evaluate(stream pb.Evaluator_EvaluateServer) error {
 
 	in := make(chan *pb.Match)
 	out := make(chan string)
+	rejected := make(chan *pb.MatchRejection)
 
 	g.Go(func() error {
 		defer close(in)
@@ -67,21 +73,43 @@ func (s *evaluatorService) Evaluate(stream pb.Evaluator_EvaluateServer) error {
 	})
 	g.Go(func() error {
 		defer close(out)
-		return s.evaluate(ctx, in, out)
+		defer close(rejected)
+		return s.evaluate(ctx, in, out, rejected)
 	})
 	g.Go(func() error {
 		defer func() {
 			for range out {
 			}
+			for range rejected {
+			}
 		}()
 
+		// Copies which are set to nil once closed, so the drain above still
+		// ranges over the original channels.
+		ids, rs := out, rejected
 		count := 0
-		for id := range out {
-			err := stream.Send(&pb.EvaluateResponse{MatchId: id})
+		for ids != nil || rs != nil {
+			var resp *pb.EvaluateResponse
+			select {
+			case id, ok := <-ids:
+				if !ok {
+					ids = nil
+					continue
+				}
+				resp = &pb.EvaluateResponse{MatchId: id}
+				count++
+			case r, ok := <-rs:
+				if !ok {
+					rs = nil
+					continue
+				}
+				resp = &pb.EvaluateResponse{Rejection: r}
+			}
+
+			err := stream.Send(resp)
 			if err != nil {
 				return err
 			}
-			count++
 		}
 		stats.Record(ctx, matchesPerEvaluateResponse.M(int64(count)))
 		return nil
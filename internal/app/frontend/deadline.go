@@ -0,0 +1,136 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/matchfunction"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Most expired tickets claimed from the state store at once.
+const expiredTicketsBatch = 1000
+
+// ticketDeadlines removes the tickets whose matchmaking deadline passed
+// without an assignment from matchmaking, whether or not a client is watching
+// their assignment.  Tickets are tracked from their creation.
+type ticketDeadlines struct {
+	cfg     config.View
+	store   statestore.Service
+	workers *worker.Pool
+}
+
+func newTicketDeadlines(cfg config.View, store statestore.Service, workers *worker.Pool) *ticketDeadlines {
+	return &ticketDeadlines{
+		cfg:     cfg,
+		store:   store,
+		workers: workers,
+	}
+}
+
+// start removes expired tickets every ticketDeadlineInterval, so they stay
+// in matchmaking at most that long after their deadline.
+func (d *ticketDeadlines) start() {
+	d.workers.Every("expire_ticket_deadlines", func() time.Duration {
+		return config.GetFrontend(d.cfg).TicketDeadlineInterval
+	}, d.expire)
+}
+
+// track records the deadlines of the tickets which have one.  Failing to
+// record a deadline only means the ticket stays in matchmaking until a
+// WatchAssignments stream sees it expire, so callers log errors rather than
+// fail.
+func (d *ticketDeadlines) track(ctx context.Context, tickets ...*pb.Ticket) {
+	deadlines := make(map[string]time.Time)
+	for _, ticket := range tickets {
+		// Deadlines were validated by CreateTicket.
+		if deadline, ok, _ := matchfunction.GetMatchmakingDeadline(ticket); ok {
+			deadlines[ticket.GetId()] = deadline
+		}
+	}
+	if err := d.store.AddTicketDeadlines(ctx, deadlines); err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"tickets": len(deadlines),
+		}).Warning("failed to record ticket deadlines")
+	}
+}
+
+// expire removes the unassigned tickets whose deadline passed from
+// matchmaking, and counts them as expired.  Tickets which failed to be
+// removed are tracked again, so that the next run retries them.
+func (d *ticketDeadlines) expire(ctx context.Context) error {
+	for {
+		now := time.Now()
+		ids, err := d.store.ClaimExpiredTickets(ctx, now, expiredTicketsBatch)
+		if err != nil {
+			return err
+		}
+
+		retry := make(map[string]time.Time)
+		removed := 0
+		// Tickets deleted by their clients are still tracked until their
+		// deadline.
+		tickets, err := d.store.GetTickets(ctx, ids)
+		if err != nil {
+			for _, id := range ids {
+				retry[id] = now
+			}
+		}
+		for _, ticket := range tickets {
+			if ticket.GetAssignment() != nil {
+				continue
+			}
+			if deindexErr := d.store.DeindexTicket(ctx, ticket.GetId()); deindexErr != nil {
+				retry[ticket.GetId()] = now
+				if err == nil {
+					err = deindexErr
+				}
+				continue
+			}
+			removed++
+		}
+
+		if removed > 0 {
+			logger.WithFields(logrus.Fields{
+				"count": removed,
+			}).Info("Removed tickets past their matchmaking deadline from matchmaking.")
+		}
+		if countErr := d.store.CountExpiredTickets(ctx, removed); countErr != nil {
+			logger.WithFields(logrus.Fields{
+				"error": countErr.Error(),
+			}).Warning("failed to count expired tickets")
+		}
+		if err != nil {
+			if retryErr := d.store.AddTicketDeadlines(ctx, retry); retryErr != nil {
+				logger.WithFields(logrus.Fields{
+					"error":   retryErr.Error(),
+					"tickets": len(retry),
+				}).Error("failed to track expired tickets again, they stay in matchmaking")
+			}
+			return err
+		}
+
+		if len(ids) < expiredTicketsBatch {
+			return nil
+		}
+	}
+}
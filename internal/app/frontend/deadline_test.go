@@ -0,0 +1,85 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/matchfunction"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketDeadlinesExpire(t *testing.T) {
+	ctx := utilTesting.NewContext(t)
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	cfg.Set(config.KeyTicketCountsRetention, "10m")
+	workers := worker.NewPool(cfg)
+	defer workers.Close()
+	d := newTicketDeadlines(cfg, store, workers)
+	fs := frontendService{cfg: cfg, store: store, idGen: newXIDGenerator(t), deadlines: d}
+
+	withDeadline := func(deadline time.Time) *pb.Ticket {
+		ts, err := ptypes.TimestampProto(deadline)
+		require.NoError(t, err)
+		ext, err := ptypes.MarshalAny(&pb.MatchmakingDeadline{Deadline: ts})
+		require.NoError(t, err)
+		return &pb.Ticket{Extensions: map[string]*any.Any{matchfunction.MatchmakingDeadlineKey: ext}}
+	}
+
+	expired, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: withDeadline(time.Now().Add(50 * time.Millisecond))})
+	require.NoError(t, err)
+	assigned, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: withDeadline(time.Now().Add(50 * time.Millisecond))})
+	require.NoError(t, err)
+	later, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: withDeadline(time.Now().Add(time.Hour))})
+	require.NoError(t, err)
+	undated, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.NoError(t, err)
+
+	_, _, err = store.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{{TicketIds: []string{assigned.GetId()}, Assignment: &pb.Assignment{Connection: "10.0.0.1"}}},
+	})
+	require.NoError(t, err)
+	// Indexed again, the assigned ticket shows it isn't touched.
+	require.NoError(t, store.IndexTicket(ctx, assigned))
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, d.expire(ctx))
+
+	ids, err := store.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, ids, expired.GetId())
+	require.Contains(t, ids, assigned.GetId())
+	require.Contains(t, ids, later.GetId())
+	require.Contains(t, ids, undated.GetId())
+
+	// The expired ticket stays stored, for its client to read.
+	_, err = store.GetTicket(ctx, expired.GetId())
+	require.NoError(t, err)
+
+	counts, err := store.GetTicketCounts(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), counts[0].GetExpired()+counts[1].GetExpired())
+}
@@ -15,12 +15,18 @@
 package frontend
 
 import (
+	"context"
+	"time"
+
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"google.golang.org/grpc"
 	"open-match.dev/open-match/internal/appmain"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/pubsub"
 	"open-match.dev/open-match/internal/statestore"
 	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/internal/worker"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -29,6 +35,8 @@ var (
 	searchFieldsPerTicket   = stats.Int64("open-match.dev/frontend/searchfields_per_ticket", "Searchfields per ticket", stats.UnitDimensionless)
 	totalBytesPerBackfill   = stats.Int64("open-match.dev/frontend/total_bytes_per_backfill", "Total bytes per backfill", stats.UnitBytes)
 	searchFieldsPerBackfill = stats.Int64("open-match.dev/frontend/searchfields_per_backfill", "Searchfields per backfill", stats.UnitDimensionless)
+	ticketsExpired          = stats.Int64("open-match.dev/frontend/tickets_expired", "Tickets deleted for missing heartbeats", stats.UnitDimensionless)
+	ticketIntakeBatchSize   = stats.Int64("open-match.dev/frontend/ticket_intake_batch_size", "Tickets per batch written by the ticket intake", stats.UnitDimensionless)
 
 	totalBytesPerTicketView = &view.View{
 		Measure:     totalBytesPerTicket,
@@ -54,15 +62,60 @@ var (
 		Description: "SearchFields per backfill",
 		Aggregation: telemetry.DefaultCountDistribution,
 	}
+	ticketsExpiredView = &view.View{
+		Measure:     ticketsExpired,
+		Name:        "open-match.dev/frontend/tickets_expired",
+		Description: "Tickets deleted for missing heartbeats",
+		Aggregation: view.Sum(),
+	}
+	ticketIntakeBatchSizeView = &view.View{
+		Measure:     ticketIntakeBatchSize,
+		Name:        "open-match.dev/frontend/ticket_intake_batch_size",
+		Description: "Tickets per batch written by the ticket intake",
+		Aggregation: telemetry.DefaultCountDistribution,
+	}
 )
 
 // BindService creates the frontend service and binds it to the serving harness.
 func BindService(p *appmain.Params, b *appmain.Bindings) error {
+	idGen, err := config.GetIDs(p.Config()).NewGenerator()
+	if err != nil {
+		return err
+	}
+	events, err := pubsub.New(p.Config())
+	if err != nil {
+		return err
+	}
+	if events != nil {
+		b.AddCloserErr(events.Close)
+	}
 	service := &frontendService{
-		cfg:   p.Config(),
-		store: statestore.New(p.Config()),
+		cfg:     p.Config(),
+		store:   pubsub.WithTicketEvents(statestore.New(p.Config()), events),
+		idGen:   idGen,
+		workers: worker.NewPool(p.Config()),
+	}
+	b.AddCloser(service.workers.Close)
+	if config.GetFrontend(p.Config()).TicketHeartbeatTimeout > 0 {
+		service.heartbeats = newTicketHeartbeats(p.Config(), service.store, service.workers)
+		service.heartbeats.start()
+	}
+	service.deadlines = newTicketDeadlines(p.Config(), service.store, service.workers)
+	service.deadlines.start()
+	if config.GetFrontend(p.Config()).TicketIntake {
+		service.intake = newTicketIntake(p.Config(), service.store, service.heartbeats, service.deadlines)
+		b.AddCloser(service.intake.close)
+	}
+	if events != nil {
+		service.watchers = newAssignmentWatchers(p.Config())
+		service.workers.Every("watch_assignments", func() time.Duration {
+			return config.GetBackoff(p.Config()).InitialInterval
+		}, func(ctx context.Context) error {
+			return pubsub.SubscribeTicketEvents(ctx, events, service.watchers.notify)
+		})
 	}
 
+	b.AddDependency("redis", service.store.HealthCheck)
 	b.AddHealthCheckFunc(service.store.HealthCheck)
 	b.AddHandleFunc(func(s *grpc.Server) {
 		pb.RegisterFrontendServiceServer(s, service)
@@ -72,6 +125,8 @@ func BindService(p *appmain.Params, b *appmain.Bindings) error {
 		searchFieldsPerTicketView,
 		totalBytesPerBackfillView,
 		searchFieldsPerBackfillView,
+		ticketIntakeBatchSizeView,
+		ticketsExpiredView,
 	)
 	return nil
 }
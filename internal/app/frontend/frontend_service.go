@@ -16,11 +16,12 @@ package frontend
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
-	"github.com/rs/xid"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/sirupsen/logrus"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/trace"
@@ -28,14 +29,28 @@ import (
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/idgen"
+	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
 
 // frontendService implements the Frontend service that is used to create
 // Tickets and add, remove them from the pool for matchmaking.
 type frontendService struct {
-	cfg   config.View
-	store statestore.Service
+	cfg     config.View
+	store   statestore.Service
+	idGen   idgen.Generator
+	workers *worker.Pool
+	// watchers is nil unless ticket events are published.
+	watchers *assignmentWatchers
+	// intake is nil unless ticket writes are batched.
+	intake *ticketIntake
+	// heartbeats is nil unless tickets without heartbeats are deleted.
+	heartbeats *ticketHeartbeats
+	// deadlines is nil in tests which don't expire tickets past their
+	// matchmaking deadline.
+	deadlines *ticketDeadlines
 }
 
 var (
@@ -60,27 +75,47 @@ func (s *frontendService) CreateTicket(ctx context.Context, req *pb.CreateTicket
 	if req.Ticket.CreateTime != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "tickets cannot be created with create time set")
 	}
+	if req.Ticket.UpdateTime != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "tickets cannot be created with update time set")
+	}
+	if _, _, err := matchfunction.GetMatchmakingDeadline(req.Ticket); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if _, err := matchfunction.GetBoost(req.Ticket); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
 
-	return doCreateTicket(ctx, req, s.store)
+	return doCreateTicket(ctx, req, s.store, s.idGen, s.intake, s.heartbeats, s.deadlines)
 }
 
-func doCreateTicket(ctx context.Context, req *pb.CreateTicketRequest, store statestore.Service) (*pb.Ticket, error) {
+func doCreateTicket(ctx context.Context, req *pb.CreateTicketRequest, store statestore.Service, idGen idgen.Generator, intake *ticketIntake, heartbeats *ticketHeartbeats, deadlines *ticketDeadlines) (*pb.Ticket, error) {
 	// Generate a ticket id and create a Ticket in state storage
 	ticket, ok := proto.Clone(req.Ticket).(*pb.Ticket)
 	if !ok {
 		return nil, status.Error(codes.Internal, "failed to clone input ticket proto")
 	}
 
-	ticket.Id = xid.New().String()
+	ticket.Id = idGen.NewID()
 	ticket.CreateTime = ptypes.TimestampNow()
+	ticket.UpdateTime = proto.Clone(ticket.CreateTime).(*timestamp.Timestamp)
 
 	sfCount := 0
 	sfCount += len(ticket.GetSearchFields().GetDoubleArgs())
 	sfCount += len(ticket.GetSearchFields().GetStringArgs())
 	sfCount += len(ticket.GetSearchFields().GetTags())
+	sfCount += len(ticket.GetSearchFields().GetStringListArgs())
 	stats.Record(ctx, searchFieldsPerTicket.M(int64(sfCount)))
 	stats.Record(ctx, totalBytesPerTicket.M(int64(proto.Size(ticket))))
 
+	if intake != nil {
+		// The intake records the creation, first heartbeats and deadlines
+		// of its batches.
+		if err := intake.create(ctx, ticket); err != nil {
+			return nil, err
+		}
+		return ticket, nil
+	}
+
 	err := store.CreateTicket(ctx, ticket)
 	if err != nil {
 		return nil, err
@@ -91,6 +126,13 @@ func doCreateTicket(ctx context.Context, req *pb.CreateTicketRequest, store stat
 		return nil, err
 	}
 
+	if heartbeats != nil {
+		heartbeats.beat(ctx, ticket.Id)
+	}
+	if deadlines != nil {
+		deadlines.track(ctx, ticket)
+	}
+	statestore.LogTicketEvent(ctx, store, []string{ticket.Id}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED})
 	return ticket, nil
 }
 
@@ -111,17 +153,17 @@ func (s *frontendService) CreateBackfill(ctx context.Context, req *pb.CreateBack
 		return nil, status.Errorf(codes.InvalidArgument, "backfills cannot be created with create time set")
 	}
 
-	return doCreateBackfill(ctx, req, s.store)
+	return doCreateBackfill(ctx, req, s.store, s.idGen)
 }
 
-func doCreateBackfill(ctx context.Context, req *pb.CreateBackfillRequest, store statestore.Service) (*pb.Backfill, error) {
+func doCreateBackfill(ctx context.Context, req *pb.CreateBackfillRequest, store statestore.Service, idGen idgen.Generator) (*pb.Backfill, error) {
 	// Generate an id and create a Backfill in state storage
 	backfill, ok := proto.Clone(req.Backfill).(*pb.Backfill)
 	if !ok {
 		return nil, status.Error(codes.Internal, "failed to clone input ticket proto")
 	}
 
-	backfill.Id = xid.New().String()
+	backfill.Id = idGen.NewID()
 	backfill.CreateTime = ptypes.TimestampNow()
 	backfill.Generation = 1
 
@@ -129,6 +171,7 @@ func doCreateBackfill(ctx context.Context, req *pb.CreateBackfillRequest, store
 	sfCount += len(backfill.GetSearchFields().GetDoubleArgs())
 	sfCount += len(backfill.GetSearchFields().GetStringArgs())
 	sfCount += len(backfill.GetSearchFields().GetTags())
+	sfCount += len(backfill.GetSearchFields().GetStringListArgs())
 	stats.Record(ctx, searchFieldsPerBackfill.M(int64(sfCount)))
 	stats.Record(ctx, totalBytesPerBackfill.M(int64(proto.Size(backfill))))
 
@@ -228,45 +271,56 @@ func (s *frontendService) DeleteBackfill(ctx context.Context, req *pb.DeleteBack
 // DeleteTicket immediately stops Open Match from using the Ticket for matchmaking and removes the Ticket from state storage.
 // The client must delete the Ticket when finished matchmaking with it.
 //   - If SearchFields exist in a Ticket, DeleteTicket will deindex the fields lazily.
+//   - The Ticket's DELETED event is recorded once it is removed from state storage,
+//     and not for Tickets which no longer exist.
 //
 // Users may still be able to assign/get a ticket after calling DeleteTicket on it.
 func (s *frontendService) DeleteTicket(ctx context.Context, req *pb.DeleteTicketRequest) (*empty.Empty, error) {
-	err := doDeleteTicket(ctx, req.GetTicketId(), s.store)
+	err := doDeleteTicket(ctx, req.GetTicketId(), s.store, s.workers)
 	if err != nil {
 		return nil, err
 	}
 	return &empty.Empty{}, nil
 }
 
-func doDeleteTicket(ctx context.Context, id string, store statestore.Service) error {
+func doDeleteTicket(ctx context.Context, id string, store statestore.Service, workers *worker.Pool) error {
 	// Deindex this Ticket to remove it from matchmaking pool.
 	err := store.DeindexTicket(ctx, id)
 	if err != nil {
 		return err
 	}
-
 	//'lazy' ticket delete that should be called after a ticket
 	// has been deindexed.
-	go func() {
-		ctx, span := trace.StartSpan(context.Background(), "open-match/frontend.DeleteTicketLazy")
+	workers.Enqueue("delete_ticket", func(ctx context.Context) error {
+		ctx, span := trace.StartSpan(ctx, "open-match/frontend.DeleteTicketLazy")
 		defer span.End()
+		// A retried job finds the ticket already deleted, and must still
+		// remove it from pending release.
 		err := store.DeleteTicket(ctx, id)
-		if err != nil {
+		switch {
+		case err == nil:
+			// Only the call which removed the ticket records its deletion, so
+			// repeated deletes of a ticket count once.
+			statestore.LogTicketEvent(ctx, store, []string{id}, &pb.TicketEvent{Type: pb.TicketEvent_DELETED})
+		case status.Code(err) != codes.NotFound:
 			logger.WithFields(logrus.Fields{
 				"error": err.Error(),
 				"id":    id,
-			}).Error("failed to delete the ticket")
+			}).Warning("failed to delete the ticket, retrying")
+			return err
 		}
 		err = store.DeleteTicketsFromPendingRelease(ctx, []string{id})
 		if err != nil {
 			logger.WithFields(logrus.Fields{
 				"error": err.Error(),
 				"id":    id,
-			}).Error("failed to delete the ticket from pendingRelease")
+			}).Warning("failed to delete the ticket from pendingRelease, retrying")
+			return err
 		}
 		// TODO: If other redis queues are implemented or we have custom index fields
 		// created by Open Match, those need to be cleaned up here.
-	}()
+		return nil
+	})
 	return nil
 }
 
@@ -276,16 +330,57 @@ func (s *frontendService) GetTicket(ctx context.Context, req *pb.GetTicketReques
 }
 
 // WatchAssignments stream back Assignment of the specified TicketId if it is updated.
-//   - If the Assignment is not updated, GetAssignment will retry using the configured backoff strategy.
+//   - If the Assignment is not updated, GetAssignment will retry using the configured backoff strategy,
+//     or when ticket events are published, wait for the ticket to be assigned.
+//   - If the Ticket has a matchmaking deadline which passes before it is assigned, the Ticket is
+//     removed from matchmaking and the stream ends with DEADLINE_EXCEEDED.
 func (s *frontendService) WatchAssignments(req *pb.WatchAssignmentsRequest, stream pb.FrontendService_WatchAssignmentsServer) error {
 	ctx := stream.Context()
 	sender := func(assignment *pb.Assignment) error {
 		return stream.Send(&pb.WatchAssignmentsResponse{Assignment: assignment})
 	}
-	return doWatchAssignments(ctx, req.GetTicketId(), sender, s.store)
+	return doWatchAssignments(ctx, req.GetTicketId(), sender, s.store, s.watchers, s.heartbeats)
+}
+
+// HeartbeatTicket records that the client of the ticket is still present.
+func (s *frontendService) HeartbeatTicket(ctx context.Context, req *pb.HeartbeatTicketRequest) (*empty.Empty, error) {
+	id := req.GetTicketId()
+	if id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, ".ticket_id is required")
+	}
+
+	_, err := s.store.GetTicket(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.heartbeats != nil {
+		err = s.store.TouchTickets(ctx, []string{id})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &empty.Empty{}, nil
 }
 
-func doWatchAssignments(ctx context.Context, id string, sender func(*pb.Assignment) error, store statestore.Service) error {
+// doWatchAssignments polls the ticket's assignment from the store, or with
+// watchers set, waits for them to signal a change to the ticket in between
+// slower polls.  With heartbeats set, the open stream keeps the ticket alive.
+func doWatchAssignments(ctx context.Context, id string, sender func(*pb.Assignment) error, store statestore.Service, watchers *assignmentWatchers, heartbeats *ticketHeartbeats) error {
+	ticket, err := store.GetTicket(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if heartbeats != nil {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go heartbeats.keepAlive(ctx, id)
+	}
+
+	// Ignore malformed deadlines on tickets created before they were validated.
+	deadline, hasDeadline, _ := matchfunction.GetMatchmakingDeadline(ticket)
+	errDeadlineExceeded := status.Errorf(codes.DeadlineExceeded, "ticket %s was not matched before its matchmaking deadline", id)
+
 	var currAssignment *pb.Assignment
 	var ok bool
 	callback := func(assignment *pb.Assignment) error {
@@ -297,6 +392,10 @@ func doWatchAssignments(ctx context.Context, id string, sender func(*pb.Assignme
 				return status.Errorf(codes.Aborted, ctx.Err().Error())
 			}
 
+			if assignment == nil && hasDeadline && !time.Now().Before(deadline) {
+				return errDeadlineExceeded
+			}
+
 			if (currAssignment == nil && assignment != nil) || !proto.Equal(currAssignment, assignment) {
 				currAssignment, ok = proto.Clone(assignment).(*pb.Assignment)
 				if !ok {
@@ -312,7 +411,18 @@ func doWatchAssignments(ctx context.Context, id string, sender func(*pb.Assignme
 		}
 	}
 
-	return store.GetAssignments(ctx, id, callback)
+	if watchers == nil {
+		err = store.GetAssignments(ctx, id, callback)
+	} else {
+		err = watchers.getAssignments(ctx, id, store, callback)
+	}
+	if err == errDeadlineExceeded {
+		// Stop offering the expired ticket to match functions.
+		if err := store.DeindexTicket(ctx, id); err != nil {
+			return err
+		}
+	}
+	return err
 }
 
 // AcknowledgeBackfill is used to notify OpenMatch about GameServer connection info.
@@ -361,6 +471,11 @@ func (s *frontendService) AcknowledgeBackfill(ctx context.Context, req *pb.Ackno
 		}
 
 		resp.Tickets = tickets
+		assigned := make([]string, 0, len(tickets))
+		for _, t := range tickets {
+			assigned = append(assigned, t.Id)
+		}
+		statestore.LogTicketEvent(ctx, s.store, assigned, &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED})
 
 		// log errors returned from UpdateAssignments to track tickets with NotFound errors
 		for _, f := range setResp.Failures {
@@ -22,17 +22,30 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/ipb"
 	"open-match.dev/open-match/internal/statestore"
 	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
 	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/idgen"
+	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
 
+func newXIDGenerator(t *testing.T) idgen.Generator {
+	g, err := idgen.New(idgen.XID, idgen.Options{})
+	require.NoError(t, err)
+	return g
+}
+
 func TestDoCreateTickets(t *testing.T) {
 	cfg := viper.New()
 
@@ -77,13 +90,15 @@ func TestDoCreateTickets(t *testing.T) {
 			ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
 			test.preAction(cancel)
 
-			res, err := doCreateTicket(ctx, &pb.CreateTicketRequest{Ticket: test.ticket}, store)
+			res, err := doCreateTicket(ctx, &pb.CreateTicketRequest{Ticket: test.ticket}, store, newXIDGenerator(t), nil, nil, nil)
 			require.Equal(t, test.wantCode.String(), status.Convert(err).Code().String())
 			if err == nil {
 				matched, err := regexp.MatchString(`[0-9a-v]{20}`, res.GetId())
 				require.True(t, matched)
 				require.NoError(t, err)
 				require.Equal(t, test.ticket.SearchFields.DoubleArgs["test-arg"], res.SearchFields.DoubleArgs["test-arg"])
+				require.NotNil(t, res.CreateTime)
+				require.True(t, proto.Equal(res.CreateTime, res.UpdateTime))
 			}
 		})
 	}
@@ -94,7 +109,7 @@ func TestCreateBackfill(t *testing.T) {
 	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
 	defer closer()
 	ctx := utilTesting.NewContext(t)
-	fs := frontendService{cfg, store}
+	fs := frontendService{cfg: cfg, store: store, idGen: newXIDGenerator(t)}
 	var testCases = []struct {
 		description     string
 		request         *pb.CreateBackfillRequest
@@ -157,7 +172,7 @@ func TestCreateBackfill(t *testing.T) {
 	// expect error with canceled context
 	store, closer = statestoreTesting.NewStoreServiceForTesting(t, cfg)
 	defer closer()
-	fs = frontendService{cfg, store}
+	fs = frontendService{cfg: cfg, store: store, idGen: newXIDGenerator(t)}
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
@@ -178,7 +193,7 @@ func TestUpdateBackfill(t *testing.T) {
 	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
 	defer closer()
 	ctx := utilTesting.NewContext(t)
-	fs := frontendService{cfg, store}
+	fs := frontendService{cfg: cfg, store: store, idGen: newXIDGenerator(t)}
 	res, err := fs.CreateBackfill(ctx, &pb.CreateBackfillRequest{
 		Backfill: &pb.Backfill{
 			SearchFields: &pb.SearchFields{
@@ -248,7 +263,7 @@ func TestUpdateBackfill(t *testing.T) {
 
 	// expect error with canceled context
 	store, closer = statestoreTesting.NewStoreServiceForTesting(t, cfg)
-	fs = frontendService{cfg, store}
+	fs = frontendService{cfg: cfg, store: store}
 	defer closer()
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -332,7 +347,7 @@ func TestDoWatchAssignments(t *testing.T) {
 			gotAssignments := []*pb.Assignment{}
 
 			test.preAction(ctx, t, store, test.wantAssignments, &wg)
-			err := doWatchAssignments(ctx, testTicket.GetId(), senderGenerator(gotAssignments, len(test.wantAssignments)), store)
+			err := doWatchAssignments(ctx, testTicket.GetId(), senderGenerator(gotAssignments, len(test.wantAssignments)), store, nil, nil)
 			require.Equal(t, test.wantCode.String(), status.Convert(err).Code().String())
 
 			wg.Wait()
@@ -343,6 +358,71 @@ func TestDoWatchAssignments(t *testing.T) {
 	}
 }
 
+func TestDoWatchAssignmentsNotified(t *testing.T) {
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	ctx := utilTesting.NewContext(t)
+
+	// Polls never come within the test, so only the notification can end it.
+	cfg.Set(config.KeyBackoffMaxInterval, "1h")
+	watchers := newAssignmentWatchers(cfg)
+
+	ticket := &pb.Ticket{Id: "test-id"}
+	require.NoError(t, store.CreateTicket(ctx, ticket))
+
+	errAssigned := errors.New("assigned")
+	done := make(chan error, 1)
+	go func() {
+		done <- doWatchAssignments(ctx, ticket.GetId(), func(a *pb.Assignment) error {
+			if a.GetConnection() != "" {
+				return errAssigned
+			}
+			return nil
+		}, store, watchers, nil)
+	}()
+
+	// Notify until the watch is registered and reads the assignment.
+	_, _, err := store.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{{TicketIds: []string{ticket.GetId()}, Assignment: &pb.Assignment{Connection: "1"}}},
+	})
+	require.NoError(t, err)
+	for {
+		watchers.notify(&ipb.TicketEvents{TicketIds: []string{ticket.GetId()}, Event: &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED}})
+		select {
+		case err = <-done:
+			require.Equal(t, codes.Aborted, status.Code(err))
+			require.Empty(t, watchers.watchers)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestDoWatchAssignmentsMatchmakingDeadline(t *testing.T) {
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, viper.New())
+	defer closer()
+	ctx := utilTesting.NewContext(t)
+
+	deadline, err := ptypes.TimestampProto(time.Now().Add(100 * time.Millisecond))
+	require.NoError(t, err)
+	ext, err := ptypes.MarshalAny(&pb.MatchmakingDeadline{Deadline: deadline})
+	require.NoError(t, err)
+	ticket := &pb.Ticket{
+		Id:         "test-id",
+		Extensions: map[string]*any.Any{matchfunction.MatchmakingDeadlineKey: ext},
+	}
+	require.NoError(t, store.CreateTicket(ctx, ticket))
+	require.NoError(t, store.IndexTicket(ctx, ticket))
+
+	err = doWatchAssignments(ctx, ticket.GetId(), func(*pb.Assignment) error { return nil }, store, nil, nil)
+	require.Equal(t, codes.DeadlineExceeded.String(), status.Convert(err).Code().String())
+
+	ids, err := store.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, ids, ticket.GetId())
+}
+
 // TestAcknowledgeBackfillValidation - test input validation only
 func TestAcknowledgeBackfillValidation(t *testing.T) {
 	cfg := viper.New()
@@ -370,7 +450,7 @@ func TestAcknowledgeBackfillValidation(t *testing.T) {
 
 			store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
 			defer closer()
-			fs := frontendService{cfg, store}
+			fs := frontendService{cfg: cfg, store: store}
 			bf, err := fs.AcknowledgeBackfill(ctx, test.request)
 			require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
 			require.Equal(t, test.expectedMessage, status.Convert(err).Message())
@@ -399,7 +479,7 @@ func TestAcknowledgeBackfill(t *testing.T) {
 	}
 	err := store.CreateBackfill(ctx, fakeBackfill, []string{})
 	require.NoError(t, err)
-	fs := frontendService{cfg, store}
+	fs := frontendService{cfg: cfg, store: store}
 
 	resp, err := fs.AcknowledgeBackfill(ctx, &pb.AcknowledgeBackfillRequest{BackfillId: fakeBackfill.Id, Assignment: &pb.Assignment{Connection: "10.0.0.1"}})
 	require.NoError(t, err)
@@ -466,17 +546,48 @@ func TestDoDeleteTicket(t *testing.T) {
 		test := test
 		t.Run(test.description, func(t *testing.T) {
 			ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
-			store, closer := statestoreTesting.NewStoreServiceForTesting(t, viper.New())
+			cfg := viper.New()
+			store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
 			defer closer()
+			workers := worker.NewPool(cfg)
+			defer workers.Close()
 
 			test.preAction(ctx, cancel, store)
 
-			err := doDeleteTicket(ctx, fakeTicket.GetId(), store)
+			err := doDeleteTicket(ctx, fakeTicket.GetId(), store, workers)
 			require.Equal(t, test.wantCode.String(), status.Convert(err).Code().String())
 		})
 	}
 }
 
+func TestDoDeleteTicketReleasesMissingTicket(t *testing.T) {
+	ctx := utilTesting.NewContext(t)
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	// Keep the ticket pending for longer than the delete job's retries.
+	cfg.Set(config.KeyPendingReleaseTimeout, "1m")
+	workers := worker.NewPool(cfg)
+	defer workers.Close()
+
+	// The ticket is pending release, but its body is already gone, as when
+	// a retried delete job runs after DeleteTicket succeeded.
+	ticket := &pb.Ticket{Id: "1"}
+	require.NoError(t, store.IndexTicket(ctx, ticket))
+	require.NoError(t, store.AddTicketsToPendingRelease(ctx, []*pb.Match{{Tickets: []*pb.Ticket{ticket}}}))
+
+	pending, err := store.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Contains(t, pending, ticket.GetId())
+
+	require.NoError(t, doDeleteTicket(ctx, ticket.GetId(), store, workers))
+	require.Eventually(t, func() bool {
+		pending, err := store.GetPendingIDSet(ctx)
+		require.NoError(t, err)
+		return len(pending) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func TestDoGetTicket(t *testing.T) {
 	fakeTicket := &pb.Ticket{
 		Id: "1",
@@ -580,7 +691,7 @@ func TestGetBackfill(t *testing.T) {
 			ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
 			store, closer := statestoreTesting.NewStoreServiceForTesting(t, viper.New())
 			defer closer()
-			fs := frontendService{cfg, store}
+			fs := frontendService{cfg: cfg, store: store}
 
 			test.preAction(ctx, cancel, store)
 
@@ -613,7 +724,7 @@ func TestDoDeleteBackfill(t *testing.T) {
 	require.NoError(t, err)
 
 	cfg := viper.New()
-	fs := frontendService{cfg, store}
+	fs := frontendService{cfg: cfg, store: store}
 
 	tests := []struct {
 		description string
@@ -641,3 +752,35 @@ func TestDoDeleteBackfill(t *testing.T) {
 		})
 	}
 }
+
+func TestDoDeleteTicketCountsOnce(t *testing.T) {
+	ctx := utilTesting.NewContext(t)
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	cfg.Set(config.KeyTicketCountsRetention, "10m")
+	workers := worker.NewPool(cfg)
+	defer workers.Close()
+
+	ticket := &pb.Ticket{Id: "1"}
+	require.NoError(t, store.CreateTicket(ctx, ticket))
+	require.NoError(t, store.IndexTicket(ctx, ticket))
+
+	deleted := func() int64 {
+		// The current minute and the last, in case the test spans both.
+		counts, err := store.GetTicketCounts(ctx, 2)
+		require.NoError(t, err)
+		return counts[0].GetDeleted() + counts[1].GetDeleted()
+	}
+
+	require.NoError(t, doDeleteTicket(ctx, ticket.GetId(), store, workers))
+	require.Eventually(t, func() bool {
+		return deleted() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Deleting the missing ticket again isn't counted.
+	require.NoError(t, doDeleteTicket(ctx, ticket.GetId(), store, workers))
+	require.NoError(t, doDeleteTicket(ctx, "missing", store, workers))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int64(1), deleted())
+}
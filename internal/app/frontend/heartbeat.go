@@ -0,0 +1,143 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/worker"
+)
+
+// Most stale tickets claimed from the state store at once.
+const staleTicketsBatch = 1000
+
+// ticketHeartbeats deletes the tickets whose clients stopped sending
+// heartbeats, so that matches aren't built around players who already left.
+// Tickets are tracked from their creation, those created before heartbeats
+// were turned on never expire.
+type ticketHeartbeats struct {
+	store   statestore.Service
+	workers *worker.Pool
+	timeout time.Duration
+}
+
+func newTicketHeartbeats(cfg config.View, store statestore.Service, workers *worker.Pool) *ticketHeartbeats {
+	return &ticketHeartbeats{
+		store:   store,
+		workers: workers,
+		timeout: config.GetFrontend(cfg).TicketHeartbeatTimeout,
+	}
+}
+
+// start deletes stale tickets every half of the timeout, so they live at most
+// one and a half timeouts without a heartbeat.
+func (h *ticketHeartbeats) start() {
+	h.workers.Every("expire_stale_tickets", func() time.Duration {
+		return h.timeout / 2
+	}, h.expire)
+}
+
+// beat records a heartbeat for the tickets.  Failing to record the first
+// heartbeat of a ticket only means it never expires, so callers log errors
+// rather than fail.
+func (h *ticketHeartbeats) beat(ctx context.Context, ids ...string) {
+	if err := h.store.TouchTickets(ctx, ids); err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"ticket_ids": ids,
+		}).Warning("failed to record ticket heartbeats")
+	}
+}
+
+// keepAlive records heartbeats for the ticket until ctx is done, for the
+// duration of a WatchAssignments stream.
+func (h *ticketHeartbeats) keepAlive(ctx context.Context, id string) {
+	ticker := time.NewTicker(h.timeout / 3)
+	defer ticker.Stop()
+	for {
+		h.beat(ctx, id)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expire deletes the unassigned tickets which had no heartbeat within the
+// timeout.  Tickets which failed to be deleted are tracked again, so that a
+// later run retries them once they go stale again.
+func (h *ticketHeartbeats) expire(ctx context.Context) error {
+	for {
+		ids, err := h.store.ClaimStaleTickets(ctx, time.Now().Add(-h.timeout), staleTicketsBatch)
+		if err != nil {
+			return err
+		}
+
+		retry := []string{}
+		deleted := 0
+		// Tickets deleted by their clients, or after their assignment, are
+		// still tracked until they go stale.
+		tickets, err := h.store.GetTickets(ctx, ids)
+		if err != nil {
+			retry = ids
+		}
+		for _, ticket := range tickets {
+			// Assigned tickets are deleted by their clients, or after
+			// assignedDeleteTimeout.
+			if ticket.GetAssignment() != nil {
+				continue
+			}
+			if deleteErr := doDeleteTicket(ctx, ticket.GetId(), h.store, h.workers); deleteErr != nil {
+				retry = append(retry, ticket.GetId())
+				if err == nil {
+					err = deleteErr
+				}
+				continue
+			}
+			deleted++
+		}
+
+		if deleted > 0 {
+			stats.Record(ctx, ticketsExpired.M(int64(deleted)))
+			if countErr := h.store.CountExpiredTickets(ctx, deleted); countErr != nil {
+				logger.WithFields(logrus.Fields{
+					"error": countErr.Error(),
+				}).Warning("failed to count expired tickets")
+			}
+			logger.WithFields(logrus.Fields{
+				"count": deleted,
+			}).Info("Deleted tickets without heartbeats.")
+		}
+		if err != nil {
+			if retryErr := h.store.TouchTickets(ctx, retry); retryErr != nil {
+				logger.WithFields(logrus.Fields{
+					"error":   retryErr.Error(),
+					"tickets": len(retry),
+				}).Error("failed to track stale tickets again, they never expire")
+			}
+			return err
+		}
+
+		if len(ids) < staleTicketsBatch {
+			return nil
+		}
+	}
+}
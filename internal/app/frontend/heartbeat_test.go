@@ -0,0 +1,109 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketHeartbeatsExpire(t *testing.T) {
+	ctx := utilTesting.NewContext(t)
+	cfg := viper.New()
+	cfg.Set(config.KeyTicketHeartbeatTimeout, "50ms")
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	workers := worker.NewPool(cfg)
+	defer workers.Close()
+	h := newTicketHeartbeats(cfg, store, workers)
+	fs := frontendService{cfg: cfg, store: store, idGen: newXIDGenerator(t), heartbeats: h}
+
+	stale, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.NoError(t, err)
+	alive, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.NoError(t, err)
+	assigned, err := fs.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.NoError(t, err)
+	_, _, err = store.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{{TicketIds: []string{assigned.GetId()}, Assignment: &pb.Assignment{Connection: "10.0.0.1"}}},
+	})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = fs.HeartbeatTicket(ctx, &pb.HeartbeatTicketRequest{TicketId: alive.GetId()})
+	require.NoError(t, err)
+
+	require.NoError(t, h.expire(ctx))
+
+	ids, err := store.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Contains(t, ids, alive.GetId())
+	require.NotContains(t, ids, stale.GetId())
+
+	_, err = store.GetTicket(ctx, stale.GetId())
+	require.Equal(t, codes.NotFound.String(), status.Convert(err).Code().String())
+
+	// The assigned ticket is left for its client to read.
+	_, err = store.GetTicket(ctx, assigned.GetId())
+	require.NoError(t, err)
+}
+
+func TestTicketHeartbeatsWatchAssignments(t *testing.T) {
+	ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
+	defer cancel()
+	cfg := viper.New()
+	cfg.Set(config.KeyTicketHeartbeatTimeout, "50ms")
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	workers := worker.NewPool(cfg)
+	defer workers.Close()
+	h := newTicketHeartbeats(cfg, store, workers)
+
+	ticket, err := doCreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}}, store, newXIDGenerator(t), nil, h, nil)
+	require.NoError(t, err)
+
+	go doWatchAssignments(ctx, ticket.GetId(), func(*pb.Assignment) error { return nil }, store, nil, h)
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, h.expire(ctx))
+
+	_, err = store.GetTicket(ctx, ticket.GetId())
+	require.NoError(t, err)
+}
+
+func TestHeartbeatTicketErrors(t *testing.T) {
+	ctx := utilTesting.NewContext(t)
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	fs := frontendService{cfg: cfg, store: store}
+
+	_, err := fs.HeartbeatTicket(ctx, &pb.HeartbeatTicketRequest{})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+
+	_, err = fs.HeartbeatTicket(ctx, &pb.HeartbeatTicketRequest{TicketId: "missing"})
+	require.Equal(t, codes.NotFound.String(), status.Convert(err).Code().String())
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// ticketIntake queues the tickets of CreateTicket calls, and writes them to
+// the state store in batches, so that a burst of calls costs redis a few
+// transactions rather than one per ticket.  A batch is written once it is
+// full, or maxDelay after its first ticket was queued.  Callers wait for
+// their batch to be written, so a ticket is never returned before it is
+// stored, and close writes the tickets still queued.
+type ticketIntake struct {
+	store statestore.Service
+	// heartbeats is nil unless tickets without heartbeats are deleted.
+	heartbeats *ticketHeartbeats
+	deadlines  *ticketDeadlines
+	maxBatch   int
+	maxDelay   time.Duration
+	queue      chan *intakeRequest
+	done       chan struct{}
+
+	// mu guards closing queue, against create sending on it.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type intakeRequest struct {
+	ctx    context.Context
+	ticket *pb.Ticket
+	result chan error
+}
+
+func newTicketIntake(cfg config.View, store statestore.Service, heartbeats *ticketHeartbeats, deadlines *ticketDeadlines) *ticketIntake {
+	settings := config.GetFrontend(cfg)
+	ti := &ticketIntake{
+		store:      store,
+		heartbeats: heartbeats,
+		deadlines:  deadlines,
+		maxBatch:   settings.TicketIntakeBatchSize,
+		maxDelay:   settings.TicketIntakeMaxDelay,
+		queue:      make(chan *intakeRequest, settings.TicketIntakeBatchSize),
+		done:       make(chan struct{}),
+	}
+	go ti.run()
+	return ti
+}
+
+// create queues the ticket, and returns once its batch was written.  Waiting
+// doesn't stop at ctx cancelation, as the ticket may be in a batch being
+// written, but tickets of canceled calls are dropped from batches not yet
+// written.
+func (ti *ticketIntake) create(ctx context.Context, ticket *pb.Ticket) error {
+	req := &intakeRequest{ctx: ctx, ticket: ticket, result: make(chan error, 1)}
+
+	ti.mu.RLock()
+	if ti.closed {
+		ti.mu.RUnlock()
+		return status.Error(codes.Unavailable, "frontend is shutting down")
+	}
+	select {
+	case ti.queue <- req:
+	case <-ctx.Done():
+		ti.mu.RUnlock()
+		return status.Error(codes.Aborted, ctx.Err().Error())
+	}
+	ti.mu.RUnlock()
+
+	return <-req.result
+}
+
+// close stops accepting tickets, and returns once the queued tickets were
+// written.
+func (ti *ticketIntake) close() {
+	ti.mu.Lock()
+	if !ti.closed {
+		ti.closed = true
+		close(ti.queue)
+	}
+	ti.mu.Unlock()
+	<-ti.done
+}
+
+func (ti *ticketIntake) run() {
+	defer close(ti.done)
+	for req := range ti.queue {
+		batch := []*intakeRequest{req}
+		timer := time.NewTimer(ti.maxDelay)
+	fill:
+		for len(batch) < ti.maxBatch {
+			select {
+			case req, ok := <-ti.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, req)
+			case <-timer.C:
+				break fill
+			}
+		}
+		timer.Stop()
+		ti.write(batch)
+	}
+}
+
+// write creates the tickets of the batch in one transaction, and records
+// their creation with one event, and their first heartbeats and their
+// deadlines with one write each.
+func (ti *ticketIntake) write(batch []*intakeRequest) {
+	ctx, span := trace.StartSpan(context.Background(), "frontend/ticketIntake.write")
+	defer span.End()
+
+	tickets := make([]*pb.Ticket, 0, len(batch))
+	ids := make([]string, 0, len(batch))
+	waiting := batch[:0]
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			req.result <- status.Error(codes.Aborted, err.Error())
+			continue
+		}
+		tickets = append(tickets, req.ticket)
+		ids = append(ids, req.ticket.GetId())
+		waiting = append(waiting, req)
+	}
+	if len(tickets) == 0 {
+		return
+	}
+	span.AddAttributes(trace.Int64Attribute("tickets", int64(len(tickets))))
+	stats.Record(ctx, ticketIntakeBatchSize.M(int64(len(tickets))))
+
+	err := ti.store.CreateTickets(ctx, tickets)
+	if err == nil {
+		if ti.heartbeats != nil {
+			ti.heartbeats.beat(ctx, ids...)
+		}
+		if ti.deadlines != nil {
+			ti.deadlines.track(ctx, tickets...)
+		}
+		statestore.LogTicketEvent(ctx, ti.store, ids, &pb.TicketEvent{Type: pb.TicketEvent_CREATED})
+	}
+	for _, req := range waiting {
+		req.result <- err
+	}
+}
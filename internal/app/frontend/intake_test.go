@@ -0,0 +1,162 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// batchCountingStore counts the batches written with CreateTickets.
+type batchCountingStore struct {
+	statestore.Service
+	batches int32
+}
+
+func (s *batchCountingStore) CreateTickets(ctx context.Context, tickets []*pb.Ticket) error {
+	atomic.AddInt32(&s.batches, 1)
+	return s.Service.CreateTickets(ctx, tickets)
+}
+
+func newTestIntake(t *testing.T, batchSize int, maxDelay time.Duration) (*ticketIntake, *batchCountingStore, func()) {
+	cfg := viper.New()
+	cfg.Set(config.KeyTicketIntakeBatchSize, batchSize)
+	cfg.Set(config.KeyTicketIntakeMaxDelay, maxDelay)
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	counting := &batchCountingStore{Service: store}
+	return newTicketIntake(cfg, counting, nil, nil), counting, closer
+}
+
+func createConcurrently(ctx context.Context, ti *ticketIntake, ids ...string) []error {
+	errs := make([]error, len(ids))
+	wg := sync.WaitGroup{}
+	for i, id := range ids {
+		i, id := i, id
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = ti.create(ctx, &pb.Ticket{Id: id})
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestTicketIntakeBatches(t *testing.T) {
+	ti, store, closer := newTestIntake(t, 3, time.Hour)
+	defer closer()
+	defer ti.close()
+	ctx := utilTesting.NewContext(t)
+
+	// A full batch is written without waiting for maxDelay.
+	for _, err := range createConcurrently(ctx, ti, "a", "b", "c") {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.batches))
+
+	ids, err := store.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := store.GetTicket(ctx, id)
+		require.NoError(t, err)
+	}
+}
+
+func TestTicketIntakeMaxDelay(t *testing.T) {
+	ti, store, closer := newTestIntake(t, 100, 10*time.Millisecond)
+	defer closer()
+	defer ti.close()
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, ti.create(ctx, &pb.Ticket{Id: "a"}))
+	_, err := store.GetTicket(ctx, "a")
+	require.NoError(t, err)
+}
+
+func TestTicketIntakeClose(t *testing.T) {
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	ctx := utilTesting.NewContext(t)
+
+	// Queue the tickets before the intake runs, with a maxDelay the test
+	// would time out waiting for.
+	ti := &ticketIntake{
+		store:    store,
+		maxBatch: 100,
+		maxDelay: time.Hour,
+		queue:    make(chan *intakeRequest, 100),
+		done:     make(chan struct{}),
+	}
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		errs = createConcurrently(ctx, ti, "a", "b")
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return len(ti.queue) == 2
+	}, 5*time.Second, time.Millisecond)
+	go ti.run()
+
+	// Closing writes the queued tickets, rather than waiting for maxDelay.
+	ti.close()
+	<-done
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	ids, err := store.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	err = ti.create(ctx, &pb.Ticket{Id: "c"})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestTicketIntakeCanceled(t *testing.T) {
+	ti, store, closer := newTestIntake(t, 100, 200*time.Millisecond)
+	defer closer()
+	defer ti.close()
+
+	ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
+	result := make(chan error, 1)
+	go func() {
+		result <- ti.create(ctx, &pb.Ticket{Id: "a"})
+	}()
+	require.Eventually(t, func() bool {
+		return len(ti.queue) == 0
+	}, 5*time.Second, time.Millisecond)
+	cancel()
+
+	// The ticket of a canceled call is dropped from its batch.
+	require.Equal(t, codes.Aborted, status.Code(<-result))
+	_, err := store.GetTicket(utilTesting.NewContext(t), "a")
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, int32(0), atomic.LoadInt32(&store.batches))
+}
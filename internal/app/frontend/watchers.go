@@ -0,0 +1,114 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// assignmentWatchers wakes the WatchAssignments calls of a frontend when
+// their tickets are assigned or deleted, so that they read the ticket right
+// away.  Notifications may be lost, so the calls still poll the ticket, but
+// only every poll rather than every backoff initial interval.
+type assignmentWatchers struct {
+	poll     time.Duration
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+}
+
+func newAssignmentWatchers(cfg config.View) *assignmentWatchers {
+	return &assignmentWatchers{
+		poll:     config.GetBackoff(cfg).MaxInterval,
+		watchers: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// watch returns a channel which receives when the ticket changes, and a
+// function to stop watching it.
+func (w *assignmentWatchers) watch(id string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watchers[id] == nil {
+		w.watchers[id] = make(map[chan struct{}]struct{})
+	}
+	w.watchers[id][wake] = struct{}{}
+
+	return wake, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers[id], wake)
+		if len(w.watchers[id]) == 0 {
+			delete(w.watchers, id)
+		}
+	}
+}
+
+// notify wakes the watchers of the tickets of events which may end a watch.
+func (w *assignmentWatchers) notify(events *ipb.TicketEvents) {
+	switch events.GetEvent().GetType() {
+	case pb.TicketEvent_ASSIGNED, pb.TicketEvent_DELETED:
+	default:
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range events.GetTicketIds() {
+		for wake := range w.watchers[id] {
+			// A pending wake up already makes the watcher read the ticket.
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// getAssignments calls back with the ticket's assignment now, and then each
+// time the ticket changes or the poll interval passes, as
+// statestore.Service.GetAssignments does.
+func (w *assignmentWatchers) getAssignments(ctx context.Context, id string, store statestore.Service, callback func(*pb.Assignment) error) error {
+	wake, stop := w.watch(id)
+	defer stop()
+
+	for {
+		ticket, err := store.GetTicket(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = callback(ticket.GetAssignment())
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Aborted, ctx.Err().Error())
+		case <-wake:
+		case <-time.After(w.poll):
+		}
+	}
+}
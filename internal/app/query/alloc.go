@@ -0,0 +1,45 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"sync"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Query results are reused across calls, as every query against a large pool
+// would otherwise grow a fresh slice to the size of its results.  Slices are
+// returned to the pool once the call ends, so they must not be retained (eg,
+// by a cursor snapshot, or by a sent response) after the call.
+var ticketSlicePool = sync.Pool{
+	New: func() interface{} {
+		s := make([]*pb.Ticket, 0)
+		return &s
+	},
+}
+
+func getTicketSlice() *[]*pb.Ticket {
+	return ticketSlicePool.Get().(*[]*pb.Ticket)
+}
+
+func putTicketSlice(s *[]*pb.Ticket) {
+	// Drop the references so the pool doesn't keep deleted tickets alive.
+	for i := range *s {
+		(*s)[i] = nil
+	}
+	*s = (*s)[:0]
+	ticketSlicePool.Put(s)
+}
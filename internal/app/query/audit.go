@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/filter"
+	"open-match.dev/open-match/internal/rpc"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// authorizeAudit returns PermissionDenied unless the calling client is listed
+// in queryAuditClients.  Inactive tickets would otherwise leak to every match
+// function, and reading them bypasses the ticket cache.
+func authorizeAudit(ctx context.Context, cfg config.View) error {
+	client := rpc.ClientIdentity(ctx)
+	for _, allowed := range config.GetQuery(cfg).AuditClients {
+		if allowed == "*" || allowed == client {
+			return nil
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"client": client,
+	}).Warning("Query client is not allowed to query inactive tickets, rejecting call.")
+	return status.Errorf(codes.PermissionDenied, "query client %q is not listed in %s", client, config.KeyQueryAuditClients)
+}
+
+// auditTickets returns the active, pending and assigned tickets which pass the
+// pool filter, along with their states.  It reads from the state store, as the
+// ticket cache only holds active tickets.
+func (s *queryService) auditTickets(ctx context.Context, pf *filter.PoolFilter) ([]*pb.Ticket, []pb.QueryTicketsResponse_State, error) {
+	states, err := s.ticketStates(ctx, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	return s.ticketsWithStates(ctx, pf, ids, states)
+}
+
+// ticketStates returns the states of the active tickets, and of the pending and
+// assigned ones if includeInactive is set, by ticket id.
+func (s *queryService) ticketStates(ctx context.Context, includeInactive bool) (map[string]pb.QueryTicketsResponse_State, error) {
+	active, err := s.store.GetIndexedIDSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]pb.QueryTicketsResponse_State, len(active))
+	for id := range active {
+		states[id] = pb.QueryTicketsResponse_ACTIVE
+	}
+	if !includeInactive {
+		return states, nil
+	}
+
+	pending, err := s.store.GetPendingIDSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := s.store.GetAssignedIDSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for id := range pending {
+		states[id] = pb.QueryTicketsResponse_PENDING
+	}
+	for id := range assigned {
+		states[id] = pb.QueryTicketsResponse_ASSIGNED
+	}
+	return states, nil
+}
+
+// ticketsWithStates reads the tickets with the given ids, and returns those
+// which pass the pool filter along with their states.  Tickets deleted since
+// their state was read are skipped.
+func (s *queryService) ticketsWithStates(ctx context.Context, pf *filter.PoolFilter, ids []string, states map[string]pb.QueryTicketsResponse_State) ([]*pb.Ticket, []pb.QueryTicketsResponse_State, error) {
+	tickets, err := s.store.GetTickets(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []*pb.Ticket
+	var resultStates []pb.QueryTicketsResponse_State
+	for _, ticket := range tickets {
+		if !pf.In(ticket) {
+			continue
+		}
+		state := states[ticket.GetId()]
+		if ticket.GetAssignment() != nil {
+			state = pb.QueryTicketsResponse_ASSIGNED
+		}
+		results = append(results, ticket)
+		resultStates = append(resultStates, state)
+	}
+	return results, resultStates, nil
+}
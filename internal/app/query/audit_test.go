@@ -0,0 +1,50 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+)
+
+func TestAuthorizeAudit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		clients []string
+		code    codes.Code
+	}{
+		{"notSet", nil, codes.PermissionDenied},
+		{"otherClient", []string{"10.0.0.2"}, codes.PermissionDenied},
+		{"listed", []string{"10.0.0.2", "10.0.0.1"}, codes.OK},
+		{"anyClient", []string{"*"}, codes.OK},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := viper.New()
+			if tt.clients != nil {
+				cfg.Set(config.KeyQueryAuditClients, tt.clients)
+			}
+			err := authorizeAudit(peerContext("10.0.0.1:1234"), cfg)
+			require.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/errorinfo"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// checkBlackout returns FailedPrecondition while the pool is closed by a
+// blackout window, so that match functions can tell a scheduled closure from
+// an empty pool or an outage.
+func checkBlackout(cfg config.View, pool *pb.Pool) error {
+	until, ok := config.GetBlackouts(cfg).Active(config.BlackoutPool, pool.GetName(), time.Now())
+	if !ok {
+		return nil
+	}
+
+	end := until.UTC().Format(time.RFC3339)
+	return errorinfo.Errorf(codes.FailedPrecondition, errorinfo.ReasonBlackout, map[string]string{
+		errorinfo.MetadataPoolName:    pool.GetName(),
+		errorinfo.MetadataBlackoutEnd: end,
+	}, "pool %q is closed by a blackout window until %s", pool.GetName(), end)
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/errorinfo"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestCheckBlackout(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	end := now.Add(time.Hour).Format(time.RFC3339)
+	window := now.Add(-time.Hour).Format(time.RFC3339) + "/" + end
+
+	cfg := viper.New()
+	cfg.Set(config.KeyBlackoutWindows, []string{"pool:ranked=" + window, "profile:casual=" + window})
+
+	err := checkBlackout(cfg, &pb.Pool{Name: "ranked"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	info, ok := errorinfo.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, errorinfo.ReasonBlackout, info.Reason)
+	require.Equal(t, map[string]string{
+		errorinfo.MetadataPoolName:    "ranked",
+		errorinfo.MetadataBlackoutEnd: end,
+	}, info.Metadata)
+
+	require.NoError(t, checkBlackout(cfg, &pb.Pool{Name: "arena"}))
+	// Profile windows are enforced by the backend only.
+	require.NoError(t, checkBlackout(cfg, &pb.Pool{Name: "casual"}))
+}
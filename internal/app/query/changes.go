@@ -0,0 +1,209 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/worker"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// ticketChangesWait is the longest a read of the ticket change stream waits
+// for a change.
+const ticketChangesWait = time.Second
+
+// ticketChanges follows the ticket change stream, keeping the indexed and
+// pending tickets in memory.  Used as the ticket cache's update, it serves
+// queries without reading the state store, so query replicas can be added
+// without adding load to it.  The state store is only read for a snapshot to
+// start following the stream from, and again if the follower falls so far
+// behind that the stream is trimmed past it.
+//
+// Queries see changes once they are read from the stream, typically within
+// milliseconds of being made.
+type ticketChanges struct {
+	cfg   config.View
+	store statestore.Service
+
+	mu sync.Mutex
+	// synced is set once the first snapshot is taken.  Later resyncs keep
+	// serving the tickets of the last snapshot until they are done.
+	synced   bool
+	failed   bool
+	position string
+	indexed  map[string]*pb.Ticket
+	pending  map[string]time.Time
+}
+
+func newTicketChanges(cfg config.View, store statestore.Service) *ticketChanges {
+	return &ticketChanges{
+		cfg:     cfg,
+		store:   store,
+		indexed: make(map[string]*pb.Ticket),
+		pending: make(map[string]time.Time),
+	}
+}
+
+// start follows the stream until the pool is closed.
+func (tc *ticketChanges) start(workers *worker.Pool) {
+	workers.Every("follow_ticket_changes", tc.interval, tc.follow)
+}
+
+// interval reads again right away, unless the last read failed.
+func (tc *ticketChanges) interval() time.Duration {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.failed {
+		return config.GetBackoff(tc.cfg).InitialInterval
+	}
+	return 0
+}
+
+// follow applies the next changes on the stream, taking a snapshot first if
+// there is no position to read from.
+func (tc *ticketChanges) follow(ctx context.Context) error {
+	tc.mu.Lock()
+	position := tc.position
+	tc.mu.Unlock()
+
+	err := tc.read(ctx, position)
+	if status.Code(err) == codes.OutOfRange {
+		logger.WithFields(logrus.Fields{
+			"position": position,
+		}).Warning("Fell behind the ticket change stream, taking a new snapshot.")
+		err = tc.read(ctx, "")
+	}
+
+	tc.mu.Lock()
+	tc.failed = err != nil
+	tc.mu.Unlock()
+	return err
+}
+
+func (tc *ticketChanges) read(ctx context.Context, position string) error {
+	if position == "" {
+		snapshot, err := tc.store.SnapshotTickets(ctx)
+		if err != nil {
+			return err
+		}
+		tc.reset(snapshot)
+		return nil
+	}
+
+	changes, err := tc.store.ReadTicketChanges(ctx, position, ticketChangesWait)
+	if err != nil {
+		return err
+	}
+	tc.apply(changes)
+	return nil
+}
+
+func (tc *ticketChanges) reset(snapshot *statestore.TicketSnapshot) {
+	indexed := make(map[string]*pb.Ticket, len(snapshot.Indexed))
+	for _, t := range snapshot.Indexed {
+		indexed[t.GetId()] = t
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.synced = true
+	tc.position = snapshot.Position
+	tc.indexed = indexed
+	tc.pending = snapshot.Pending
+}
+
+func (tc *ticketChanges) apply(changes []*statestore.TicketChange) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	for _, c := range changes {
+		switch c.Type {
+		case statestore.TicketIndexed:
+			if c.Ticket != nil {
+				tc.indexed[c.Ticket.GetId()] = c.Ticket
+			}
+		case statestore.TicketsDeindexed:
+			for _, id := range c.TicketIDs {
+				delete(tc.indexed, id)
+			}
+		case statestore.TicketsPending:
+			for _, id := range c.TicketIDs {
+				tc.pending[id] = c.Time
+			}
+		case statestore.TicketsReleased:
+			for _, id := range c.TicketIDs {
+				delete(tc.pending, id)
+			}
+		case statestore.AllTicketsReleased:
+			tc.pending = make(map[string]time.Time)
+		}
+		tc.position = c.Position
+	}
+}
+
+// update sets value, the ticket cache, to the indexed tickets which are not
+// pending release.
+func (tc *ticketChanges) update(_ statestore.Service, value interface{}) error {
+	tickets, ok := value.(map[string]*pb.Ticket)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "expecting value type map[string]*pb.Ticket, but got: %T", value)
+	}
+
+	t := time.Now()
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !tc.synced {
+		return status.Error(codes.Unavailable, "the ticket change stream is not followed yet")
+	}
+
+	// Pending tickets are released pendingReleaseTimeout after they were
+	// proposed, without a change on the stream.
+	releasedBefore := t.Add(-config.GetStateStore(tc.cfg).PendingReleaseTimeout)
+	for id, proposed := range tc.pending {
+		if !proposed.After(releasedBefore) {
+			delete(tc.pending, id)
+		}
+	}
+
+	previousCount := len(tickets)
+	for id := range tickets {
+		_, indexed := tc.indexed[id]
+		_, pending := tc.pending[id]
+		if !indexed || pending {
+			delete(tickets, id)
+		}
+	}
+	for id, ticket := range tc.indexed {
+		if _, pending := tc.pending[id]; !pending {
+			tickets[id] = ticket
+		}
+	}
+
+	stats.Record(context.Background(), cacheTotalItems.M(int64(previousCount)))
+	stats.Record(context.Background(), totalActiveTickets.M(int64(len(tickets))))
+	stats.Record(context.Background(), cacheUpdateLatency.M(float64(time.Since(t))/float64(time.Millisecond)))
+
+	logger.Debugf("Ticket Cache update from the change stream: Previous %d, Current %d, Pending %d", previousCount, len(tickets), len(tc.pending))
+	return nil
+}
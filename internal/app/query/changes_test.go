@@ -0,0 +1,109 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func ticketIDSet(tickets map[string]*pb.Ticket) map[string]struct{} {
+	ids := make(map[string]struct{}, len(tickets))
+	for id := range tickets {
+		ids[id] = struct{}{}
+	}
+	return ids
+}
+
+func TestTicketChanges(t *testing.T) {
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	cfg.Set(config.KeyTicketChangeStream, true)
+	cfg.Set(config.KeyPendingReleaseTimeout, "1m")
+	ctx := utilTesting.NewContext(t)
+
+	index := func(id string) {
+		ticket := &pb.Ticket{Id: id}
+		require.NoError(t, store.CreateTicket(ctx, ticket))
+		require.NoError(t, store.IndexTicket(ctx, ticket))
+	}
+	tc := newTicketChanges(cfg, store)
+	tickets := make(map[string]*pb.Ticket)
+
+	// Queries fail until the first snapshot.
+	err := tc.update(store, tickets)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	index("a")
+	index("b")
+	require.NoError(t, store.AddTicketsToPendingRelease(ctx, []*pb.Match{{Tickets: []*pb.Ticket{{Id: "b"}}}}))
+
+	require.NoError(t, tc.follow(ctx))
+	require.NoError(t, tc.update(store, tickets))
+	require.Equal(t, map[string]struct{}{"a": {}}, ticketIDSet(tickets))
+
+	index("c")
+	require.NoError(t, store.DeindexTicket(ctx, "a"))
+	require.NoError(t, store.DeleteTicketsFromPendingRelease(ctx, []string{"b"}))
+
+	require.NoError(t, tc.follow(ctx))
+	require.NoError(t, tc.update(store, tickets))
+	require.Equal(t, map[string]struct{}{"b": {}, "c": {}}, ticketIDSet(tickets))
+
+	// Pending tickets are released by time, without a change.
+	require.NoError(t, store.AddTicketsToPendingRelease(ctx, []*pb.Match{{Tickets: []*pb.Ticket{{Id: "c"}}}}))
+	require.NoError(t, tc.follow(ctx))
+	require.NoError(t, tc.update(store, tickets))
+	require.Equal(t, map[string]struct{}{"b": {}}, ticketIDSet(tickets))
+
+	cfg.Set(config.KeyPendingReleaseTimeout, "1ns")
+	require.NoError(t, tc.update(store, tickets))
+	require.Equal(t, map[string]struct{}{"b": {}, "c": {}}, ticketIDSet(tickets))
+}
+
+func TestTicketChangesFallBehind(t *testing.T) {
+	cfg := viper.New()
+	store, closer := statestoreTesting.NewStoreServiceForTesting(t, cfg)
+	defer closer()
+	cfg.Set(config.KeyTicketChangeStream, true)
+	cfg.Set(config.KeyTicketChangeStreamMaxLen, 2)
+	ctx := utilTesting.NewContext(t)
+
+	tc := newTicketChanges(cfg, store)
+	require.NoError(t, tc.follow(ctx))
+
+	for _, id := range []string{"a", "b", "c"} {
+		ticket := &pb.Ticket{Id: id}
+		require.NoError(t, store.CreateTicket(ctx, ticket))
+		require.NoError(t, store.IndexTicket(ctx, ticket))
+	}
+
+	// The snapshot's position has been trimmed, so a new snapshot is taken.
+	require.NoError(t, tc.follow(ctx))
+	tickets := make(map[string]*pb.Ticket)
+	require.NoError(t, tc.update(store, tickets))
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}, "c": {}}, ticketIDSet(tickets))
+	require.Equal(t, time.Duration(0), tc.interval())
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Paginated queries are opt-in.  A caller which sets the limit metadata key
+// receives at most that many results, and if more results remain, a cursor
+// in the response trailer.  Passing that cursor back on the next call resumes
+// from a point-in-time snapshot of the query results, so tickets which are
+// created or removed between calls don't cause results to be skipped or
+// duplicated.  When ticket events are published, tickets which are matched,
+// assigned or deleted after the snapshot is taken are left out of its later
+// pages, which may then hold fewer than limit results.
+//
+// A cursor may only be passed back with the same pool it was returned for.
+// Snapshots are held in the memory of the query service replica which took
+// them, so a cursor is only valid on that replica: clients paging through
+// results from behind a load balancer need sticky routing, and should restart
+// the query without a cursor when it is reported as not found.
+const (
+	// cursorLimitKey is the request metadata key holding the maximum number of
+	// results to return for this call.
+	cursorLimitKey = "x-open-match-query-limit"
+	// cursorKey is the request metadata key holding the cursor to resume from.
+	cursorKey = "x-open-match-query-cursor"
+	// nextCursorKey is the response trailer key holding the cursor for the next
+	// page.  It is absent when there are no more results.
+	nextCursorKey = "x-open-match-query-next-cursor"
+)
+
+// paging holds the pagination parameters of a query call.  A zero limit means
+// the call is not paginated.
+type paging struct {
+	limit      int
+	snapshotID string
+	offset     int
+}
+
+func readPaging(ctx context.Context) (*paging, error) {
+	p := &paging{}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return p, nil
+	}
+
+	if v := md.Get(cursorLimitKey); len(v) > 0 {
+		limit, err := strconv.Atoi(v[0])
+		if err != nil || limit <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be a positive integer, got %q", cursorLimitKey, v[0])
+		}
+		p.limit = limit
+	}
+
+	if v := md.Get(cursorKey); len(v) > 0 && v[0] != "" {
+		if p.limit == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "%s requires %s to be set", cursorKey, cursorLimitKey)
+		}
+		id, offset, err := decodeCursor(v[0])
+		if err != nil {
+			return nil, err
+		}
+		p.snapshotID = id
+		p.offset = offset
+	}
+
+	return p, nil
+}
+
+func encodeCursor(snapshotID string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", snapshotID, offset)))
+}
+
+func decodeCursor(cursor string) (string, int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, status.Error(codes.InvalidArgument, "malformed query cursor")
+	}
+
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, status.Error(codes.InvalidArgument, "malformed query cursor")
+	}
+
+	offset, err := strconv.Atoi(parts[1])
+	if err != nil || offset < 0 {
+		return "", 0, status.Error(codes.InvalidArgument, "malformed query cursor")
+	}
+
+	return parts[0], offset, nil
+}
+
+type snapshot struct {
+	// pool is the hash of the pool the snapshot was taken for.
+	pool    [sha256.Size]byte
+	tickets []*pb.Ticket
+	expires time.Time
+	// removed holds the ids of the tickets of the snapshot which are no
+	// longer active, and so are left out of the pages still to be read.
+	removed map[string]struct{}
+}
+
+// snapshotStore holds the results of paginated queries until either all pages
+// have been read, or the snapshot has expired.
+type snapshotStore struct {
+	cfg       config.View
+	mu        sync.Mutex
+	snapshots map[string]*snapshot
+}
+
+func newSnapshotStore(cfg config.View) *snapshotStore {
+	return &snapshotStore{
+		cfg:       cfg,
+		snapshots: make(map[string]*snapshot),
+	}
+}
+
+// hashPool returns the hash binding a snapshot to the pool it was taken for.
+func hashPool(pool *pb.Pool) ([sha256.Size]byte, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(pool)
+	if err != nil {
+		return [sha256.Size]byte{}, status.Errorf(codes.InvalidArgument, "failed to marshal pool: %v", err)
+	}
+	return sha256.Sum256(b), nil
+}
+
+// put stores the tickets, sorted by id, and returns the snapshot id.  It
+// returns ResourceExhausted when the maximum number of live snapshots is
+// reached.
+func (s *snapshotStore) put(pool [sha256.Size]byte, tickets []*pb.Ticket) (string, error) {
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].GetId() < tickets[j].GetId()
+	})
+
+	now := time.Now()
+	id := xid.New().String()
+	cfg := config.GetQuery(s.cfg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(now)
+	if len(s.snapshots) >= cfg.MaxSnapshots {
+		return "", status.Errorf(codes.ResourceExhausted, "too many paginated queries in progress, at most %d are kept", cfg.MaxSnapshots)
+	}
+	s.snapshots[id] = &snapshot{
+		pool:    pool,
+		tickets: tickets,
+		expires: now.Add(cfg.CursorTTL),
+	}
+	return id, nil
+}
+
+// get returns the tickets of the snapshot, NotFound if it has expired or was
+// taken by another replica, or InvalidArgument if it was taken for another
+// pool.
+func (s *snapshotStore) get(id string, pool [sha256.Size]byte) ([]*pb.Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(time.Now())
+
+	snap, ok := s.snapshots[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "query cursor has expired or belongs to another query service replica, restart the query without a cursor")
+	}
+	if snap.pool != pool {
+		return nil, status.Error(codes.InvalidArgument, "query cursor was returned for a different pool")
+	}
+	return snap.tickets, nil
+}
+
+// invalidate leaves the tickets out of the pages of the snapshots still to be
+// read, as they are no longer active.
+func (s *snapshotStore) invalidate(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, snap := range s.snapshots {
+		for _, id := range ids {
+			i := sort.Search(len(snap.tickets), func(i int) bool {
+				return snap.tickets[i].GetId() >= id
+			})
+			if i < len(snap.tickets) && snap.tickets[i].GetId() == id {
+				if snap.removed == nil {
+					snap.removed = make(map[string]struct{})
+				}
+				snap.removed[id] = struct{}{}
+			}
+		}
+	}
+}
+
+// notify invalidates the tickets of events which are no longer active.
+func (s *snapshotStore) notify(events *ipb.TicketEvents) {
+	switch events.GetEvent().GetType() {
+	case pb.TicketEvent_MATCHED, pb.TicketEvent_RESERVED, pb.TicketEvent_ASSIGNED, pb.TicketEvent_DELETED:
+		s.invalidate(events.GetTicketIds())
+	}
+}
+
+// withoutRemoved returns the tickets of the page which haven't been
+// invalidated since the snapshot was taken.
+func (s *snapshotStore) withoutRemoved(id string, page []*pb.Ticket) []*pb.Ticket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap, ok := s.snapshots[id]
+	if !ok || len(snap.removed) == 0 {
+		return page
+	}
+
+	kept := make([]*pb.Ticket, 0, len(page))
+	for _, t := range page {
+		if _, ok := snap.removed[t.GetId()]; !ok {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// release drops the snapshot once its last page has been read.
+func (s *snapshotStore) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.snapshots, id)
+}
+
+func (s *snapshotStore) expireLocked(now time.Time) {
+	for id, snap := range s.snapshots {
+		if now.After(snap.expires) {
+			delete(s.snapshots, id)
+		}
+	}
+}
+
+// page returns the tickets of pool for the call described by p.  query is
+// only run when the call doesn't resume from an existing snapshot.  The
+// returned cursor is empty when no results remain after this page.
+func (s *snapshotStore) page(p *paging, pool *pb.Pool, query func() ([]*pb.Ticket, error)) ([]*pb.Ticket, string, error) {
+	hash, err := hashPool(pool)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id := p.snapshotID
+	var tickets []*pb.Ticket
+
+	if id == "" {
+		tickets, err = query()
+		if err != nil {
+			return nil, "", err
+		}
+		id, err = s.put(hash, tickets)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		tickets, err = s.get(id, hash)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	start := p.offset
+	if start > len(tickets) {
+		start = len(tickets)
+	}
+	end := start + p.limit
+	if end >= len(tickets) {
+		page := s.withoutRemoved(id, tickets[start:])
+		s.release(id)
+		return page, "", nil
+	}
+
+	return s.withoutRemoved(id, tickets[start:end]), encodeCursor(id, end), nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+var testPool = &pb.Pool{
+	Name:                "ranked",
+	TagPresentFilters:   []*pb.TagPresentFilter{{Tag: "ranked"}},
+	DoubleRangeFilters:  []*pb.DoubleRangeFilter{{DoubleArg: "mmr", Min: 10, Max: 20}},
+	StringEqualsFilters: []*pb.StringEqualsFilter{{StringArg: "mode", Value: "duel"}},
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id, offset, err := decodeCursor(encodeCursor("abc", 42))
+	require.NoError(t, err)
+	require.Equal(t, "abc", id)
+	require.Equal(t, 42, offset)
+
+	for _, bad := range []string{"!!!", encodeCursor("", 1), "YWJj", encodeCursor("abc", -1)} {
+		_, _, err = decodeCursor(bad)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), bad)
+	}
+}
+
+func TestReadPaging(t *testing.T) {
+	p, err := readPaging(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, p.limit)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(cursorLimitKey, "5", cursorKey, encodeCursor("abc", 10)))
+	p, err = readPaging(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &paging{limit: 5, snapshotID: "abc", offset: 10}, p)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(cursorLimitKey, "0"))
+	_, err = readPaging(ctx)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(cursorKey, encodeCursor("abc", 10)))
+	_, err = readPaging(ctx)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSnapshotPagesAreStable(t *testing.T) {
+	s := newSnapshotStore(viper.New())
+	pool := []*pb.Ticket{{Id: "c"}, {Id: "a"}, {Id: "e"}, {Id: "b"}, {Id: "d"}}
+	queries := 0
+	query := func() ([]*pb.Ticket, error) {
+		queries++
+		// Copy so that mutating the pool below doesn't change the snapshot.
+		return append([]*pb.Ticket{}, pool...), nil
+	}
+
+	page, next, err := s.page(&paging{limit: 2}, testPool, query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ids(page))
+	require.NotEmpty(t, next)
+
+	// Mutating the pool between pages must not affect the snapshot.
+	pool = append(pool[:1], &pb.Ticket{Id: "0"})
+
+	var results []string
+	results = append(results, ids(page)...)
+	for next != "" {
+		id, offset, err := decodeCursor(next)
+		require.NoError(t, err)
+		page, next, err = s.page(&paging{limit: 2, snapshotID: id, offset: offset}, testPool, query)
+		require.NoError(t, err)
+		results = append(results, ids(page)...)
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, results)
+	require.Equal(t, 1, queries)
+	require.Empty(t, s.snapshots)
+}
+
+func TestSnapshotInvalidated(t *testing.T) {
+	s := newSnapshotStore(viper.New())
+	query := func() ([]*pb.Ticket, error) {
+		return []*pb.Ticket{{Id: "a"}, {Id: "b"}, {Id: "c"}, {Id: "d"}, {Id: "e"}}, nil
+	}
+
+	page, next, err := s.page(&paging{limit: 2}, testPool, query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ids(page))
+
+	// Tickets which are no longer active are left out of the later pages,
+	// without shifting them.  Released tickets stay.
+	s.notify(&ipb.TicketEvents{TicketIds: []string{"c", "x"}, Event: &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED}})
+	s.notify(&ipb.TicketEvents{TicketIds: []string{"e"}, Event: &pb.TicketEvent{Type: pb.TicketEvent_MATCHED}})
+	s.notify(&ipb.TicketEvents{TicketIds: []string{"d"}, Event: &pb.TicketEvent{Type: pb.TicketEvent_RELEASED}})
+
+	id, offset, err := decodeCursor(next)
+	require.NoError(t, err)
+	page, next, err = s.page(&paging{limit: 2, snapshotID: id, offset: offset}, testPool, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d"}, ids(page))
+
+	id, offset, err = decodeCursor(next)
+	require.NoError(t, err)
+	page, next, err = s.page(&paging{limit: 2, snapshotID: id, offset: offset}, testPool, nil)
+	require.NoError(t, err)
+	require.Empty(t, page)
+	require.Empty(t, next)
+}
+
+func TestSnapshotExpired(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("queryCursorTTL", "-1s")
+	s := newSnapshotStore(cfg)
+
+	_, next, err := s.page(&paging{limit: 1}, testPool, func() ([]*pb.Ticket, error) {
+		return []*pb.Ticket{{Id: "a"}, {Id: "b"}}, nil
+	})
+	require.NoError(t, err)
+
+	id, offset, err := decodeCursor(next)
+	require.NoError(t, err)
+	_, _, err = s.page(&paging{limit: 1, snapshotID: id, offset: offset}, testPool, nil)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSnapshotOtherPool(t *testing.T) {
+	s := newSnapshotStore(viper.New())
+	_, next, err := s.page(&paging{limit: 1}, testPool, func() ([]*pb.Ticket, error) {
+		return []*pb.Ticket{{Id: "a"}, {Id: "b"}}, nil
+	})
+	require.NoError(t, err)
+
+	id, offset, err := decodeCursor(next)
+	require.NoError(t, err)
+	other := &pb.Pool{Name: "ranked", TagPresentFilters: []*pb.TagPresentFilter{{Tag: "casual"}}}
+	_, _, err = s.page(&paging{limit: 1, snapshotID: id, offset: offset}, other, nil)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	page, next, err := s.page(&paging{limit: 1, snapshotID: id, offset: offset}, testPool, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, ids(page))
+	require.Empty(t, next)
+}
+
+func TestSnapshotsExhausted(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("queryMaxSnapshots", 1)
+	s := newSnapshotStore(cfg)
+	query := func() ([]*pb.Ticket, error) {
+		return []*pb.Ticket{{Id: "a"}, {Id: "b"}}, nil
+	}
+
+	_, next, err := s.page(&paging{limit: 1}, testPool, query)
+	require.NoError(t, err)
+	_, _, err = s.page(&paging{limit: 1}, testPool, query)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Reading the last page releases the snapshot.
+	id, offset, err := decodeCursor(next)
+	require.NoError(t, err)
+	_, _, err = s.page(&paging{limit: 1, snapshotID: id, offset: offset}, testPool, nil)
+	require.NoError(t, err)
+	_, _, err = s.page(&paging{limit: 1}, testPool, query)
+	require.NoError(t, err)
+}
+
+func ids(tickets []*pb.Ticket) []string {
+	r := make([]string, 0, len(tickets))
+	for _, t := range tickets {
+		r = append(r, t.GetId())
+	}
+	return r
+}
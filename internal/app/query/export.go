@@ -0,0 +1,84 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/filter"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// ExportTickets streams the tickets which pass the pool filter, in batches of
+// queryPageSize.  Only the ticket ids are read up front.  Each batch of tickets
+// is read as the previous one is sent, and Send blocks while the caller's flow
+// control window is full, so the export proceeds at the pace of the caller.
+// Exports are not counted against the query client quotas, which pace match
+// functions.
+func (s *queryService) ExportTickets(req *pb.ExportTicketsRequest, responseServer pb.QueryService_ExportTicketsServer) error {
+	ctx := responseServer.Context()
+
+	pf, err := filter.NewPoolFilter(req.GetPool())
+	if err != nil {
+		return err
+	}
+
+	if err = authorizeAudit(ctx, s.cfg); err != nil {
+		return err
+	}
+
+	if err = authorizePartition(ctx, s.cfg, req.GetPool()); err != nil {
+		return err
+	}
+
+	states, err := s.ticketStates(ctx, req.GetIncludeInactive())
+	if err != nil {
+		return errors.Wrap(err, "ExportTickets: failed to list tickets")
+	}
+
+	// Export in id order, so an interrupted export is easy to compare against.
+	ids := make([]string, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	pSize := config.GetQuery(s.cfg).PageSize
+	for start := 0; start < len(ids); start += pSize {
+		end := start + pSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		tickets, ticketStates, err := s.ticketsWithStates(ctx, pf, ids[start:end], states)
+		if err != nil {
+			return errors.Wrap(err, "ExportTickets: failed to read tickets")
+		}
+		if len(tickets) == 0 {
+			continue
+		}
+
+		err = responseServer.Send(&pb.ExportTicketsResponse{
+			Tickets: tickets,
+			States:  ticketStates,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package query
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/rpc"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// authorizePartition returns PermissionDenied unless the calling client may
+// query the tickets of the pool's partition, so that match functions of one
+// game mode or studio can't select the players of another.
+func authorizePartition(ctx context.Context, cfg config.View, pool *pb.Pool) error {
+	client := rpc.ClientIdentity(ctx)
+	if config.GetPartitions(cfg).Allowed(client, pool.GetPartition()) {
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"client":    client,
+		"partition": pool.GetPartition(),
+	}).Warning("Query client is not allowed to query the partition, rejecting call.")
+	return status.Errorf(codes.PermissionDenied, "query client %q is not allowed partition %q by %s", client, pool.GetPartition(), config.KeyPartitionClients)
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"plugin"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/pkg/customfilter"
+)
+
+// loadFilterPlugins opens the Go plugins at paths, whose init functions
+// register their custom filter types with customfilter.Register.  A plugin
+// which fails to load fails the startup of the query service, rather than
+// making every pool using its filters invalid.  plugin.Open needs cgo, so the
+// query and minimatch images are built with it, on a base with glibc.
+func loadFilterPlugins(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	for _, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			return errors.Wrapf(err, "failed to load query filter plugin %s", path)
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"plugins": paths,
+		"filters": customfilter.Names(),
+	}).Info("Loaded query filter plugins.")
+	return nil
+}
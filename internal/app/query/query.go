@@ -15,12 +15,18 @@
 package query
 
 import (
+	"context"
+	"time"
+
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"google.golang.org/grpc"
 	"open-match.dev/open-match/internal/appmain"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/pubsub"
 	"open-match.dev/open-match/internal/statestore"
 	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/internal/worker"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -99,11 +105,43 @@ var (
 
 // BindService creates the query service and binds it to the serving harness.
 func BindService(p *appmain.Params, b *appmain.Bindings) error {
+	err := loadFilterPlugins(config.GetQuery(p.Config()).FilterPlugins)
+	if err != nil {
+		return err
+	}
+	events, err := pubsub.New(p.Config())
+	if err != nil {
+		return err
+	}
+	if events != nil {
+		b.AddCloserErr(events.Close)
+	}
+	workers := worker.NewPool(p.Config())
+	b.AddCloser(workers.Close)
+
 	store := statestore.New(p.Config())
+	b.AddDependency("redis", store.HealthCheck)
+	tc := newTicketCache(b, store)
+	if config.GetQuery(p.Config()).Source == config.QuerySourceChangeStream {
+		changes := newTicketChanges(p.Config(), store)
+		tc.update = changes.update
+		changes.start(workers)
+	}
 	service := &queryService{
-		cfg: p.Config(),
-		tc:  newTicketCache(b, store),
-		bc:  newBackfillCache(b, store),
+		cfg:       p.Config(),
+		store:     store,
+		tc:        tc,
+		bc:        newBackfillCache(b, store),
+		snapshots: newSnapshotStore(p.Config()),
+		quotas:    newQuotaTracker(p.Config()),
+	}
+
+	if events != nil {
+		workers.Every("invalidate_snapshots", func() time.Duration {
+			return config.GetBackoff(p.Config()).InitialInterval
+		}, func(ctx context.Context) error {
+			return pubsub.SubscribeTicketEvents(ctx, events, service.snapshots.notify)
+		})
 	}
 
 	b.AddHandleFunc(func(s *grpc.Server) {
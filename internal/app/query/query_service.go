@@ -15,14 +15,18 @@
 package query
 
 import (
+	"context"
+
 	"go.opencensus.io/stats"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/filter"
+	"open-match.dev/open-match/internal/statestore"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -36,9 +40,12 @@ var (
 // queryService API provides utility functions for common MMF functionality such
 // as retrieving Tickets from state storage.
 type queryService struct {
-	cfg config.View
-	tc  *cache
-	bc  *cache
+	cfg       config.View
+	store     statestore.Service
+	tc        *cache
+	bc        *cache
+	snapshots *snapshotStore
+	quotas    *quotaTracker
 }
 
 func (s *queryService) QueryTickets(req *pb.QueryTicketsRequest, responseServer pb.QueryService_QueryTicketsServer) error {
@@ -53,36 +60,102 @@ func (s *queryService) QueryTickets(req *pb.QueryTicketsRequest, responseServer
 		return err
 	}
 
-	var results []*pb.Ticket
-	err = s.tc.request(ctx, func(value interface{}) {
-		tickets, ok := value.(map[string]*pb.Ticket)
-		if !ok {
-			logger.Errorf("expecting value type map[string]*pb.Ticket, but got: %T", value)
-			return
-		}
+	if err = authorizePartition(ctx, s.cfg, pool); err != nil {
+		return err
+	}
+	if err = checkBlackout(s.cfg, pool); err != nil {
+		return err
+	}
 
-		for _, ticket := range tickets {
-			if pf.In(ticket) {
-				results = append(results, ticket)
-			}
+	p, err := readPaging(ctx)
+	if err != nil {
+		return err
+	}
+
+	if pf.ReportExpired && (p.limit > 0 || req.GetIncludeInactive()) {
+		return status.Error(codes.InvalidArgument, ".pool.report_expired is not supported with paginated or include_inactive queries")
+	}
+
+	if req.GetIncludeInactive() {
+		if p.limit > 0 {
+			return status.Error(codes.InvalidArgument, ".include_inactive is not supported with paginated queries")
 		}
-	})
+		if err = authorizeAudit(ctx, s.cfg); err != nil {
+			return err
+		}
+	}
+
+	client, err := s.quotas.admit(ctx)
 	if err != nil {
-		err = errors.Wrap(err, "QueryTickets: failed to run request")
 		return err
 	}
+
+	var results []*pb.Ticket
+	var states []pb.QueryTicketsResponse_State
+	var expired []string
+	if req.GetIncludeInactive() {
+		results, states, err = s.auditTickets(ctx, pf)
+		if err != nil {
+			return errors.Wrap(err, "QueryTickets: failed to run request")
+		}
+	} else if p.limit > 0 {
+		var next string
+		results, next, err = s.snapshots.page(p, pool, func() ([]*pb.Ticket, error) {
+			return s.queryTickets(ctx, pf, nil)
+		})
+		if err != nil {
+			return errors.Wrap(err, "QueryTickets: failed to run request")
+		}
+		if next != "" {
+			responseServer.SetTrailer(metadata.Pairs(nextCursorKey, next))
+		}
+	} else {
+		buf := getTicketSlice()
+		defer putTicketSlice(buf)
+		results, err = s.queryTickets(ctx, pf, *buf)
+		*buf = results
+		if err != nil {
+			return errors.Wrap(err, "QueryTickets: failed to run request")
+		}
+		if pf.ReportExpired {
+			expired, err = s.expiredTicketIDs(ctx, pf)
+			if err != nil {
+				return errors.Wrap(err, "QueryTickets: failed to run request")
+			}
+		}
+	}
 	stats.Record(ctx, ticketsPerQuery.M(int64(len(results))))
+	s.quotas.record(client, len(results))
 
-	pSize := getPageSize(s.cfg)
+	// A sent response may still be read after Send returns, eg by stats
+	// handlers, so each page is a new response, and copies its tickets out of
+	// results, which is returned to the pool once the call ends.
+	pSize := config.GetQuery(s.cfg).PageSize
 	for start := 0; start < len(results); start += pSize {
 		end := start + pSize
 		if end > len(results) {
 			end = len(results)
 		}
 
-		err := responseServer.Send(&pb.QueryTicketsResponse{
-			Tickets: results[start:end],
-		})
+		resp := &pb.QueryTicketsResponse{
+			Tickets: append([]*pb.Ticket(nil), results[start:end]...),
+		}
+		if states != nil {
+			resp.States = states[start:end]
+		}
+		err := responseServer.Send(resp)
+		if err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(expired); start += pSize {
+		end := start + pSize
+		if end > len(expired) {
+			end = len(expired)
+		}
+
+		err := responseServer.Send(&pb.QueryTicketsResponse{ExpiredIds: expired[start:end]})
 		if err != nil {
 			return err
 		}
@@ -103,36 +176,60 @@ func (s *queryService) QueryTicketIds(req *pb.QueryTicketIdsRequest, responseSer
 		return err
 	}
 
-	var results []string
-	err = s.tc.request(ctx, func(value interface{}) {
-		tickets, ok := value.(map[string]*pb.Ticket)
-		if !ok {
-			logger.Errorf("expecting value type map[string]*pb.Ticket, but got: %T", value)
-			return
-		}
+	if err = authorizePartition(ctx, s.cfg, pool); err != nil {
+		return err
+	}
+	if err = checkBlackout(s.cfg, pool); err != nil {
+		return err
+	}
 
-		for id, ticket := range tickets {
-			if pf.In(ticket) {
-				results = append(results, id)
-			}
-		}
-	})
+	p, err := readPaging(ctx)
 	if err != nil {
-		err = errors.Wrap(err, "QueryTicketIds: failed to run request")
 		return err
 	}
-	stats.Record(ctx, ticketsPerQuery.M(int64(len(results))))
 
-	pSize := getPageSize(s.cfg)
-	for start := 0; start < len(results); start += pSize {
+	client, err := s.quotas.admit(ctx)
+	if err != nil {
+		return err
+	}
+
+	var tickets []*pb.Ticket
+	if p.limit > 0 {
+		var next string
+		tickets, next, err = s.snapshots.page(p, pool, func() ([]*pb.Ticket, error) {
+			return s.queryTickets(ctx, pf, nil)
+		})
+		if err != nil {
+			return errors.Wrap(err, "QueryTicketIds: failed to run request")
+		}
+		if next != "" {
+			responseServer.SetTrailer(metadata.Pairs(nextCursorKey, next))
+		}
+	} else {
+		buf := getTicketSlice()
+		defer putTicketSlice(buf)
+		tickets, err = s.queryTickets(ctx, pf, *buf)
+		*buf = tickets
+		if err != nil {
+			return errors.Wrap(err, "QueryTicketIds: failed to run request")
+		}
+	}
+
+	stats.Record(ctx, ticketsPerQuery.M(int64(len(tickets))))
+	s.quotas.record(client, len(tickets))
+
+	pSize := config.GetQuery(s.cfg).PageSize
+	for start := 0; start < len(tickets); start += pSize {
 		end := start + pSize
-		if end > len(results) {
-			end = len(results)
+		if end > len(tickets) {
+			end = len(tickets)
 		}
 
-		err := responseServer.Send(&pb.QueryTicketIdsResponse{
-			Ids: results[start:end],
-		})
+		ids := make([]string, 0, end-start)
+		for _, ticket := range tickets[start:end] {
+			ids = append(ids, ticket.GetId())
+		}
+		err := responseServer.Send(&pb.QueryTicketIdsResponse{Ids: ids})
 		if err != nil {
 			return err
 		}
@@ -141,6 +238,45 @@ func (s *queryService) QueryTicketIds(req *pb.QueryTicketIdsRequest, responseSer
 	return nil
 }
 
+// queryTickets appends the tickets in the cache which pass the pool filter to
+// results.
+func (s *queryService) queryTickets(ctx context.Context, pf *filter.PoolFilter, results []*pb.Ticket) ([]*pb.Ticket, error) {
+	err := s.tc.request(ctx, func(value interface{}) {
+		tickets, ok := value.(map[string]*pb.Ticket)
+		if !ok {
+			logger.Errorf("expecting value type map[string]*pb.Ticket, but got: %T", value)
+			return
+		}
+
+		for _, ticket := range tickets {
+			if pf.In(ticket) {
+				results = append(results, ticket)
+			}
+		}
+	})
+	return results, err
+}
+
+// expiredTicketIDs returns the ids of the tickets in the cache which pass the
+// pool filter except for its max age.
+func (s *queryService) expiredTicketIDs(ctx context.Context, pf *filter.PoolFilter) ([]string, error) {
+	var ids []string
+	err := s.tc.request(ctx, func(value interface{}) {
+		tickets, ok := value.(map[string]*pb.Ticket)
+		if !ok {
+			logger.Errorf("expecting value type map[string]*pb.Ticket, but got: %T", value)
+			return
+		}
+
+		for _, ticket := range tickets {
+			if pf.Expired(ticket) {
+				ids = append(ids, ticket.GetId())
+			}
+		}
+	})
+	return ids, err
+}
+
 func (s *queryService) QueryBackfills(req *pb.QueryBackfillsRequest, responseServer pb.QueryService_QueryBackfillsServer) error {
 	ctx := responseServer.Context()
 	pool := req.GetPool()
@@ -153,6 +289,10 @@ func (s *queryService) QueryBackfills(req *pb.QueryBackfillsRequest, responseSer
 		return err
 	}
 
+	if err = checkBlackout(s.cfg, pool); err != nil {
+		return err
+	}
+
 	var results []*pb.Backfill
 	err = s.bc.request(ctx, func(value interface{}) {
 		backfills, ok := value.(map[string]*pb.Backfill)
@@ -173,7 +313,7 @@ func (s *queryService) QueryBackfills(req *pb.QueryBackfillsRequest, responseSer
 	}
 	stats.Record(ctx, backfillsPerQuery.M(int64(len(results))))
 
-	pSize := getPageSize(s.cfg)
+	pSize := config.GetQuery(s.cfg).PageSize
 	for start := 0; start < len(results); start += pSize {
 		end := start + pSize
 		if end > len(results) {
@@ -191,34 +331,11 @@ func (s *queryService) QueryBackfills(req *pb.QueryBackfillsRequest, responseSer
 	return nil
 }
 
-func getPageSize(cfg config.View) int {
-	const (
-		name = "queryPageSize"
-		// Minimum number of tickets to be returned in a streamed response for QueryTickets. This value
-		// will be used if page size is configured lower than the minimum value.
-		minPageSize int = 10
-		// Default number of tickets to be returned in a streamed response for QueryTickets.  This value
-		// will be used if page size is not configured.
-		defaultPageSize int = 1000
-		// Maximum number of tickets to be returned in a streamed response for QueryTickets. This value
-		// will be used if page size is configured higher than the maximum value.
-		maxPageSize int = 10000
-	)
-
-	if !cfg.IsSet(name) {
-		return defaultPageSize
-	}
-
-	pSize := cfg.GetInt(name)
-	if pSize < minPageSize {
-		logger.Infof("page size %v is lower than the minimum limit of %v", pSize, maxPageSize)
-		pSize = minPageSize
-	}
-
-	if pSize > maxPageSize {
-		logger.Infof("page size %v is higher than the maximum limit of %v", pSize, maxPageSize)
-		return maxPageSize
+// QueryServerCapacity returns the unexpired game server capacity published by allocators.
+func (s *queryService) QueryServerCapacity(ctx context.Context, req *pb.QueryServerCapacityRequest) (*pb.QueryServerCapacityResponse, error) {
+	capacities, err := s.store.GetServerCapacity(ctx, req.GetRegion())
+	if err != nil {
+		return nil, err
 	}
-
-	return pSize
+	return &pb.QueryServerCapacityResponse{Capacities: capacities}, nil
 }
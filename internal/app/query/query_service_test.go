@@ -15,54 +15,69 @@
 package query
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/spf13/viper"
-	"github.com/stretchr/testify/require"
-	"open-match.dev/open-match/internal/config"
+	"google.golang.org/grpc"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/pb"
 )
 
-func TestGetPageSize(t *testing.T) {
-	testCases := []struct {
-		name      string
-		configure func(config.Mutable)
-		expected  int
-	}{
-		{
-			"notSet",
-			func(cfg config.Mutable) {},
-			1000,
-		},
-		{
-			"set",
-			func(cfg config.Mutable) {
-				cfg.Set("queryPageSize", "2156")
-			},
-			2156,
-		},
-		{
-			"low",
-			func(cfg config.Mutable) {
-				cfg.Set("queryPageSize", "9")
+type benchmarkQueryTicketsServer struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *benchmarkQueryTicketsServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *benchmarkQueryTicketsServer) Send(*pb.QueryTicketsResponse) error {
+	return nil
+}
+
+func BenchmarkQueryTickets(b *testing.B) {
+	tickets := make(map[string]*pb.Ticket)
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("ticket-%d", i)
+		tickets[id] = &pb.Ticket{
+			Id: id,
+			SearchFields: &pb.SearchFields{
+				DoubleArgs: map[string]float64{"level": float64(i % 100)},
 			},
-			10,
+		}
+	}
+
+	tc := &cache{
+		requests:        make(chan *cacheRequest),
+		startRunRequest: make(chan struct{}, 1),
+		value:           tickets,
+		update: func(statestore.Service, interface{}) error {
+			return nil
 		},
-		{
-			"high",
-			func(cfg config.Mutable) {
-				cfg.Set("queryPageSize", "10001")
-			},
-			10000,
+	}
+	tc.startRunRequest <- struct{}{}
+
+	cfg := viper.New()
+	s := &queryService{
+		cfg:    cfg,
+		tc:     tc,
+		quotas: newQuotaTracker(cfg),
+	}
+	req := &pb.QueryTicketsRequest{
+		Pool: &pb.Pool{
+			DoubleRangeFilters: []*pb.DoubleRangeFilter{{DoubleArg: "level", Min: 0, Max: 50}},
 		},
 	}
+	stream := &benchmarkQueryTicketsServer{ctx: context.Background()}
 
-	for _, tt := range testCases {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			cfg := viper.New()
-			tt.configure(cfg)
-			actual := getPageSize(cfg)
-			require.Equal(t, tt.expected, actual)
-		})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := s.QueryTickets(req, stream); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
@@ -0,0 +1,164 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/rpc"
+)
+
+// Per-client quotas protect the query service from a misconfigured match
+// function which queries an unbounded pool in a tight loop.  Each client gets
+// a token bucket for calls and one for returned tickets, both holding one
+// second of tokens, and at least one.  The ticket count of a call is only
+// known after it ran, so a call is admitted while any ticket tokens remain,
+// and may leave the bucket in debt.
+
+// clientQuotaIdleTimeout is how long a client's buckets are kept after its
+// last call, at least.  Buckets still in debt are kept until they refill.
+const clientQuotaIdleTimeout = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the last refill, up to one second's
+// worth.  At least one token is kept, so that rates below one per second
+// still allow calls.
+func (b *bucket) refill(now time.Time, rate float64) {
+	capacity := math.Max(rate, 1)
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+}
+
+// full returns true if the bucket refilled to its capacity.  Buckets of
+// quotas which are off are always full.
+func (b *bucket) full(now time.Time, rate float64) bool {
+	if rate <= 0 {
+		return true
+	}
+	b.refill(now, rate)
+	return b.tokens >= math.Max(rate, 1)
+}
+
+type clientQuota struct {
+	queries  bucket
+	tickets  bucket
+	lastSeen time.Time
+}
+
+// quotaTracker tracks the per-client quotas of the query service.
+type quotaTracker struct {
+	cfg     config.View
+	mu      sync.Mutex
+	clients map[string]*clientQuota
+}
+
+func newQuotaTracker(cfg config.View) *quotaTracker {
+	return &quotaTracker{
+		cfg:     cfg,
+		clients: make(map[string]*clientQuota),
+	}
+}
+
+// admit charges one call to the calling client, and returns ResourceExhausted
+// if the client is out of either quota.  The returned client is passed to
+// record once the call's results are known.
+func (q *quotaTracker) admit(ctx context.Context) (string, error) {
+	settings := config.GetQuery(q.cfg)
+	qps, tps := settings.ClientQPS, settings.ClientTicketsPerSecond
+	if qps <= 0 && tps <= 0 {
+		return "", nil
+	}
+
+	client := rpc.ClientIdentity(ctx)
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expireLocked(now, qps, tps)
+
+	c, ok := q.clients[client]
+	if !ok {
+		c = &clientQuota{}
+		q.clients[client] = c
+	}
+	c.lastSeen = now
+
+	if qps > 0 {
+		c.queries.refill(now, qps)
+		if c.queries.tokens < 1 {
+			return "", q.exhausted(client, "query rate")
+		}
+	}
+	if tps > 0 {
+		c.tickets.refill(now, tps)
+		if c.tickets.tokens <= 0 {
+			return "", q.exhausted(client, "returned ticket volume")
+		}
+	}
+
+	if qps > 0 {
+		c.queries.tokens--
+	}
+	return client, nil
+}
+
+// record charges the tickets returned by a call to the client's quota.
+func (q *quotaTracker) record(client string, tickets int) {
+	if client == "" {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if c, ok := q.clients[client]; ok {
+		c.tickets.tokens -= float64(tickets)
+	}
+}
+
+func (q *quotaTracker) exhausted(client, quota string) error {
+	logger.WithFields(logrus.Fields{
+		"client": client,
+		"quota":  quota,
+	}).Warning("Query client exceeded its quota, rejecting call.")
+	return status.Errorf(codes.ResourceExhausted, "query client %q exceeded its %s quota", client, quota)
+}
+
+// expireLocked forgets the clients idle for clientQuotaIdleTimeout whose
+// buckets refilled, so that a client can't idle its way out of ticket debt.
+func (q *quotaTracker) expireLocked(now time.Time, qps, tps float64) {
+	for client, c := range q.clients {
+		if now.Sub(c.lastSeen) <= clientQuotaIdleTimeout {
+			continue
+		}
+		if c.queries.full(now, qps) && c.tickets.full(now, tps) {
+			delete(q.clients, client)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+)
+
+func peerContext(addr string) context.Context {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		panic(err)
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr})
+}
+
+func TestQuotaUnlimitedByDefault(t *testing.T) {
+	q := newQuotaTracker(viper.New())
+	ctx := peerContext("10.0.0.1:1234")
+
+	for i := 0; i < 100; i++ {
+		client, err := q.admit(ctx)
+		require.NoError(t, err)
+		q.record(client, 1000)
+	}
+	require.Empty(t, q.clients)
+}
+
+func TestQuotaQueriesPerSecond(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(config.KeyQueryClientQPS, 2)
+	q := newQuotaTracker(cfg)
+
+	// Connections from the same host share a quota.
+	for _, addr := range []string{"10.0.0.1:1234", "10.0.0.1:5678"} {
+		client, err := q.admit(peerContext(addr))
+		require.NoError(t, err)
+		require.Equal(t, "10.0.0.1", client)
+	}
+	_, err := q.admit(peerContext("10.0.0.1:1234"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Other clients are unaffected.
+	_, err = q.admit(peerContext("10.0.0.2:1234"))
+	require.NoError(t, err)
+}
+
+func TestQuotaTicketsPerSecond(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(config.KeyQueryClientTicketsPerSecond, 100)
+	q := newQuotaTracker(cfg)
+	ctx := peerContext("10.0.0.1:1234")
+
+	// A call is admitted while any ticket quota remains, even if it returns
+	// more tickets than that.
+	client, err := q.admit(ctx)
+	require.NoError(t, err)
+	q.record(client, 60)
+
+	client, err = q.admit(ctx)
+	require.NoError(t, err)
+	q.record(client, 60)
+
+	_, err = q.admit(ctx)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestQuotaIdleDebt(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(config.KeyQueryClientTicketsPerSecond, 100)
+	q := newQuotaTracker(cfg)
+	ctx := peerContext("10.0.0.1:1234")
+
+	client, err := q.admit(ctx)
+	require.NoError(t, err)
+	q.record(client, 100000)
+
+	// Idle for longer than the timeout, but not long enough to pay off the
+	// debt.
+	idle := func() {
+		c := q.clients[client]
+		c.lastSeen = c.lastSeen.Add(-2 * clientQuotaIdleTimeout)
+		c.tickets.last = c.tickets.last.Add(-2 * clientQuotaIdleTimeout)
+	}
+	idle()
+	_, err = q.admit(ctx)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Once the buckets refilled, idle clients are forgotten.
+	q.clients[client].tickets.tokens = 0
+	idle()
+	_, err = q.admit(peerContext("10.0.0.2:1234"))
+	require.NoError(t, err)
+	require.NotContains(t, q.clients, client)
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"time"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// evaluationsLog is the decision log in which every evaluation window is
+// summarized.
+const evaluationsLog = "evaluations"
+
+// cycleDecision summarizes what an evaluation window did, for /decisionz.
+type cycleDecision struct {
+	Start         time.Time      `json:"start"`
+	Registrations int            `json:"registrations"`
+	Proposals     int            `json:"proposals"`
+	Profiles      map[string]int `json:"profiles,omitempty"`
+	Accepted      int            `json:"accepted"`
+	Rejected      int            `json:"rejected"`
+	Oversized     int            `json:"oversized,omitempty"`
+	DurationMs    float64        `json:"durationMs"`
+	Error         string         `json:"error,omitempty"`
+}
+
+func newCycleDecision(start time.Time) *cycleDecision {
+	return &cycleDecision{Start: start.UTC(), Profiles: map[string]int{}}
+}
+
+// proposed counts a proposal, by the profile which made it.
+func (d *cycleDecision) proposed(m *pb.Match) {
+	d.Proposals++
+	d.Profiles[m.GetMatchProfile()]++
+}
+
+// rejected counts a rejection, whether it was made by the evaluator, or
+// before evaluation by the fairness policy or the match size limit.
+func (d *cycleDecision) rejected(r *pb.MatchRejection) {
+	d.Rejected++
+	if r.GetMaxTickets() > 0 {
+		d.Oversized++
+	}
+}
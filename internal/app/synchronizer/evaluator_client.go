@@ -25,9 +25,11 @@ import (
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/faultinject"
 	"open-match.dev/open-match/internal/rpc"
 	"open-match.dev/open-match/pkg/pb"
 )
@@ -40,12 +42,18 @@ var (
 )
 
 type evaluator interface {
-	evaluate(context.Context, <-chan []*pb.Match, chan<- string) error
+	evaluate(context.Context, <-chan []*pb.Match, chan<- string, chan<- *pb.MatchRejection) error
+}
+
+// evaluatorHealthChecker is implemented by the evaluator clients to report
+// whether the evaluator is reachable.
+type evaluatorHealthChecker interface {
+	healthCheck(context.Context) error
 }
 
 var errNoEvaluatorType = status.Errorf(codes.FailedPrecondition, "unable to determine evaluator type, either api.evaluator.grpcport or api.evaluator.httpport must be specified in the config")
 
-func newEvaluator(cfg config.View) evaluator {
+func newEvaluator(cfg config.View) *deferredEvaluator {
 	newInstance := func(cfg config.View) (interface{}, func(), error) {
 		// grpc is preferred over http.
 		if cfg.IsSet("api.evaluator.grpcport") {
@@ -58,28 +66,48 @@ func newEvaluator(cfg config.View) evaluator {
 	}
 
 	return &deferredEvaluator{
+		cfg:    cfg,
 		cacher: config.NewCacher(cfg, newInstance),
 	}
 }
 
 type deferredEvaluator struct {
+	cfg    config.View
 	cacher *config.Cacher
 }
 
-func (de *deferredEvaluator) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string) error {
+func (de *deferredEvaluator) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string, rejections chan<- *pb.MatchRejection) error {
+	malformed, err := faultinject.Get(de.cfg, faultinject.TargetEvaluator).Inject(ctx)
+	if err != nil {
+		return err
+	}
+	if malformed {
+		acceptedIds <- faultinject.MalformedMatchID
+	}
+
 	e, err := de.cacher.Get()
 	if err != nil {
 		return err
 	}
 
-	err = e.(evaluator).evaluate(ctx, pc, acceptedIds)
+	err = e.(evaluator).evaluate(ctx, pc, acceptedIds, rejections)
 	if err != nil {
 		de.cacher.ForceReset()
 	}
 	return err
 }
 
+// healthCheck reports whether the configured evaluator is reachable.
+func (de *deferredEvaluator) healthCheck(ctx context.Context) error {
+	e, err := de.cacher.Get()
+	if err != nil {
+		return err
+	}
+	return e.(evaluatorHealthChecker).healthCheck(ctx)
+}
+
 type grcpEvaluatorClient struct {
+	conn      *grpc.ClientConn
 	evaluator pb.EvaluatorClient
 }
 
@@ -102,11 +130,16 @@ func newGrpcEvaluator(cfg config.View) (evaluator, func(), error) {
 	}
 
 	return &grcpEvaluatorClient{
+		conn:      conn,
 		evaluator: pb.NewEvaluatorClient(conn),
 	}, close, nil
 }
 
-func (ec *grcpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string) error {
+func (ec *grcpEvaluatorClient) healthCheck(ctx context.Context) error {
+	return rpc.WaitForReady(ctx, ec.conn)
+}
+
+func (ec *grcpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string, rejections chan<- *pb.MatchRejection) error {
 	eg, ctx := errgroup.WithContext(ctx)
 
 	var stream pb.Evaluator_EvaluateClient
@@ -149,6 +182,14 @@ func (ec *grcpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Mat
 				return fmt.Errorf("failed to get response from evaluator client, desc: %w", err)
 			}
 
+			if r := resp.GetRejection(); r != nil {
+				if _, ok := matchIDs.Load(r.GetMatchId()); !ok {
+					return fmt.Errorf("evaluator rejected match_id \"%s\" which does not correspond to its any match in its input", r.GetMatchId())
+				}
+				rejections <- r
+				continue
+			}
+
 			v, ok := matchIDs.Load(resp.GetMatchId())
 			if !ok {
 				return fmt.Errorf("evaluator returned match_id \"%s\" which does not correspond to its any match in its input", resp.GetMatchId())
@@ -194,11 +235,16 @@ func newHTTPEvaluator(cfg config.View) (evaluator, func(), error) {
 	}, close, nil
 }
 
-func (ec *httpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string) error {
+func (ec *httpEvaluatorClient) healthCheck(ctx context.Context) error {
+	return rpc.CheckHTTPHealth(ctx, ec.httpClient, ec.baseURL)
+}
+
+func (ec *httpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string, rejections chan<- *pb.MatchRejection) error {
 	reqr, reqw := io.Pipe()
 	var wg sync.WaitGroup
 	wg.Add(1)
 
+	matchIDs := &sync.Map{}
 	sc := make(chan error, 1)
 	defer close(sc)
 	go func() {
@@ -211,6 +257,7 @@ func (ec *httpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Mat
 		}()
 		for proposals := range pc {
 			for _, proposal := range proposals {
+				matchIDs.Store(proposal.GetMatchId(), true)
 				buf, err := m.MarshalToString(&pb.EvaluateRequest{Match: proposal})
 				if err != nil {
 					sc <- status.Errorf(codes.FailedPrecondition, "failed to marshal proposal to string: %s", err.Error())
@@ -271,6 +318,14 @@ func (ec *httpEvaluatorClient) evaluate(ctx context.Context, pc <-chan []*pb.Mat
 				rc <- status.Errorf(codes.Unavailable, "failed to execute jsonpb.UnmarshalString(%s, &proposal): %v.", item.Result, err)
 				return
 			}
+			if r := resp.GetRejection(); r != nil {
+				if _, ok := matchIDs.Load(r.GetMatchId()); !ok {
+					rc <- status.Errorf(codes.Unavailable, "evaluator rejected match_id \"%s\" which does not correspond to its any match in its input", r.GetMatchId())
+					return
+				}
+				rejections <- r
+				continue
+			}
 			acceptedIds <- resp.GetMatchId()
 		}
 	}()
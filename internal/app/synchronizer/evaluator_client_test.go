@@ -0,0 +1,54 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestHTTPEvaluatorUnknownRejection(t *testing.T) {
+	for _, id := range []string{"1", "unknown"} {
+		t.Run(id, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = ioutil.ReadAll(r.Body)
+				fmt.Fprintf(w, `{"result":{"rejection":{"matchId":%q}}}`, id)
+			}))
+			defer server.Close()
+			ec := &httpEvaluatorClient{httpClient: server.Client(), baseURL: server.URL}
+
+			pc := make(chan []*pb.Match, 1)
+			pc <- []*pb.Match{{MatchId: "1"}}
+			close(pc)
+			rejections := make(chan *pb.MatchRejection, 1)
+			err := ec.evaluate(context.Background(), pc, make(chan string, 1), rejections)
+
+			if id == "1" {
+				require.NoError(t, err)
+				require.Equal(t, "1", (<-rejections).GetMatchId())
+				return
+			}
+			require.Contains(t, err.Error(), `evaluator rejected match_id "unknown" which does not correspond to its any match in its input`)
+			require.Empty(t, rejections)
+		})
+	}
+}
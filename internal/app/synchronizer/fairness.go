@@ -0,0 +1,202 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+var (
+	profileKey = tag.MustNewKey("profile")
+
+	fairnessRejections = telemetry.Counter("open-match.dev/synchronizer/fairness_rejections", "matches rejected by the fairness policy", profileKey)
+)
+
+// fairness shares the tickets proposed by matches of several profiles in a
+// cycle between the profiles, before the matches are evaluated.  Without it
+// the evaluator picks between colliding matches on quality alone, so a
+// high-volume profile can claim every shared ticket, cycle after cycle.
+//
+// Profiles take turns by start-time fair queuing: each profile has a virtual
+// time, advanced by 1/weight for every match it is given, and the profile
+// with the lowest virtual time goes next.  Virtual times carry over between
+// cycles, so a profile which lost out in one cycle goes first in the next.
+// A profile's virtual time is raised to the current one when it proposes
+// again, so that idle profiles don't build up credit.
+//
+// A match is rejected if any of its tickets or its backfill were given to a
+// match of another profile.  Collisions between matches of the same profile
+// are left to the evaluator.
+type fairness struct {
+	mu          sync.Mutex
+	virtualTime float64
+	profiles    map[string]float64
+}
+
+func newFairness() *fairness {
+	return &fairness{
+		profiles: make(map[string]float64),
+	}
+}
+
+// filter returns the matches from in which are given their tickets, and the
+// rejections of the others.  Unless the policy is FairnessNone, it waits for all of the
+// cycle's matches, and sends the rejections once the matches are all sent.
+func (f *fairness) filter(settings config.Synchronizer, in <-chan *pb.Match) (<-chan *pb.Match, <-chan *pb.MatchRejection) {
+	rc := make(chan *pb.MatchRejection)
+	if settings.FairnessPolicy != config.FairnessRoundRobin && settings.FairnessPolicy != config.FairnessWeighted {
+		close(rc)
+		return in, rc
+	}
+
+	out := make(chan *pb.Match)
+	go func() {
+		var matches []*pb.Match
+		for m := range in {
+			matches = append(matches, m)
+		}
+
+		accepted, rejected := f.schedule(settings, matches)
+		for _, m := range accepted {
+			out <- m
+		}
+		close(out)
+
+		for _, r := range rejected {
+			rc <- r
+		}
+		close(rc)
+	}()
+	return out, rc
+}
+
+// schedule splits the matches into the accepted and the rejected ones.
+func (f *fairness) schedule(settings config.Synchronizer, matches []*pb.Match) ([]*pb.Match, []*pb.MatchRejection) {
+	weight := func(profile string) float64 {
+		if w, ok := settings.FairnessWeights[profile]; ok && settings.FairnessPolicy == config.FairnessWeighted {
+			return w
+		}
+		return 1
+	}
+
+	// Queue each profile's matches in the order they were proposed.  Ties
+	// between profiles go to the one which proposed first.
+	var order []string
+	queues := make(map[string][]*pb.Match)
+	for _, m := range matches {
+		p := m.GetMatchProfile()
+		if _, ok := queues[p]; !ok {
+			order = append(order, p)
+		}
+		queues[p] = append(queues[p], m)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, p := range order {
+		if vt, ok := f.profiles[p]; !ok || vt < f.virtualTime {
+			f.profiles[p] = f.virtualTime
+		}
+	}
+
+	ticketOwners := make(map[string]*pb.Match)
+	backfillOwners := make(map[string]*pb.Match)
+	var accepted []*pb.Match
+	var rejected []*pb.MatchRejection
+
+	for remaining := len(matches); remaining > 0; remaining-- {
+		next := ""
+		found := false
+		for _, p := range order {
+			if len(queues[p]) > 0 && (!found || f.profiles[p] < f.profiles[next]) {
+				next, found = p, true
+			}
+		}
+		m := queues[next][0]
+		queues[next] = queues[next][1:]
+		if f.profiles[next] > f.virtualTime {
+			f.virtualTime = f.profiles[next]
+		}
+
+		if r := collision(m, ticketOwners, backfillOwners); r != nil {
+			logger.WithFields(logrus.Fields{
+				"match_id":         m.GetMatchId(),
+				"profile":          next,
+				"winning_match_id": r.WinningMatchId,
+			}).Debug("Match collides with a match of another profile. Rejecting match.")
+			telemetry.RecordUnitMeasurement(context.Background(), fairnessRejections, tag.Upsert(profileKey, next))
+			rejected = append(rejected, r)
+			continue
+		}
+
+		for _, t := range m.GetTickets() {
+			if _, ok := ticketOwners[t.GetId()]; !ok {
+				ticketOwners[t.GetId()] = m
+			}
+		}
+		if id := m.GetBackfill().GetId(); id != "" {
+			if _, ok := backfillOwners[id]; !ok {
+				backfillOwners[id] = m
+			}
+		}
+		f.profiles[next] += 1 / weight(next)
+		accepted = append(accepted, m)
+	}
+
+	// Profiles at or behind the virtual time would be raised to it anyway.
+	for p, vt := range f.profiles {
+		if vt <= f.virtualTime {
+			delete(f.profiles, p)
+		}
+	}
+
+	return accepted, rejected
+}
+
+// collision returns the rejection of m if any of its tickets or its backfill
+// are owned by a match of another profile.
+func collision(m *pb.Match, ticketOwners, backfillOwners map[string]*pb.Match) *pb.MatchRejection {
+	var r *pb.MatchRejection
+	reject := func(owner *pb.Match) bool {
+		if owner == nil || owner.GetMatchProfile() == m.GetMatchProfile() {
+			return false
+		}
+		if r == nil {
+			r = &pb.MatchRejection{
+				MatchId:        m.GetMatchId(),
+				WinningMatchId: owner.GetMatchId(),
+			}
+		}
+		return true
+	}
+
+	if id := m.GetBackfill().GetId(); id != "" && reject(backfillOwners[id]) {
+		r.CollidingBackfillId = id
+	}
+	for _, t := range m.GetTickets() {
+		if reject(ticketOwners[t.GetId()]) {
+			r.CollidingTicketIds = append(r.CollidingTicketIds, t.GetId())
+		}
+	}
+	return r
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func fairnessMatch(id, profile string, ticketIDs ...string) *pb.Match {
+	m := &pb.Match{MatchId: id, MatchProfile: profile}
+	for _, tid := range ticketIDs {
+		m.Tickets = append(m.Tickets, &pb.Ticket{Id: tid})
+	}
+	return m
+}
+
+func matchIDs(matches []*pb.Match) []string {
+	ids := []string{}
+	for _, m := range matches {
+		ids = append(ids, m.GetMatchId())
+	}
+	return ids
+}
+
+func TestFairnessRoundRobin(t *testing.T) {
+	settings := config.Synchronizer{FairnessPolicy: config.FairnessRoundRobin}
+	f := newFairness()
+
+	accepted, rejected := f.schedule(settings, []*pb.Match{
+		fairnessMatch("a1", "a", "t1"),
+		fairnessMatch("a2", "a", "t2"),
+		fairnessMatch("b1", "b", "t1"),
+		fairnessMatch("b2", "b", "t2"),
+	})
+	require.Equal(t, []string{"a1", "b2"}, matchIDs(accepted))
+	require.Equal(t, []*pb.MatchRejection{
+		{MatchId: "b1", WinningMatchId: "a1", CollidingTicketIds: []string{"t1"}},
+		{MatchId: "a2", WinningMatchId: "b2", CollidingTicketIds: []string{"t2"}},
+	}, rejected)
+
+	// a proposed first, so wins the tie.
+	accepted, _ = f.schedule(settings, []*pb.Match{
+		fairnessMatch("a3", "a", "t3"),
+		fairnessMatch("b3", "b", "t3"),
+	})
+	require.Equal(t, []string{"a3"}, matchIDs(accepted))
+
+	// b lost out in the last cycle, so it goes first in this one, even though
+	// a proposed first.
+	accepted, _ = f.schedule(settings, []*pb.Match{
+		fairnessMatch("a4", "a", "t4"),
+		fairnessMatch("b4", "b", "t4"),
+	})
+	require.Equal(t, []string{"b4"}, matchIDs(accepted))
+}
+
+func TestFairnessWeighted(t *testing.T) {
+	settings := config.Synchronizer{
+		FairnessPolicy:  config.FairnessWeighted,
+		FairnessWeights: map[string]float64{"ranked": 3},
+	}
+
+	var matches []*pb.Match
+	for _, tid := range []string{"t1", "t2", "t3", "t4"} {
+		matches = append(matches, fairnessMatch("ranked-"+tid, "ranked", tid))
+	}
+	for _, tid := range []string{"t1", "t2", "t3", "t4"} {
+		matches = append(matches, fairnessMatch("casual-"+tid, "casual", tid))
+	}
+
+	accepted, rejected := newFairness().schedule(settings, matches)
+	require.ElementsMatch(t, []string{"ranked-t1", "casual-t2", "ranked-t3", "ranked-t4"}, matchIDs(accepted))
+	require.Len(t, rejected, 4)
+}
+
+func TestFairnessCollisions(t *testing.T) {
+	settings := config.Synchronizer{FairnessPolicy: config.FairnessRoundRobin}
+
+	a1 := fairnessMatch("a1", "a", "t1")
+	a1.Backfill = &pb.Backfill{Id: "bf1"}
+	a2 := fairnessMatch("a2", "a", "t1")
+	b1 := fairnessMatch("b1", "b", "t2", "t3")
+	b1.Backfill = &pb.Backfill{Id: "bf1"}
+
+	accepted, rejected := newFairness().schedule(settings, []*pb.Match{a1, a2, b1})
+
+	// Collisions within a profile are left to the evaluator.
+	require.Equal(t, []string{"a1", "a2"}, matchIDs(accepted))
+	require.Equal(t, []*pb.MatchRejection{
+		{MatchId: "b1", WinningMatchId: "a1", CollidingBackfillId: "bf1"},
+	}, rejected)
+}
+
+func TestFairnessFilter(t *testing.T) {
+	send := func(matches ...*pb.Match) <-chan *pb.Match {
+		in := make(chan *pb.Match, len(matches))
+		for _, m := range matches {
+			in <- m
+		}
+		close(in)
+		return in
+	}
+	receive := func(out <-chan *pb.Match, rc <-chan *pb.MatchRejection) ([]string, []string) {
+		accepted := []string{}
+		for m := range out {
+			accepted = append(accepted, m.GetMatchId())
+		}
+		rejected := []string{}
+		for r := range rc {
+			rejected = append(rejected, r.GetMatchId())
+		}
+		return accepted, rejected
+	}
+
+	f := newFairness()
+
+	accepted, rejected := receive(f.filter(config.Synchronizer{FairnessPolicy: config.FairnessNone}, send(
+		fairnessMatch("a1", "a", "t1"),
+		fairnessMatch("b1", "b", "t1"),
+	)))
+	require.Equal(t, []string{"a1", "b1"}, accepted)
+	require.Empty(t, rejected)
+
+	accepted, rejected = receive(f.filter(config.Synchronizer{FairnessPolicy: config.FairnessRoundRobin}, send(
+		fairnessMatch("a1", "a", "t1"),
+		fairnessMatch("b1", "b", "t1"),
+	)))
+	require.Equal(t, []string{"a1"}, accepted)
+	require.Equal(t, []string{"b1"}, rejected)
+}
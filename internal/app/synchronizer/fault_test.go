@@ -0,0 +1,47 @@
+// +build faultinject
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package synchronizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/faultinject"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestEvaluatorFaults(t *testing.T) {
+	cfg := viper.New()
+	eval := newEvaluator(cfg)
+	acceptedIds := make(chan string, 1)
+	rejections := make(chan *pb.MatchRejection)
+
+	cfg.Set("faultInjection.evaluator.mode", faultinject.ModeFail)
+	err := eval.evaluate(context.Background(), nil, acceptedIds, rejections)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Empty(t, acceptedIds)
+
+	// The evaluator is still called after the malformed result, and fails as
+	// none is configured.
+	cfg.Set("faultInjection.evaluator.mode", faultinject.ModeMalformed)
+	err = eval.evaluate(context.Background(), nil, acceptedIds, rejections)
+	require.Equal(t, errNoEvaluatorType, err)
+	require.Equal(t, faultinject.MalformedMatchID, <-acceptedIds)
+}
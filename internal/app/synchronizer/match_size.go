@@ -0,0 +1,74 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+var (
+	oversizedRejections = telemetry.Counter("open-match.dev/synchronizer/oversized_match_rejections", "matches rejected for having more tickets than the maximum per match", profileKey)
+)
+
+// limitMatchSize returns the matches from in which have at most the maximum
+// tickets per match of their profile, and the rejections of the others.  Match
+// functions aren't trusted to stay within the maximum, so that one buggy
+// proposal can't claim a large part of the pool.  The profile of a match is
+// that of the backend call which proposed it, so a match function can't pick
+// its own limit.  The rejections are sent once the matches are all sent.
+func limitMatchSize(settings config.Synchronizer, in <-chan *pb.Match) (<-chan *pb.Match, <-chan *pb.MatchRejection) {
+	rc := make(chan *pb.MatchRejection)
+	if settings.MaxTicketsPerMatch == 0 && len(settings.ProfileMaxTicketsPerMatch) == 0 {
+		close(rc)
+		return in, rc
+	}
+
+	out := make(chan *pb.Match)
+	go func() {
+		var rejected []*pb.MatchRejection
+		for m := range in {
+			limit := settings.MaxTickets(m.GetMatchProfile())
+			if limit == 0 || len(m.GetTickets()) <= limit {
+				out <- m
+				continue
+			}
+
+			logger.WithFields(logrus.Fields{
+				"match_id":    m.GetMatchId(),
+				"profile":     m.GetMatchProfile(),
+				"tickets":     len(m.GetTickets()),
+				"max_tickets": limit,
+			}).Warning("Match has more tickets than the maximum per match. Rejecting match.")
+			telemetry.RecordUnitMeasurement(context.Background(), oversizedRejections, tag.Upsert(profileKey, m.GetMatchProfile()))
+			rejected = append(rejected, &pb.MatchRejection{
+				MatchId:    m.GetMatchId(),
+				MaxTickets: int32(limit),
+			})
+		}
+		close(out)
+
+		for _, r := range rejected {
+			rc <- r
+		}
+		close(rc)
+	}()
+	return out, rc
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestLimitMatchSize(t *testing.T) {
+	settings := config.Synchronizer{
+		MaxTicketsPerMatch:        2,
+		ProfileMaxTicketsPerMatch: map[string]int{"raid": 4},
+	}
+
+	in := make(chan *pb.Match, 4)
+	in <- fairnessMatch("duel", "duel", "t1", "t2")
+	in <- fairnessMatch("huge", "duel", "t3", "t4", "t5")
+	in <- fairnessMatch("raid", "raid", "t6", "t7", "t8", "t9")
+	in <- fairnessMatch("hugeraid", "raid", "t1", "t2", "t3", "t4", "t5")
+	close(in)
+
+	out, rc := limitMatchSize(settings, in)
+	var accepted []*pb.Match
+	for m := range out {
+		accepted = append(accepted, m)
+	}
+	var rejected []*pb.MatchRejection
+	for r := range rc {
+		rejected = append(rejected, r)
+	}
+
+	require.Equal(t, []string{"duel", "raid"}, matchIDs(accepted))
+	require.Equal(t, []*pb.MatchRejection{
+		{MatchId: "huge", MaxTickets: 2},
+		{MatchId: "hugeraid", MaxTickets: 4},
+	}, rejected)
+}
+
+func TestLimitMatchSizeUnlimited(t *testing.T) {
+	in := make(chan *pb.Match)
+	out, rc := limitMatchSize(config.Synchronizer{}, in)
+	require.Equal(t, (<-chan *pb.Match)(in), out)
+	_, ok := <-rc
+	require.False(t, ok)
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// evaluateSharded evaluates the proposals on pc with one evaluator call per
+// profile group, in parallel.  Separate calls are balanced across evaluator
+// replicas, so the cycle isn't limited by the throughput of one.  This is only
+// correct if groups never share tickets, as collisions between groups are not
+// seen by any evaluator call.  Profiles not in groups share a group.  Without
+// groups, all proposals are evaluated by a single call.
+//
+// If any call fails, the others are canceled and the first error is returned.
+func evaluateSharded(ctx context.Context, eval evaluator, groups map[string]string, pc <-chan []*pb.Match, acceptedIds chan<- string, rejections chan<- *pb.MatchRejection) error {
+	if len(groups) == 0 {
+		return eval.evaluate(ctx, pc, acceptedIds, rejections)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	shards := make(map[string]chan *pb.Match)
+	for proposals := range pc {
+		for _, proposal := range proposals {
+			group := groups[proposal.GetMatchProfile()]
+			shard, ok := shards[group]
+			if !ok {
+				shard = make(chan *pb.Match)
+				shards[group] = shard
+				gpc := bufferMatchChannel(shard)
+				eg.Go(func() error {
+					// Drain the shard if the call fails, so the buffer isn't
+					// left blocked.
+					defer func() {
+						for range gpc {
+						}
+					}()
+					return eval.evaluate(ctx, gpc, acceptedIds, rejections)
+				})
+			}
+			shard <- proposal
+		}
+	}
+	for _, shard := range shards {
+		close(shard)
+	}
+	return eg.Wait()
+}
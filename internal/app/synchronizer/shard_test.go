@@ -0,0 +1,140 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synchronizer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// shardEvaluator accepts every proposal, and records the proposals of each
+// call.  With started set, each call waits until that many calls have started.
+type shardEvaluator struct {
+	mu      sync.Mutex
+	calls   [][]string
+	started sync.WaitGroup
+	err     error
+}
+
+func (e *shardEvaluator) evaluate(ctx context.Context, pc <-chan []*pb.Match, acceptedIds chan<- string, _ chan<- *pb.MatchRejection) error {
+	e.started.Done()
+	e.started.Wait()
+	if e.err != nil {
+		return e.err
+	}
+
+	var ids []string
+	for proposals := range pc {
+		for _, p := range proposals {
+			ids = append(ids, p.GetMatchId())
+			acceptedIds <- p.GetMatchId()
+		}
+	}
+	sort.Strings(ids)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, ids)
+	return nil
+}
+
+func runSharded(t *testing.T, e *shardEvaluator, groups map[string]string, proposals ...*pb.Match) ([]string, error) {
+	pc := make(chan []*pb.Match, 1)
+	pc <- proposals
+	close(pc)
+
+	acceptedIds := make(chan string)
+	var accepted []string
+	done := make(chan struct{})
+	go func() {
+		for id := range acceptedIds {
+			accepted = append(accepted, id)
+		}
+		close(done)
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- evaluateSharded(context.Background(), e, groups, pc, acceptedIds, make(chan *pb.MatchRejection))
+	}()
+
+	select {
+	case err := <-errc:
+		close(acceptedIds)
+		<-done
+		sort.Strings(accepted)
+		return accepted, err
+	case <-time.After(10 * time.Second):
+		t.Fatal("evaluateSharded did not return")
+		return nil, nil
+	}
+}
+
+func TestEvaluateShardedWithoutGroups(t *testing.T) {
+	e := &shardEvaluator{}
+	e.started.Add(1)
+
+	accepted, err := runSharded(t, e, nil,
+		fairnessMatch("a1", "a"),
+		fairnessMatch("b1", "b"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a1", "b1"}, accepted)
+	require.Equal(t, [][]string{{"a1", "b1"}}, e.calls)
+}
+
+func TestEvaluateShardedByGroup(t *testing.T) {
+	e := &shardEvaluator{}
+	// Every call waits for the others, so this only returns if the groups are
+	// evaluated in parallel.
+	e.started.Add(3)
+
+	accepted, err := runSharded(t, e, map[string]string{"ranked-eu": "eu", "casual-eu": "eu", "ranked-us": "us"},
+		fairnessMatch("re1", "ranked-eu"),
+		fairnessMatch("ce1", "casual-eu"),
+		fairnessMatch("ru1", "ranked-us"),
+		fairnessMatch("x1", "unlisted"),
+		fairnessMatch("re2", "ranked-eu"),
+		fairnessMatch("y1", "other"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ce1", "re1", "re2", "ru1", "x1", "y1"}, accepted)
+
+	sort.Slice(e.calls, func(i, j int) bool { return e.calls[i][0] < e.calls[j][0] })
+	require.Equal(t, [][]string{
+		{"ce1", "re1", "re2"},
+		{"ru1"},
+		{"x1", "y1"},
+	}, e.calls)
+}
+
+func TestEvaluateShardedError(t *testing.T) {
+	want := errors.New("evaluator down")
+	e := &shardEvaluator{err: want}
+	e.started.Add(2)
+
+	_, err := runSharded(t, e, map[string]string{"ranked-eu": "eu"},
+		fairnessMatch("re1", "ranked-eu"),
+		fairnessMatch("x1", "unlisted"),
+	)
+	require.Equal(t, want, err)
+}
@@ -52,7 +52,11 @@ var (
 // BindService creates the synchronizer service and binds it to the serving harness.
 func BindService(p *appmain.Params, b *appmain.Bindings) error {
 	store := statestore.New(p.Config())
-	service := newSynchronizerService(p.Config(), newEvaluator(p.Config()), store)
+	eval := newEvaluator(p.Config())
+	service := newSynchronizerService(p.Config(), eval, store)
+	service.decisions = b.DecisionLog()
+	b.AddDependency("redis", store.HealthCheck)
+	b.AddDependencyProbe("evaluator", eval.healthCheck)
 	b.AddHealthCheckFunc(store.HealthCheck)
 	b.AddHandleFunc(func(s *grpc.Server) {
 		ipb.RegisterSynchronizerServer(s, service)
@@ -24,6 +24,7 @@ import (
 	"go.opencensus.io/stats"
 
 	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/internal/appmain"
 	"open-match.dev/open-match/internal/appmain/contextcause"
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/ipb"
@@ -51,10 +52,14 @@ var (
 //   -> m2c ->
 // remember return channel m7c for match | fanInFanOut
 //   -> m3c ->
-// set mappings from matchIDs to ticketIDs| cacheMatchIDToTicketIDs
-//   -> m4c -> (buffered)
-// send to evaluator                     | wrapEvaluator
-//   -> m5c -> (buffered)
+// set mappings from matchIDs to matches | cacheMatchIDToMatch
+//   -> m4c ->
+// reject matches with too many tickets  | limitMatchSize
+//   ->                                   (rejections skip to wrapEvaluator)
+// share tickets between profiles        | fairness.filter
+//   -> (buffered)                        (rejections skip to wrapEvaluator)
+// send to evaluator, by profile group   | wrapEvaluator
+//   -> m5c -> (buffered)                 (rejections skip to fanInFanOut on rc)
 // add tickets to pending release            | addMatchesToPendingRelease
 //   -> m6c ->
 // fan out to origin synchronize call    | fanInFanOut
@@ -66,6 +71,11 @@ type synchronizerService struct {
 	store statestore.Service
 	eval  evaluator
 
+	fairness *fairness
+
+	// decisions records a summary of every evaluation window.
+	decisions *appmain.DecisionLog
+
 	synchronizeRegistration chan *registrationRequest
 
 	// startCycle is a buffered channel for containing a single value.  The value
@@ -79,6 +89,8 @@ func newSynchronizerService(cfg config.View, eval evaluator, store statestore.Se
 		store: store,
 		eval:  eval,
 
+		fairness: newFairness(),
+
 		synchronizeRegistration: make(chan *registrationRequest),
 		startCycle:              make(chan struct{}, 1),
 	}
@@ -95,7 +107,7 @@ func (s *synchronizerService) Synchronize(stream ipb.Synchronizer_SynchronizeSer
 	// 2. Receive matches and signals from cycle, send them to backend.
 
 	registration := s.register(stream.Context())
-	m6cBuffer := bufferStringChannel(registration.m7c)
+	m6cBuffer := bufferResponseChannel(registration.m7c)
 	defer func() {
 		for range m6cBuffer {
 		}
@@ -113,6 +125,16 @@ func (s *synchronizerService) Synchronize(stream ipb.Synchronizer_SynchronizeSer
 				registration.allM1cSent.Done()
 				return
 			}
+			if req.GetProposal() == nil {
+				logger.Warning("backend sent a synchronize request with no proposal, dropping it")
+				continue
+			}
+			if req.GetClaimOwner() != "" {
+				registration.claimOwners.Store(req.GetProposal().GetMatchId(), req.GetClaimOwner())
+			}
+			// Per profile settings apply by the profile of the backend call,
+			// not by whatever profile the mmf set.
+			req.Proposal.MatchProfile = req.GetProfile()
 			registration.m1c.send(mAndM7c{m: req.Proposal, m7c: registration.m7c})
 		}
 	}()
@@ -124,7 +146,7 @@ func (s *synchronizerService) Synchronize(stream ipb.Synchronizer_SynchronizeSer
 
 	for {
 		select {
-		case mIDs, ok := <-m6cBuffer:
+		case resps, ok := <-m6cBuffer:
 			if !ok {
 				// Prevent race: An error will result in this channel being
 				// closed as part of cleanup.  If it's especially fast, it may
@@ -132,8 +154,8 @@ func (s *synchronizerService) Synchronize(stream ipb.Synchronizer_SynchronizeSer
 				// potential error.
 				return registration.cycleCtx.Err()
 			}
-			for _, mID := range mIDs {
-				err = stream.Send(&ipb.SynchronizeResponse{MatchId: mID})
+			for _, resp := range resps {
+				err = stream.Send(resp)
 				if err != nil {
 					logger.WithFields(logrus.Fields{
 						"error": err.Error(),
@@ -178,9 +200,12 @@ type registrationRequest struct {
 type registration struct {
 	m1c        *cutoffSender
 	allM1cSent *sync.WaitGroup
-	m7c        chan string
+	m7c        chan *ipb.SynchronizeResponse
 	cancelMmfs chan struct{}
 	cycleCtx   context.Context
+	// claimOwners maps the match ids of the cycle to the claim owners of the
+	// backend calls which proposed them.
+	claimOwners *sync.Map
 }
 
 func (s synchronizerService) register(ctx context.Context) *registration {
@@ -217,6 +242,7 @@ func (s *synchronizerService) runCycle() {
 	m4c := make(chan *pb.Match)
 	m5c := make(chan string)
 	m6c := make(chan string)
+	rc := make(chan *pb.MatchRejection)
 
 	m1c := newCutoffSender(m2c)
 	// m7c, unlike other channels, is specific to a synchronize call.  There are
@@ -229,18 +255,29 @@ func (s *synchronizerService) runCycle() {
 	closedOnCycleEnd := make(chan struct{})
 
 	go func() {
-		fanInFanOut(m2c, m3c, m6c)
+		d := newCycleDecision(cst)
+		fanInFanOut(m2c, m3c, m6c, rc, d)
 		// Close response channels after all responses have been sent.
 		for _, r := range registrations {
 			close(r.m7c)
 		}
+
+		d.Registrations = len(registrations)
+		d.DurationMs = float64(time.Since(cst)) / float64(time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			d.Error = err.Error()
+		}
+		s.decisions.Record(evaluationsLog, d)
 	}()
 
-	matchTickets := &sync.Map{}
-	go s.cacheMatchIDToTicketIDs(matchTickets, m3c, m4c)
-	go s.wrapEvaluator(ctx, cancel, bufferMatchChannel(m4c), m5c)
+	matches := &sync.Map{}
+	claimOwners := &sync.Map{}
+	go s.cacheMatchIDToMatch(matches, m3c, m4c)
+	sized, src := limitMatchSize(config.GetSynchronizer(s.cfg), m4c)
+	fair, frc := s.fairness.filter(config.GetSynchronizer(s.cfg), sized)
+	go s.wrapEvaluator(ctx, cancel, bufferMatchChannel(fair), []<-chan *pb.MatchRejection{src, frc}, m5c, rc)
 	go func() {
-		s.addMatchesToPendingRelease(ctx, matchTickets, cancel, bufferStringChannel(m5c), m6c)
+		s.addMatchesToPendingRelease(ctx, matches, claimOwners, cancel, bufferStringChannel(m5c), m6c)
 		// Wait for pending release, but not all matches returned, the next cycle
 		// can start now.
 		close(closedOnCycleEnd)
@@ -248,7 +285,7 @@ func (s *synchronizerService) runCycle() {
 
 	/////////////////////////////////////// Run Registration Period
 	rst := time.Now()
-	closeRegistration := time.After(s.registrationInterval())
+	closeRegistration := time.After(config.GetSynchronizer(s.cfg).RegistrationInterval)
 Registration:
 	for {
 		select {
@@ -256,11 +293,12 @@ Registration:
 			allM1cSent.Add(1)
 			callingCtx = append(callingCtx, req.ctx)
 			r := &registration{
-				m1c:        m1c,
-				m7c:        make(chan string),
-				cancelMmfs: make(chan struct{}, 1),
-				cycleCtx:   ctx,
-				allM1cSent: &allM1cSent,
+				m1c:         m1c,
+				m7c:         make(chan *ipb.SynchronizeResponse),
+				cancelMmfs:  make(chan struct{}, 1),
+				cycleCtx:    ctx,
+				allM1cSent:  &allM1cSent,
+				claimOwners: claimOwners,
 			}
 			registrations = append(registrations, r)
 			req.resp <- r
@@ -280,10 +318,10 @@ Registration:
 	go func() {
 		allM1cSent.Wait()
 		m1c.cutoff()
-		stats.Record(ctx, registrationMMFDoneTime.M(float64((s.registrationInterval()-time.Since(rst))/time.Millisecond)))
+		stats.Record(ctx, registrationMMFDoneTime.M(float64((config.GetSynchronizer(s.cfg).RegistrationInterval-time.Since(rst))/time.Millisecond)))
 	}()
 
-	cancelProposalCollection := time.AfterFunc(s.proposalCollectionInterval(), func() {
+	cancelProposalCollection := time.AfterFunc(config.GetSynchronizer(s.cfg).ProposalCollectionInterval, func() {
 		m1c.cutoff()
 		for _, r := range registrations {
 			r.cancelMmfs <- struct{}{}
@@ -308,26 +346,28 @@ Registration:
 
 type mAndM7c struct {
 	m   *pb.Match
-	m7c chan string
+	m7c chan *ipb.SynchronizeResponse
 }
 
 // fanInFanOut routes evaluated matches back to it's source synchronize call.
 // Each incoming match is passed along with it's synchronize call's m7c channel.
 // This channel is remembered in a map, and the match is passed to be evaluated.
 // When a match returns from evaluation, it's ID is looked up in the map and the
-// match is returned on that channel.
-func fanInFanOut(m2c <-chan mAndM7c, m3c chan<- *pb.Match, m6c <-chan string) {
-	m7cMap := make(map[string]chan<- string)
+// match is returned on that channel.  Rejections from the evaluator on rc are
+// routed the same way.  What passes through is counted in d.
+func fanInFanOut(m2c <-chan mAndM7c, m3c chan<- *pb.Match, m6c <-chan string, rc <-chan *pb.MatchRejection, d *cycleDecision) {
+	m7cMap := make(map[string]chan<- *ipb.SynchronizeResponse)
 
 	defer func(m2c <-chan mAndM7c) {
 		for range m2c {
 		}
 	}(m2c)
 
-	for {
+	for m6c != nil || rc != nil {
 		select {
 		case m2, ok := <-m2c:
 			if ok {
+				d.proposed(m2.m)
 				m7cMap[m2.m.GetMatchId()] = m2.m7c
 				m3c <- m2.m
 			} else {
@@ -338,17 +378,37 @@ func fanInFanOut(m2c <-chan mAndM7c, m3c chan<- *pb.Match, m6c <-chan string) {
 
 		case m5, ok := <-m6c:
 			if !ok {
-				return
+				// No longer select on m6c
+				m6c = nil
+				continue
 			}
 
+			d.Accepted++
 			m7c, ok := m7cMap[m5]
 			if ok {
-				m7c <- m5
+				m7c <- &ipb.SynchronizeResponse{MatchId: m5}
 			} else {
 				logger.WithFields(logrus.Fields{
 					"matchId": m5,
 				}).Error("Match ID from evaluator does not match any id sent to it.")
 			}
+
+		case r, ok := <-rc:
+			if !ok {
+				// No longer select on rc
+				rc = nil
+				continue
+			}
+
+			d.rejected(r)
+			m7c, ok := m7cMap[r.GetMatchId()]
+			if ok {
+				m7c <- &ipb.SynchronizeResponse{Rejection: r}
+			} else {
+				logger.WithFields(logrus.Fields{
+					"matchId": r.GetMatchId(),
+				}).Error("Rejected match ID from evaluator does not match any id sent to it.")
+			}
 		}
 	}
 }
@@ -407,70 +467,87 @@ func (c *cutoffSender) cutoff() {
 ///////////////////////////////////////
 ///////////////////////////////////////
 
-// Calls the evaluator with the matches.
-func (s *synchronizerService) wrapEvaluator(ctx context.Context, cancel contextcause.CancelErrFunc, m4c <-chan []*pb.Match, m5c chan<- string) {
-	err := s.eval.evaluate(ctx, m4c, m5c)
+// Calls the evaluator with the matches, once per profile group.  Matches
+// rejected before evaluation, on frcs, are passed on with the evaluator's
+// rejections.
+func (s *synchronizerService) wrapEvaluator(ctx context.Context, cancel contextcause.CancelErrFunc, m4c <-chan []*pb.Match, frcs []<-chan *pb.MatchRejection, m5c chan<- string, rc chan<- *pb.MatchRejection) {
+	err := evaluateSharded(ctx, s.eval, config.GetSynchronizer(s.cfg).ProfileGroups, m4c, m5c, rc)
 	if err != nil {
 		logger.WithFields(logrus.Fields{
 			"error": err,
 		}).Error("error calling evaluator, canceling cycle")
 		cancel(fmt.Errorf("error calling evaluator: %w", err))
 	}
+	for _, frc := range frcs {
+		for r := range frc {
+			rc <- r
+		}
+	}
 	close(m5c)
+	close(rc)
 }
 
 ///////////////////////////////////////
 ///////////////////////////////////////
 
-func (s *synchronizerService) cacheMatchIDToTicketIDs(m *sync.Map, m3c <-chan *pb.Match, m4c chan<- *pb.Match) {
+func (s *synchronizerService) cacheMatchIDToMatch(m *sync.Map, m3c <-chan *pb.Match, m4c chan<- *pb.Match) {
 	for match := range m3c {
-		m.Store(match.GetMatchId(), getTicketIds(match.GetTickets()))
+		m.Store(match.GetMatchId(), match)
 		m4c <- match
 	}
 	close(m4c)
 }
 
-func getTicketIds(tickets []*pb.Ticket) []string {
-	tids := []string{}
-	for _, ticket := range tickets {
-		tids = append(tids, ticket.GetId())
-	}
-	return tids
-}
-
 ///////////////////////////////////////
 ///////////////////////////////////////
 
 // Calls statestore to add all of the tickets returned by the evaluator to the
-// pendingRelease list.  If it partially fails for whatever reason (not all tickets will
-// necessarily be in the same call), only the matches which can be safely
-// returned to the Synchronize calls are.
-func (s *synchronizerService) addMatchesToPendingRelease(ctx context.Context, m *sync.Map, cancel contextcause.CancelErrFunc, m5c <-chan []string, m6c chan<- string) {
+// pendingRelease list, claimed by the backend calls which proposed them.  If it
+// partially fails for whatever reason (not all tickets will necessarily be in
+// the same call), only the matches which can be safely returned to the
+// Synchronize calls are.
+func (s *synchronizerService) addMatchesToPendingRelease(ctx context.Context, m *sync.Map, claimOwners *sync.Map, cancel contextcause.CancelErrFunc, m5c <-chan []string, m6c chan<- string) {
 	totalMatches := 0
 	successfulMatches := 0
 	var lastErr error
 	for mIDs := range m5c {
-		ids := []string{}
+		byOwner := map[string][]*pb.Match{}
 		for _, mID := range mIDs {
-			tids, ok := m.Load(mID)
+			match, ok := m.Load(mID)
 			if ok {
-				ids = append(ids, tids.([]string)...)
+				owner, _ := claimOwners.Load(mID)
+				o, _ := owner.(string)
+				byOwner[o] = append(byOwner[o], match.(*pb.Match))
 			} else {
 				logger.Errorf("failed to get MatchId %s with its corresponding tickets from the cache", mID)
 			}
 		}
 
-		err := s.store.AddTicketsToPendingRelease(ctx, ids)
-
 		totalMatches += len(mIDs)
-		if err == nil {
-			successfulMatches += len(mIDs)
-		} else {
-			lastErr = err
+		reserved := map[string]bool{}
+		for owner, matches := range byOwner {
+			skipped, err := s.store.ClaimTickets(ctx, owner, matches)
+			if err == nil {
+				successfulMatches += len(matches) - len(skipped)
+			} else {
+				lastErr = err
+			}
+			for _, mID := range skipped {
+				reserved[mID] = true
+			}
+		}
+		if len(reserved) > 0 {
+			logger.WithFields(logrus.Fields{
+				"dropped": len(reserved),
+			}).Info("dropped matches with reserved tickets")
 		}
 
 		for _, mID := range mIDs {
-			m6c <- mID
+			// Matches with reserved tickets weren't claimed, so they can't
+			// be returned.
+			if !reserved[mID] {
+				m6c <- mID
+			}
 		}
 	}
 
@@ -491,41 +568,12 @@ func (s *synchronizerService) addMatchesToPendingRelease(ctx context.Context, m
 ///////////////////////////////////////
 ///////////////////////////////////////
 
-func (s *synchronizerService) registrationInterval() time.Duration {
-	const (
-		name            = "registrationInterval"
-		defaultInterval = time.Second
-	)
-
-	if !s.cfg.IsSet(name) {
-		return defaultInterval
-	}
-
-	return s.cfg.GetDuration(name)
-}
-
-func (s *synchronizerService) proposalCollectionInterval() time.Duration {
-	const (
-		name            = "proposalCollectionInterval"
-		defaultInterval = 10 * time.Second
-	)
-
-	if !s.cfg.IsSet(name) {
-		return defaultInterval
-	}
-
-	return s.cfg.GetDuration(name)
-}
-
-///////////////////////////////////////
-///////////////////////////////////////
-
 // bufferMatchChannel collects matches from the input, and sends
 // slice of matches on the output.  It never (for long) blocks
 // the input channel, always appending to the slice which will
 // next be used for output.  Used before external calls, so that
 // network won't back up internal processing.
-func bufferMatchChannel(in chan *pb.Match) chan []*pb.Match {
+func bufferMatchChannel(in <-chan *pb.Match) chan []*pb.Match {
 	out := make(chan []*pb.Match)
 	go func() {
 		var a []*pb.Match
@@ -595,3 +643,41 @@ func bufferStringChannel(in chan string) chan []string {
 	}()
 	return out
 }
+
+// bufferResponseChannel collects responses from the input, and sends
+// slice of responses on the output.  It never (for long) blocks
+// the input channel, always appending to the slice which will
+// next be used for output.  Used before external calls, so that
+// network won't back up internal processing.
+func bufferResponseChannel(in chan *ipb.SynchronizeResponse) chan []*ipb.SynchronizeResponse {
+	out := make(chan []*ipb.SynchronizeResponse)
+	go func() {
+		var a []*ipb.SynchronizeResponse
+
+	outerLoop:
+		for {
+			resp, ok := <-in
+			if !ok {
+				break outerLoop
+			}
+			a = []*ipb.SynchronizeResponse{resp}
+
+			for len(a) > 0 {
+				select {
+				case resp, ok := <-in:
+					if !ok {
+						break outerLoop
+					}
+					a = append(a, resp)
+				case out <- a:
+					a = nil
+				}
+			}
+		}
+		if len(a) > 0 {
+			out <- a
+		}
+		close(out)
+	}()
+	return out
+}
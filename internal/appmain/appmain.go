@@ -47,7 +47,12 @@ func RunApplication(serviceName string, bindService Bind) {
 	signal.Notify(c, syscall.SIGTERM, syscall.SIGINT)
 
 	readConfig := func() (config.View, error) {
-		return config.Read()
+		cfg, err := config.Read()
+		if err != nil {
+			return nil, err
+		}
+		setMaxProcs(cfg)
+		return cfg, nil
 	}
 
 	a, err := NewApplication(serviceName, bindService, readConfig, net.Listen)
@@ -85,9 +90,12 @@ func (p *Params) ServiceName() string {
 
 // Bindings allows applications to bind various functions to the running servers.
 type Bindings struct {
-	sp       *rpc.ServerParams
-	a        *App
-	firstErr error
+	sp        *rpc.ServerParams
+	a         *App
+	firstErr  error
+	deps      []dependency
+	probes    []dependency
+	decisions *DecisionLog
 }
 
 // AddHealthCheckFunc allows an application to check if it is healthy, and
@@ -96,6 +104,30 @@ func (b *Bindings) AddHealthCheckFunc(f func(context.Context) error) {
 	b.sp.AddHealthCheckFunc(f)
 }
 
+// AddDependency registers a service, such as redis, the application can't
+// serve traffic without.  At startup the dependencies are checked in the
+// order they were added, each retried with backoff until it succeeds, and
+// until then the application reports it is starting rather than unhealthy.
+// Dependencies are only checked at startup, use AddHealthCheckFunc to keep
+// checking them afterwards.  They are also reported by /dependencyz.
+func (b *Bindings) AddDependency(name string, check func(context.Context) error) {
+	b.deps = append(b.deps, dependency{name: name, check: check})
+	b.AddDependencyProbe(name, check)
+}
+
+// AddDependencyProbe registers a service the application calls, such as a
+// match function, to be checked on demand by /dependencyz.  Unlike
+// AddDependency, it doesn't gate startup.
+func (b *Bindings) AddDependencyProbe(name string, check func(context.Context) error) {
+	b.probes = append(b.probes, dependency{name: name, check: check})
+}
+
+// DecisionLog returns the log in which the application records its recent
+// pipeline decisions, served on /decisionz.
+func (b *Bindings) DecisionLog() *DecisionLog {
+	return b.decisions
+}
+
 // RegisterViews begins collecting data for the given views.
 func (b *Bindings) RegisterViews(v ...*view.View) {
 	if err := view.Register(v...); err != nil {
@@ -169,9 +201,14 @@ func NewApplication(serviceName string, bindService Bind, getCfg func() (config.
 		serviceName: serviceName,
 	}
 	b := &Bindings{
-		a:  a,
-		sp: sp,
+		a:         a,
+		sp:        sp,
+		decisions: newDecisionLog(config.GetRuntime(cfg).DecisionLogSize),
 	}
+	// Registered first, so readiness reports the service is starting
+	// before any of its health checks fail.
+	gate := &startupGate{}
+	sp.AddHealthCheckFunc(gate.healthCheck)
 
 	err = telemetry.Setup(p, b)
 	if err != nil {
@@ -192,6 +229,13 @@ func NewApplication(serviceName string, bindService Bind, getCfg func() (config.
 		return nil, b.firstErr
 	}
 
+	sp.ServeMux.Handle(dependencyzEndpoint, newDependencyz(serviceName, b.probes))
+	sp.ServeMux.Handle(decisionzEndpoint, newDecisionz(serviceName, b.decisions))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gate.start(ctx, cfg, b.deps)
+	b.AddCloser(cancel)
+
 	s := &rpc.Server{}
 	err = s.Start(sp)
 	if err != nil {
@@ -0,0 +1,170 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const decisionzEndpoint = "/decisionz"
+
+// DecisionLog keeps the most recent decisions of a service's pipeline, such
+// as a summary of each FetchMatches call, in named logs of bounded size.  They
+// are served on /decisionz, so operators can see what the service just did
+// without going through its logs.  A nil DecisionLog records nothing.
+type DecisionLog struct {
+	size int
+
+	mu   sync.Mutex
+	logs map[string]*decisionRing
+}
+
+type decisionEntry struct {
+	Time     time.Time   `json:"time"`
+	Decision interface{} `json:"decision"`
+}
+
+// decisionRing holds up to size entries, overwriting the oldest at next once
+// it is full.
+type decisionRing struct {
+	entries []decisionEntry
+	next    int
+}
+
+func newDecisionLog(size int) *DecisionLog {
+	return &DecisionLog{size: size, logs: map[string]*decisionRing{}}
+}
+
+// Record adds decision to the named log, dropping the log's oldest entry if
+// it is full.  The decision is encoded as JSON when /decisionz is read, so it
+// must not be modified after being recorded.
+func (l *DecisionLog) Record(name string, decision interface{}) {
+	if l == nil || l.size <= 0 {
+		return
+	}
+	e := decisionEntry{Time: time.Now().UTC(), Decision: decision}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.logs[name]
+	if !ok {
+		r = &decisionRing{}
+		l.logs[name] = r
+	}
+	if len(r.entries) < l.size {
+		r.entries = append(r.entries, e)
+		return
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % l.size
+}
+
+// recent returns up to limit of the newest entries of each log, newest first.
+// An empty name returns every log, and a limit of zero every entry.
+func (l *DecisionLog) recent(name string, limit int) map[string][]decisionEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := map[string][]decisionEntry{}
+	for n, r := range l.logs {
+		if name != "" && n != name {
+			continue
+		}
+		count := len(r.entries)
+		if limit > 0 && limit < count {
+			count = limit
+		}
+		entries := make([]decisionEntry, count)
+		for i := range entries {
+			// The newest entry is the one before next, wrapping around.
+			entries[i] = r.entries[(r.next-1-i+2*len(r.entries))%len(r.entries)]
+		}
+		out[n] = entries
+	}
+	return out
+}
+
+// names returns the names of the logs which have entries, sorted.
+func (l *DecisionLog) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.logs))
+	for n := range l.logs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type decisionReport struct {
+	Service  string                     `json:"service"`
+	Instance string                     `json:"instance"`
+	Time     time.Time                  `json:"time"`
+	Logs     map[string][]decisionEntry `json:"logs"`
+}
+
+// decisionz serves the /decisionz endpoint, which reports the entries of
+// every decision log, newest first.  ?log=<name> narrows the report to one
+// log, and ?limit=<n> to the newest n entries of each.
+type decisionz struct {
+	service  string
+	instance string
+	log      *DecisionLog
+}
+
+func newDecisionz(service string, log *DecisionLog) *decisionz {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &decisionz{service: service, instance: instance, log: log}
+}
+
+func (d *decisionz) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	limit := 0
+	if l := q.Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			http.Error(w, fmt.Sprintf("limit must be a positive number, got %q", l), http.StatusBadRequest)
+			return
+		}
+	}
+	name := q.Get("log")
+	logs := d.log.recent(name, limit)
+	if name != "" && len(logs) == 0 {
+		http.Error(w, fmt.Sprintf("no decisions recorded in log %q, logs are %q", name, d.log.names()), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	r := &decisionReport{
+		Service:  d.service,
+		Instance: d.instance,
+		Time:     time.Now().UTC(),
+		Logs:     logs,
+	}
+	if err := json.NewEncoder(w).Encode(r); err != nil {
+		logger.WithError(err).Debug("Failed to write decisionz report.")
+	}
+}
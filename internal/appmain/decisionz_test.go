@@ -0,0 +1,94 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionLogKeepsNewest(t *testing.T) {
+	l := newDecisionLog(3)
+	for i := 0; i < 5; i++ {
+		l.Record("fetchMatches", i)
+	}
+	l.Record("evaluations", "a")
+
+	logs := l.recent("", 0)
+	require.Len(t, logs, 2)
+	require.Equal(t, []interface{}{4, 3, 2}, decisions(logs["fetchMatches"]))
+	require.Equal(t, []interface{}{"a"}, decisions(logs["evaluations"]))
+
+	logs = l.recent("fetchMatches", 2)
+	require.Len(t, logs, 1)
+	require.Equal(t, []interface{}{4, 3}, decisions(logs["fetchMatches"]))
+	require.Equal(t, []string{"evaluations", "fetchMatches"}, l.names())
+}
+
+func TestDecisionLogDisabled(t *testing.T) {
+	l := newDecisionLog(0)
+	l.Record("fetchMatches", 1)
+	require.Empty(t, l.recent("", 0))
+
+	var nilLog *DecisionLog
+	nilLog.Record("fetchMatches", 1)
+}
+
+func TestDecisionz(t *testing.T) {
+	l := newDecisionLog(10)
+	l.Record("fetchMatches", map[string]string{"profile": "casual"})
+	l.Record("fetchMatches", map[string]string{"profile": "ranked"})
+	l.Record("evaluations", map[string]int{"proposals": 2})
+	d := newDecisionz("minimatch", l)
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, decisionzEndpoint+"?log=fetchMatches&limit=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var r struct {
+		Service  string
+		Instance string
+		Logs     map[string][]struct {
+			Decision map[string]string
+		}
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	require.Equal(t, "minimatch", r.Service)
+	require.NotEmpty(t, r.Instance)
+	require.Len(t, r.Logs, 1)
+	require.Len(t, r.Logs["fetchMatches"], 1)
+	require.Equal(t, "ranked", r.Logs["fetchMatches"][0].Decision["profile"])
+
+	rec = httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, decisionzEndpoint+"?log=assignments", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, decisionzEndpoint+"?limit=0", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func decisions(entries []decisionEntry) []interface{} {
+	out := make([]interface{}, len(entries))
+	for i, e := range entries {
+		out[i] = e.Decision
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/telemetry"
+)
+
+// Each attempt to reach a dependency at startup is canceled after this long.
+const dependencyCheckTimeout = 5 * time.Second
+
+type dependency struct {
+	name  string
+	check func(context.Context) error
+}
+
+// startupGate reports the service as not ready until each of its
+// dependencies has been reached once.
+type startupGate struct {
+	mu      sync.Mutex
+	pending []dependency
+}
+
+// healthCheck fails readiness with a telemetry.StartingError while
+// dependencies are pending.
+func (g *startupGate) healthCheck(context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.pending) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(g.pending))
+	for _, d := range g.pending {
+		names = append(names, d.name)
+	}
+	return &telemetry.StartingError{Dependencies: names}
+}
+
+// start checks the dependencies one at a time, in the order they were added,
+// retrying each with backoff until it succeeds or ctx is canceled.
+func (g *startupGate) start(ctx context.Context, cfg config.View, deps []dependency) {
+	g.mu.Lock()
+	g.pending = deps
+	g.mu.Unlock()
+
+	go func() {
+		for _, d := range deps {
+			if !waitForDependency(ctx, cfg, d) {
+				return
+			}
+
+			g.mu.Lock()
+			g.pending = g.pending[1:]
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// waitForDependency returns whether the dependency was reached before ctx
+// was canceled.
+func waitForDependency(ctx context.Context, cfg config.View, d dependency) bool {
+	start := time.Now()
+	attempts := 0
+	err := backoff.RetryNotify(func() error {
+		attempts++
+		checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+		defer cancel()
+		return d.check(checkCtx)
+	}, backoff.WithContext(newDependencyBackoff(cfg), ctx), func(err error, next time.Duration) {
+		entry := logger.WithFields(logrus.Fields{
+			"dependency": d.name,
+			"attempts":   attempts,
+			"error":      err.Error(),
+		})
+		if attempts == 1 {
+			entry.Info("Waiting for dependency to become available.")
+		} else {
+			entry.Debug("Dependency is still unavailable, retrying.")
+		}
+	})
+	if err != nil {
+		return false
+	}
+
+	fields := logrus.Fields{
+		"dependency": d.name,
+		"attempts":   attempts,
+		"elapsed":    time.Since(start).String(),
+	}
+	if attempts > 1 {
+		logger.WithFields(fields).Info("Dependency is available after waiting for it.")
+	} else {
+		logger.WithFields(fields).Debug("Dependency is available.")
+	}
+	return true
+}
+
+// newDependencyBackoff returns the configured exponential backoff, without
+// its maxElapsedTime: services wait for their dependencies indefinitely,
+// rather than exit and be restarted.
+func newDependencyBackoff(cfg config.View) backoff.BackOff {
+	b := config.GetBackoff(cfg)
+	b.MaxElapsedTime = 0
+	return b.NewExponentialBackOff()
+}
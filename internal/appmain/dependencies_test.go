@@ -0,0 +1,76 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/telemetry"
+)
+
+func TestStartupGate(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("backoff.initialInterval", time.Millisecond)
+	cfg.Set("backoff.maxInterval", 10*time.Millisecond)
+
+	var redisUp, syncUp int32
+	var syncChecked int32
+	check := func(up *int32) func(context.Context) error {
+		return func(context.Context) error {
+			if atomic.LoadInt32(up) == 0 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+	}
+	deps := []dependency{
+		{name: "redis", check: check(&redisUp)},
+		{name: "synchronizer", check: func(ctx context.Context) error {
+			atomic.StoreInt32(&syncChecked, 1)
+			return check(&syncUp)(ctx)
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := &startupGate{}
+	g.start(ctx, cfg, deps)
+
+	var startingErr *telemetry.StartingError
+	require.True(t, errors.As(g.healthCheck(ctx), &startingErr))
+	require.Equal(t, []string{"redis", "synchronizer"}, startingErr.Dependencies)
+
+	// Dependencies are waited for in order.
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&syncChecked))
+
+	atomic.StoreInt32(&redisUp, 1)
+	require.Eventually(t, func() bool {
+		err := g.healthCheck(ctx)
+		return errors.As(err, &startingErr) && len(startingErr.Dependencies) == 1
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(t, []string{"synchronizer"}, startingErr.Dependencies)
+
+	atomic.StoreInt32(&syncUp, 1)
+	require.Eventually(t, func() bool {
+		return g.healthCheck(ctx) == nil
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	dependencyzEndpoint = "/dependencyz"
+	// Streaming with ?watch=<interval> refuses intervals shorter than this,
+	// so a typo doesn't hammer the dependencies.
+	minDependencyzWatchInterval = 100 * time.Millisecond
+)
+
+type dependencyStatus struct {
+	Name      string  `json:"name"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type dependencyReport struct {
+	Service      string             `json:"service"`
+	Instance     string             `json:"instance"`
+	Time         time.Time          `json:"time"`
+	Dependencies []dependencyStatus `json:"dependencies"`
+}
+
+// dependencyz serves the /dependencyz endpoint, which checks each of the
+// service's dependencies and reports whether it was reached and how long
+// that took.  With ?watch=<interval>, for example ?watch=1s, a report is
+// streamed every interval as newline delimited JSON until the client
+// disconnects.
+type dependencyz struct {
+	service  string
+	instance string
+	probes   []dependency
+}
+
+func newDependencyz(service string, probes []dependency) *dependencyz {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &dependencyz{service: service, instance: instance, probes: probes}
+}
+
+func (d *dependencyz) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	watch := req.URL.Query().Get("watch")
+	if watch == "" {
+		if err := enc.Encode(d.report(req.Context())); err != nil {
+			logger.WithError(err).Debug("Failed to write dependencyz report.")
+		}
+		return
+	}
+
+	interval, err := time.ParseDuration(watch)
+	if err != nil || interval < minDependencyzWatchInterval {
+		http.Error(w, fmt.Sprintf("watch must be a duration of at least %s, got %q", minDependencyzWatchInterval, watch), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := enc.Encode(d.report(req.Context())); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report checks every dependency concurrently, so one which hangs doesn't
+// delay reporting the others for longer than dependencyCheckTimeout.
+func (d *dependencyz) report(ctx context.Context) *dependencyReport {
+	r := &dependencyReport{
+		Service:      d.service,
+		Instance:     d.instance,
+		Time:         time.Now().UTC(),
+		Dependencies: make([]dependencyStatus, len(d.probes)),
+	}
+
+	wg := sync.WaitGroup{}
+	for i, probe := range d.probes {
+		i, probe := i, probe
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := probe.check(checkCtx)
+			s := dependencyStatus{
+				Name:      probe.name,
+				Healthy:   err == nil,
+				LatencyMs: float64(time.Since(start)) / float64(time.Millisecond),
+			}
+			if err != nil {
+				s.Error = err.Error()
+			}
+			r.Dependencies[i] = s
+		}()
+	}
+	wg.Wait()
+	return r
+}
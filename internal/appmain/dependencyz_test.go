@@ -0,0 +1,95 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyz(t *testing.T) {
+	d := newDependencyz("backend", []dependency{
+		{name: "redis", check: func(context.Context) error { return nil }},
+		{name: "synchronizer", check: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}},
+		{name: "matchFunction grpc://mmf:50502", check: func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			require.True(t, ok, "checks must be bounded")
+			return nil
+		}},
+	})
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, dependencyzEndpoint, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var r dependencyReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	require.Equal(t, "backend", r.Service)
+	require.NotEmpty(t, r.Instance)
+	require.Len(t, r.Dependencies, 3)
+	require.Equal(t, "redis", r.Dependencies[0].Name)
+	require.True(t, r.Dependencies[0].Healthy)
+	require.Empty(t, r.Dependencies[0].Error)
+	require.Equal(t, "synchronizer", r.Dependencies[1].Name)
+	require.False(t, r.Dependencies[1].Healthy)
+	require.Equal(t, "connection refused", r.Dependencies[1].Error)
+	require.True(t, r.Dependencies[2].Healthy)
+}
+
+func TestDependencyzWatch(t *testing.T) {
+	d := newDependencyz("query", []dependency{
+		{name: "redis", check: func(context.Context) error { return nil }},
+	})
+	ts := httptest.NewServer(d)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+dependencyzEndpoint+"?watch=100ms", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	// Reports keep coming until the client goes away.
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 3; i++ {
+		require.True(t, scanner.Scan(), "stream ended early: %v", scanner.Err())
+		var r dependencyReport
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		require.Equal(t, "query", r.Service)
+		require.True(t, r.Dependencies[0].Healthy)
+	}
+}
+
+func TestDependencyzWatchInvalid(t *testing.T) {
+	d := newDependencyz("query", nil)
+	for _, watch := range []string{"soon", "1ms"} {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, dependencyzEndpoint+"?watch="+watch, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, watch)
+	}
+}
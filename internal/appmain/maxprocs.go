@@ -0,0 +1,94 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/internal/config"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// setMaxProcs sizes GOMAXPROCS to the container's CPU limit.  By default Go
+// uses every CPU on the node, and a service limited to a fraction of them is
+// then throttled by the kernel, and sizes its worker pools far too large.
+// A GOMAXPROCS environment variable takes precedence.
+func setMaxProcs(cfg config.View) {
+	if _, ok := os.LookupEnv("GOMAXPROCS"); ok {
+		return
+	}
+
+	n, source := config.GetRuntime(cfg).MaxProcs, "config"
+	if n <= 0 {
+		limit, ok := cgroupCPULimit(cgroupRoot)
+		if !ok {
+			return
+		}
+		n, source = int(limit), "cpu limit"
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	previous := runtime.GOMAXPROCS(n)
+	logger.WithFields(logrus.Fields{
+		"maxprocs": n,
+		"previous": previous,
+		"source":   source,
+	}).Info("Set GOMAXPROCS.")
+}
+
+// cgroupCPULimit returns the number of CPUs the container may use, as set by
+// its cgroup CPU quota, and false if it has no limit.
+func cgroupCPULimit(root string) (float64, bool) {
+	// cgroup v2: "<quota> <period>", or "max <period>" without a limit.
+	if b, err := ioutil.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(b))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return cpuQuota(fields[0], fields[1])
+	}
+
+	// cgroup v1: the quota is -1 without a limit.
+	quota, err := ioutil.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := ioutil.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+	return cpuQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func cpuQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
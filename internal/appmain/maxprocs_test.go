@@ -0,0 +1,80 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appmain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupCPULimit(t *testing.T) {
+	tests := []struct {
+		description string
+		files       map[string]string
+		wantLimit   float64
+		wantOk      bool
+	}{
+		{
+			description: "cgroup v2 limit",
+			files:       map[string]string{"cpu.max": "250000 100000\n"},
+			wantLimit:   2.5,
+			wantOk:      true,
+		},
+		{
+			description: "cgroup v2 without limit",
+			files:       map[string]string{"cpu.max": "max 100000\n"},
+		},
+		{
+			description: "cgroup v1 limit",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "400000\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+			wantLimit: 4,
+			wantOk:    true,
+		},
+		{
+			description: "cgroup v1 without limit",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "-1\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+		},
+		{
+			description: "no cgroup",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "cgroup")
+			require.NoError(t, err)
+			defer os.RemoveAll(root)
+			for name, content := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+			}
+
+			limit, ok := cgroupCPULimit(root)
+			require.Equal(t, test.wantOk, ok)
+			require.Equal(t, test.wantLimit, limit)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "runtime"
+
+// Concurrency returns the number of goroutines a pool of workers should use:
+// the value of name if it is set to a positive number, and otherwise perCPU
+// for each CPU the Go runtime is using.  GOMAXPROCS follows the container's
+// CPU limit, so pools are sized for the CPU the service actually gets, not
+// for the node it runs on.
+func Concurrency(v View, name string, perCPU int) int {
+	if n := v.GetInt(name); n > 0 {
+		return n
+	}
+	return perCPU * runtime.GOMAXPROCS(0)
+}
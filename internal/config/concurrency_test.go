@@ -0,0 +1,34 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrency(t *testing.T) {
+	v := viper.New()
+	require.Equal(t, 2*runtime.GOMAXPROCS(0), Concurrency(v, "concurrency", 2))
+
+	v.Set("concurrency", 0)
+	require.Equal(t, 2*runtime.GOMAXPROCS(0), Concurrency(v, "concurrency", 2))
+
+	v.Set("concurrency", 5)
+	require.Equal(t, 5, Concurrency(v, "concurrency", 2))
+}
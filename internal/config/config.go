@@ -48,6 +48,14 @@ func Read() (*viper.Viper, error) {
 		cfg.SetDefault(k, v)
 	}
 
+	// The id node must differ between the pods of a deployment, which share
+	// the config map, so it may be set per pod, eg from a StatefulSet's pod
+	// index.
+	err = cfg.BindEnv(KeyIDNode, "OPEN_MATCH_ID_NODE")
+	if err != nil {
+		return nil, fmt.Errorf("fatal error binding %s to the environment, desc: %s", KeyIDNode, err.Error())
+	}
+
 	cfg.SetConfigType("yaml")
 	cfg.AddConfigPath(".")
 	// The config path needs to be the same as the volumeMountPath defined via helm
@@ -58,6 +66,10 @@ func Read() (*viper.Viper, error) {
 		return nil, fmt.Errorf("fatal error reading override config file, desc: %s", err.Error())
 	}
 
+	if err = Validate(cfg); err != nil {
+		return nil, err
+	}
+
 	// Look for updates to the config; in Kubernetes, this is implemented using
 	// a ConfigMap that is written to the matchmaker_config_override.yaml file, which is
 	// what the Open Match components using Viper monitor for changes.
@@ -67,6 +79,10 @@ func Read() (*viper.Viper, error) {
 	// Write a log when the configuration changes.
 	cfg.OnConfigChange(func(event fsnotify.Event) {
 		log.Printf("Server configuration changed, operation: %v, filename: %s", event.Op, event.Name)
+		// Running services can't refuse a change, only report it.
+		if err := Validate(cfg); err != nil {
+			log.Printf("Changed server configuration is invalid: %v", err)
+		}
 	})
 	return cfg, nil
 }
@@ -0,0 +1,874 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"open-match.dev/open-match/pkg/idgen"
+)
+
+// Config keys of the settings read through the typed accessors below.  Feature
+// code should use the accessors rather than the keys, which are exported for
+// tests and tools which write configs.
+const (
+	KeyRegistrationInterval        = "registrationInterval"
+	KeyProposalCollectionInterval  = "proposalCollectionInterval"
+	KeyFairnessPolicy              = "fairnessPolicy"
+	KeyFairnessWeights             = "fairnessWeights"
+	KeyProfileGroups               = "profileGroups"
+	KeyMaxTicketsPerMatch          = "maxTicketsPerMatch"
+	KeyProfileMaxTicketsPerMatch   = "profileMaxTicketsPerMatch"
+	KeyTicketIntake                = "ticketIntake.enabled"
+	KeyTicketIntakeBatchSize       = "ticketIntake.batchSize"
+	KeyTicketIntakeMaxDelay        = "ticketIntake.maxDelay"
+	KeyTicketHeartbeatTimeout      = "ticketHeartbeatTimeout"
+	KeyTicketDeadlineInterval      = "ticketDeadlineInterval"
+	KeyQueryPageSize               = "queryPageSize"
+	KeyQueryCursorTTL              = "queryCursorTTL"
+	KeyQueryMaxSnapshots           = "queryMaxSnapshots"
+	KeyQueryClientQPS              = "queryClientQPS"
+	KeyQueryClientTicketsPerSecond = "queryClientTicketsPerSecond"
+	KeyQueryAuditClients           = "queryAuditClients"
+	KeyTicketTimelineClients       = "ticketTimelineClients"
+	KeyPendingTicketsClients       = "pendingTicketsClients"
+	KeyProfilePauseClients         = "profilePauseClients"
+	KeyQuerySource                 = "querySource"
+	KeyQueryFilterPlugins          = "queryFilterPlugins"
+	KeyWarmMatchFunctions          = "warmMatchFunctions"
+	KeyWarmMatchFunctionsInterval  = "warmMatchFunctionsInterval"
+	KeyPausedProfiles              = "pausedProfiles"
+	KeyMaxReservationTTL           = "maxReservationTtl"
+	KeyPendingReleaseTimeout       = "pendingReleaseTimeout"
+	KeyAssignedDeleteTimeout       = "assignedDeleteTimeout"
+	KeyBackfillLockTimeout         = "backfillLockTimeout"
+	KeyServerCapacityTimeout       = "serverCapacityTimeout"
+	KeyTicketTimelineRetention     = "ticketTimelineRetention"
+	KeyTicketCountsRetention       = "ticketCountsRetention"
+	KeyMatchRosterRetention        = "matchRosterRetention"
+	KeyClaimLeaseTimeout           = "claimLeaseTimeout"
+	KeyBackfillCleanupConcurrency  = "backfillCleanupConcurrency"
+	KeyCompressionCodec            = "redis.compression.codec"
+	KeyCompressionThreshold        = "redis.compression.thresholdBytes"
+	KeyTicketChangeStream          = "redis.ticketChanges.enabled"
+	KeyTicketChangeStreamMaxLen    = "redis.ticketChanges.maxLen"
+	KeySlowCommandThreshold        = "redis.slowCommandThreshold"
+	KeyMaxProcs                    = "maxProcs"
+	KeyWorkerConcurrency           = "workerConcurrency"
+	KeyDecisionLogSize             = "decisionLogSize"
+	KeyBackoffInitialInterval      = "backoff.initialInterval"
+	KeyBackoffRandFactor           = "backoff.randFactor"
+	KeyBackoffMultiplier           = "backoff.multiplier"
+	KeyBackoffMaxInterval          = "backoff.maxInterval"
+	KeyBackoffMaxElapsedTime       = "backoff.maxElapsedTime"
+	KeyIDScheme                    = "idScheme"
+	KeyIDRegion                    = "idRegion"
+	KeyIDNode                      = "idNode"
+	KeyRPCCompression              = "rpcCompression"
+	KeyPubSubDriver                = "pubsub.driver"
+	KeyPubSubNATSURL               = "pubsub.nats.url"
+	KeyPartitionClients            = "partitionClients"
+	KeyBlackoutWindows             = "blackoutWindows"
+)
+
+// Policies for sharing tickets between the match profiles of a synchronizer
+// cycle.
+const (
+	// FairnessNone leaves every collision to the evaluator.
+	FairnessNone = "none"
+	// FairnessRoundRobin takes turns between the profiles.
+	FairnessRoundRobin = "roundRobin"
+	// FairnessWeighted takes turns between the profiles in proportion to
+	// their weights.
+	FairnessWeighted = "weighted"
+)
+
+// Sources of the tickets the query service serves.
+const (
+	// QuerySourceStateStore reads the active tickets from the state store on
+	// every cache update.
+	QuerySourceStateStore = "statestore"
+	// QuerySourceChangeStream follows the ticket change stream, and only
+	// reads the state store to start following it.
+	QuerySourceChangeStream = "changeStream"
+)
+
+// Targets of blackout windows.
+const (
+	// BlackoutPool closes a pool, by name, in the query service and in
+	// FetchMatches.
+	BlackoutPool = "pool"
+	// BlackoutProfile closes a match profile, by name, in FetchMatches.
+	BlackoutProfile = "profile"
+)
+
+// Drivers of the messaging the core services notify each other with.
+const (
+	// PubSubNone turns notifications off, services poll the state store.
+	PubSubNone = "none"
+	// PubSubRedis publishes on the state store's redis.
+	PubSubRedis = "redis"
+	// PubSubNATS publishes on a NATS server, so that notifications don't add
+	// load to the state store.
+	PubSubNATS = "nats"
+)
+
+const (
+	// Bounds of the number of tickets returned in a streamed response for
+	// QueryTickets.  Configured page sizes outside of them are clamped.
+	minQueryPageSize = 10
+	maxQueryPageSize = 10000
+)
+
+// Synchronizer holds the settings of the synchronizer.
+type Synchronizer struct {
+	// RegistrationInterval is the time between the first FetchMatches call of
+	// a cycle, and when further calls wait for the next cycle.
+	RegistrationInterval time.Duration
+	// ProposalCollectionInterval is the time match functions have to return
+	// their proposals, before the evaluator input is closed.
+	ProposalCollectionInterval time.Duration
+	// FairnessPolicy is how tickets proposed by matches of several profiles
+	// are shared between the profiles: FairnessNone, FairnessRoundRobin or
+	// FairnessWeighted.
+	FairnessPolicy string
+	// FairnessWeights are the weights of the profiles by name, for
+	// FairnessWeighted.  Profiles not listed have a weight of 1.
+	FairnessWeights map[string]float64
+	// ProfileGroups are the groups of the profiles by name.  The proposals of
+	// each group are evaluated by a separate evaluator call, in parallel, so
+	// the operator must ensure that groups never share tickets.  Profiles not
+	// listed share a group.  Empty evaluates all proposals together.
+	ProfileGroups map[string]string
+	// MaxTicketsPerMatch is the most tickets a proposal may have before it is
+	// rejected, rather than evaluated.  Zero is unlimited.
+	MaxTicketsPerMatch int
+	// ProfileMaxTicketsPerMatch overrides MaxTicketsPerMatch for the profiles
+	// by name.
+	ProfileMaxTicketsPerMatch map[string]int
+}
+
+// MaxTickets returns the most tickets a match of the profile may have, or
+// zero if unlimited.
+func (s Synchronizer) MaxTickets(profile string) int {
+	if n, ok := s.ProfileMaxTicketsPerMatch[profile]; ok {
+		return n
+	}
+	return s.MaxTicketsPerMatch
+}
+
+// GetSynchronizer returns the synchronizer settings of v.  Invalid fairness
+// weights are ignored, Validate reports them.
+func GetSynchronizer(v View) Synchronizer {
+	policy := v.GetString(KeyFairnessPolicy)
+	if policy == "" {
+		policy = FairnessNone
+	}
+	weights, _ := parseWeights(v.GetStringSlice(KeyFairnessWeights))
+	groups, _ := parseGroups(v.GetStringSlice(KeyProfileGroups))
+	maxTickets, _ := parseMaxTickets(v.GetStringSlice(KeyProfileMaxTicketsPerMatch))
+
+	return Synchronizer{
+		RegistrationInterval:       getDuration(v, KeyRegistrationInterval, time.Second),
+		ProposalCollectionInterval: getDuration(v, KeyProposalCollectionInterval, 10*time.Second),
+		FairnessPolicy:             policy,
+		FairnessWeights:            weights,
+		ProfileGroups:              groups,
+		MaxTicketsPerMatch:         v.GetInt(KeyMaxTicketsPerMatch),
+		ProfileMaxTicketsPerMatch:  maxTickets,
+	}
+}
+
+// parseWeights parses "name=weight" entries.
+func parseWeights(entries []string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(entries))
+	var err error
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			err = fmt.Errorf("%q is not name=weight", entry)
+			continue
+		}
+		w, parseErr := strconv.ParseFloat(entry[i+1:], 64)
+		if parseErr != nil || w <= 0 {
+			err = fmt.Errorf("%q does not have a positive weight", entry)
+			continue
+		}
+		weights[entry[:i]] = w
+	}
+	return weights, err
+}
+
+// parseGroups parses "name=group" entries.
+func parseGroups(entries []string) (map[string]string, error) {
+	groups := make(map[string]string, len(entries))
+	var err error
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 || i == len(entry)-1 {
+			err = fmt.Errorf("%q is not name=group", entry)
+			continue
+		}
+		groups[entry[:i]] = entry[i+1:]
+	}
+	return groups, err
+}
+
+// parseMaxTickets parses "name=max" entries.
+func parseMaxTickets(entries []string) (map[string]int, error) {
+	maxTickets := make(map[string]int, len(entries))
+	var err error
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			err = fmt.Errorf("%q is not name=max", entry)
+			continue
+		}
+		n, parseErr := strconv.Atoi(entry[i+1:])
+		if parseErr != nil || n <= 0 {
+			err = fmt.Errorf("%q does not have a positive maximum", entry)
+			continue
+		}
+		maxTickets[entry[:i]] = n
+	}
+	return maxTickets, err
+}
+
+// Frontend holds the settings of the frontend.
+type Frontend struct {
+	// TicketIntake queues the tickets of CreateTicket calls, and writes them
+	// to the state store in batches, rather than one at a time.
+	TicketIntake bool
+	// TicketIntakeBatchSize is the most tickets written in one batch.
+	TicketIntakeBatchSize int
+	// TicketIntakeMaxDelay is the longest a queued ticket waits for its batch
+	// to fill before the batch is written anyway.
+	TicketIntakeMaxDelay time.Duration
+	// TicketHeartbeatTimeout is how long a ticket may go without a heartbeat,
+	// from HeartbeatTicket or an open WatchAssignments stream, before it is
+	// deleted.  Zero turns off deleting tickets without heartbeats, otherwise
+	// it is at least a second.
+	TicketHeartbeatTimeout time.Duration
+	// TicketDeadlineInterval is the time between removals from matchmaking
+	// of the tickets whose matchmaking deadline passed.
+	TicketDeadlineInterval time.Duration
+}
+
+// GetFrontend returns the frontend settings of v.
+func GetFrontend(v View) Frontend {
+	return Frontend{
+		TicketIntake:           v.GetBool(KeyTicketIntake),
+		TicketIntakeBatchSize:  getInt(v, KeyTicketIntakeBatchSize, 100),
+		TicketIntakeMaxDelay:   getDuration(v, KeyTicketIntakeMaxDelay, 10*time.Millisecond),
+		TicketHeartbeatTimeout: v.GetDuration(KeyTicketHeartbeatTimeout),
+		TicketDeadlineInterval: getDuration(v, KeyTicketDeadlineInterval, time.Second),
+	}
+}
+
+// Query holds the settings of the query service.
+type Query struct {
+	// PageSize is the number of tickets or backfills sent per streamed
+	// response, clamped to [10, 10000].
+	PageSize int
+	// CursorTTL is how long a paginated query snapshot is kept after it was
+	// taken.
+	CursorTTL time.Duration
+	// MaxSnapshots is the number of paginated query snapshots a query service
+	// replica keeps at once.  Queries starting a new snapshot beyond it fail
+	// with ResourceExhausted.
+	MaxSnapshots int
+	// ClientQPS and ClientTicketsPerSecond are the sustained rates of queries,
+	// and of returned tickets, allowed for a single client.  Zero means no
+	// limit.
+	ClientQPS              float64
+	ClientTicketsPerSecond float64
+	// AuditClients lists the clients allowed to include pending and assigned
+	// tickets in QueryTickets results.  "*" allows any client.
+	AuditClients []string
+	// Source is where the active tickets are read from,
+	// QuerySourceStateStore or QuerySourceChangeStream.
+	Source string
+	// FilterPlugins are the paths of the Go plugins registering custom
+	// filter types, loaded when the query service starts.
+	FilterPlugins []string
+}
+
+// GetQuery returns the query service settings of v.
+func GetQuery(v View) Query {
+	pageSize := getInt(v, KeyQueryPageSize, 1000)
+	if pageSize < minQueryPageSize {
+		pageSize = minQueryPageSize
+	}
+	if pageSize > maxQueryPageSize {
+		pageSize = maxQueryPageSize
+	}
+
+	source := v.GetString(KeyQuerySource)
+	if source == "" {
+		source = QuerySourceStateStore
+	}
+
+	return Query{
+		PageSize:               pageSize,
+		CursorTTL:              getDuration(v, KeyQueryCursorTTL, time.Minute),
+		MaxSnapshots:           getInt(v, KeyQueryMaxSnapshots, 1000),
+		ClientQPS:              v.GetFloat64(KeyQueryClientQPS),
+		ClientTicketsPerSecond: v.GetFloat64(KeyQueryClientTicketsPerSecond),
+		AuditClients:           v.GetStringSlice(KeyQueryAuditClients),
+		Source:                 source,
+		FilterPlugins:          v.GetStringSlice(KeyQueryFilterPlugins),
+	}
+}
+
+// Backend holds the settings of the backend.
+type Backend struct {
+	// WarmMatchFunctions lists the match functions dialed ahead of the first
+	// FetchMatches call, as "grpc://host:port" or "http://host:port".
+	WarmMatchFunctions []string
+	// WarmMatchFunctionsInterval is the time between health checks of the warm
+	// match functions, and re-reads of their list.
+	WarmMatchFunctionsInterval time.Duration
+	// PausedProfiles are the names of the match profiles FetchMatches returns
+	// no matches for, in addition to those paused with PauseProfiles.
+	PausedProfiles []string
+	// MaxReservationTTL is the longest ttl ReserveTickets accepts, so that a
+	// caller can't hold tickets out of matchmaking indefinitely.
+	MaxReservationTTL time.Duration
+	// TimelineClients lists the clients allowed to read ticket timelines with
+	// GetTicketTimeline.  "*" allows any client.
+	TimelineClients []string
+	// PendingTicketsClients lists the clients allowed to list pending tickets
+	// with ListPendingTickets.  "*" allows any client.
+	PendingTicketsClients []string
+	// ProfilePauseClients lists the clients allowed to pause and resume match
+	// profiles.  "*" allows any client.
+	ProfilePauseClients []string
+}
+
+// GetBackend returns the backend settings of v.
+func GetBackend(v View) Backend {
+	return Backend{
+		WarmMatchFunctions:         v.GetStringSlice(KeyWarmMatchFunctions),
+		WarmMatchFunctionsInterval: getDuration(v, KeyWarmMatchFunctionsInterval, 10*time.Second),
+		PausedProfiles:             v.GetStringSlice(KeyPausedProfiles),
+		MaxReservationTTL:          getDuration(v, KeyMaxReservationTTL, 10*time.Minute),
+		TimelineClients:            v.GetStringSlice(KeyTicketTimelineClients),
+		PendingTicketsClients:      v.GetStringSlice(KeyPendingTicketsClients),
+		ProfilePauseClients:        v.GetStringSlice(KeyProfilePauseClients),
+	}
+}
+
+// StateStore holds the settings of the redis state store shared by the core
+// services.
+type StateStore struct {
+	// PendingReleaseTimeout is the time after which tickets returned from
+	// FetchMatches become active again.
+	PendingReleaseTimeout time.Duration
+	// AssignedDeleteTimeout is the time after which assigned tickets are
+	// deleted.
+	AssignedDeleteTimeout time.Duration
+	// BackfillLockTimeout is the expiry of the backfill locks.
+	BackfillLockTimeout time.Duration
+	// ServerCapacityTimeout is the time after which unrefreshed game server
+	// capacity expires.
+	ServerCapacityTimeout time.Duration
+	// TicketTimelineRetention is how long ticket lifecycle events are kept.
+	// Zero turns off recording them.
+	TicketTimelineRetention time.Duration
+	// TicketCountsRetention is how long the per minute counts of ticket
+	// lifecycle events are kept.  Zero turns off counting them.
+	TicketCountsRetention time.Duration
+	// MatchRosterRetention is how long the tickets of the matches returned by
+	// FetchMatches are kept, for AssignMatch.  Zero turns off recording them.
+	MatchRosterRetention time.Duration
+	// ClaimLeaseTimeout is the time after which the tickets claimed for a
+	// FetchMatches call are released, if the backend serving it stops
+	// renewing its lease before returning them.
+	ClaimLeaseTimeout time.Duration
+	// BackfillCleanupConcurrency is the number of goroutines deleting expired
+	// backfills.
+	BackfillCleanupConcurrency int
+	// CompressionCodec is the codec tickets are stored with, "none" or
+	// "snappy".
+	CompressionCodec string
+	// CompressionThreshold is the marshaled size in bytes below which tickets
+	// are stored uncompressed.  Small tickets don't compress well enough to be
+	// worth the CPU.
+	CompressionThreshold int
+	// TicketChangeStream records the changes to the active tickets on a redis
+	// stream, for query services following it.
+	TicketChangeStream bool
+	// TicketChangeStreamMaxLen is the approximate number of changes the
+	// stream keeps.  Followers which fall further behind start over.
+	TicketChangeStreamMaxLen int
+	// SlowCommandThreshold is the duration above which redis commands, and
+	// pipelines, are logged as slow.  Zero turns off the log.
+	SlowCommandThreshold time.Duration
+}
+
+// GetStateStore returns the state store settings of v.
+func GetStateStore(v View) StateStore {
+	codec := v.GetString(KeyCompressionCodec)
+	if codec == "" {
+		codec = "none"
+	}
+
+	return StateStore{
+		PendingReleaseTimeout:      getDuration(v, KeyPendingReleaseTimeout, time.Minute),
+		AssignedDeleteTimeout:      getDuration(v, KeyAssignedDeleteTimeout, 10*time.Minute),
+		BackfillLockTimeout:        getDuration(v, KeyBackfillLockTimeout, time.Minute),
+		ServerCapacityTimeout:      getDuration(v, KeyServerCapacityTimeout, time.Minute),
+		TicketTimelineRetention:    v.GetDuration(KeyTicketTimelineRetention),
+		TicketCountsRetention:      v.GetDuration(KeyTicketCountsRetention),
+		MatchRosterRetention:       getDuration(v, KeyMatchRosterRetention, 10*time.Minute),
+		ClaimLeaseTimeout:          getDuration(v, KeyClaimLeaseTimeout, 15*time.Second),
+		BackfillCleanupConcurrency: Concurrency(v, KeyBackfillCleanupConcurrency, 2),
+		CompressionCodec:           codec,
+		CompressionThreshold:       getInt(v, KeyCompressionThreshold, 1024),
+		TicketChangeStream:         v.GetBool(KeyTicketChangeStream),
+		TicketChangeStreamMaxLen:   getInt(v, KeyTicketChangeStreamMaxLen, 100000),
+		SlowCommandThreshold:       getDuration(v, KeySlowCommandThreshold, 100*time.Millisecond),
+	}
+}
+
+// Runtime holds the settings of the Go runtime, and the background workers
+// every service runs.
+type Runtime struct {
+	// MaxProcs is the number of CPUs the Go runtime uses.  Zero derives it
+	// from the container's CPU limit.
+	MaxProcs int
+	// WorkerConcurrency is the number of goroutines running background jobs.
+	WorkerConcurrency int
+	// DecisionLogSize is the number of recent pipeline decisions, such as
+	// FetchMatches calls, each service keeps for /decisionz.  Zero keeps none.
+	DecisionLogSize int
+}
+
+// GetRuntime returns the runtime settings of v.
+func GetRuntime(v View) Runtime {
+	return Runtime{
+		MaxProcs:          v.GetInt(KeyMaxProcs),
+		WorkerConcurrency: Concurrency(v, KeyWorkerConcurrency, 2),
+		DecisionLogSize:   getInt(v, KeyDecisionLogSize, 100),
+	}
+}
+
+// Backoff holds the exponential backoff applied to retried operations.  Unset
+// values keep the backoff library's defaults.
+type Backoff struct {
+	InitialInterval time.Duration
+	RandFactor      float64
+	Multiplier      float64
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// GetBackoff returns the backoff settings of v.
+func GetBackoff(v View) Backoff {
+	return Backoff{
+		InitialInterval: getDuration(v, KeyBackoffInitialInterval, backoff.DefaultInitialInterval),
+		RandFactor:      getFloat64(v, KeyBackoffRandFactor, backoff.DefaultRandomizationFactor),
+		Multiplier:      getFloat64(v, KeyBackoffMultiplier, backoff.DefaultMultiplier),
+		MaxInterval:     getDuration(v, KeyBackoffMaxInterval, backoff.DefaultMaxInterval),
+		MaxElapsedTime:  getDuration(v, KeyBackoffMaxElapsedTime, backoff.DefaultMaxElapsedTime),
+	}
+}
+
+// NewExponentialBackOff returns a new backoff with the settings of b.
+func (b Backoff) NewExponentialBackOff() *backoff.ExponentialBackOff {
+	e := backoff.NewExponentialBackOff()
+	e.InitialInterval = b.InitialInterval
+	e.RandomizationFactor = b.RandFactor
+	e.Multiplier = b.Multiplier
+	e.MaxInterval = b.MaxInterval
+	e.MaxElapsedTime = b.MaxElapsedTime
+	e.Reset()
+	return e
+}
+
+// IDs holds the scheme of the ids given to tickets and backfills.
+type IDs struct {
+	// Scheme is an idgen scheme, eg idgen.XID, idgen.UUIDv7 or idgen.Snowflake.
+	Scheme string
+	// Region is the idgen.Options region.
+	Region string
+	// Node is the idgen.Options node.  It is derived from the hostname
+	// unless idNode is set, which deployments running many replicas creating
+	// snowflake ids should do, as hashed nodes may collide.
+	Node int64
+}
+
+// GetIDs returns the id settings of v.
+func GetIDs(v View) IDs {
+	scheme := v.GetString(KeyIDScheme)
+	if scheme == "" {
+		scheme = idgen.XID
+	}
+	node := idgen.NodeFromHostname()
+	if v.IsSet(KeyIDNode) {
+		node = v.GetInt64(KeyIDNode)
+	}
+	return IDs{
+		Scheme: scheme,
+		Region: v.GetString(KeyIDRegion),
+		Node:   node,
+	}
+}
+
+// NewGenerator returns a new id generator with the settings of i.
+func (i IDs) NewGenerator() (idgen.Generator, error) {
+	return idgen.New(i.Scheme, idgen.Options{
+		Region: i.Region,
+		Node:   i.Node,
+	})
+}
+
+// RPC holds the settings of the gRPC clients the core services dial each other
+// and the match functions and evaluator with.
+type RPC struct {
+	// Compression is the compressor of the calls, "none", "gzip" or "snappy".
+	// Match functions and evaluators must register the compressor to accept
+	// compressed calls, see open-match.dev/open-match/pkg/encoding/snappy.
+	Compression string
+}
+
+// GetRPC returns the gRPC client settings of v.
+func GetRPC(v View) RPC {
+	compression := v.GetString(KeyRPCCompression)
+	if compression == "" {
+		compression = "none"
+	}
+	return RPC{
+		Compression: compression,
+	}
+}
+
+// PubSub holds the settings of the messaging used for assignment
+// notifications, ticket events and cache invalidation.
+type PubSub struct {
+	// Driver is PubSubNone, PubSubRedis or PubSubNATS.
+	Driver string
+	// NATSURL is the NATS server url, eg "nats://nats:4222", for PubSubNATS.
+	NATSURL string
+}
+
+// GetPubSub returns the messaging settings of v.
+func GetPubSub(v View) PubSub {
+	driver := v.GetString(KeyPubSubDriver)
+	if driver == "" {
+		driver = PubSubNone
+	}
+	return PubSub{
+		Driver:  driver,
+		NATSURL: v.GetString(KeyPubSubNATSURL),
+	}
+}
+
+// Partitions holds the settings restricting which clients may fetch matches
+// from and query the tickets of which partitions.
+type Partitions struct {
+	// Clients lists the partitions each client may use, by client identity,
+	// from "client=partition" entries.  The client "*" is any client.  Every
+	// client may use every partition when it is empty, and the default
+	// partition always.
+	Clients map[string][]string
+}
+
+// GetPartitions returns the partition settings of v.
+func GetPartitions(v View) Partitions {
+	clients, _ := parsePartitionClients(v.GetStringSlice(KeyPartitionClients))
+	return Partitions{Clients: clients}
+}
+
+// Allowed returns true if the client may use the partition.
+func (p Partitions) Allowed(client, partition string) bool {
+	if partition == "" || len(p.Clients) == 0 {
+		return true
+	}
+	for _, c := range []string{client, "*"} {
+		for _, allowed := range p.Clients[c] {
+			if allowed == partition {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parsePartitionClients parses "client=partition" entries.  A client may have
+// several entries.
+func parsePartitionClients(entries []string) (map[string][]string, error) {
+	clients := make(map[string][]string, len(entries))
+	var err error
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 || i == len(entry)-1 {
+			err = fmt.Errorf("%q is not client=partition", entry)
+			continue
+		}
+		clients[entry[:i]] = append(clients[entry[:i]], entry[i+1:])
+	}
+	return clients, err
+}
+
+// Blackouts holds the schedules during which pools and match profiles are
+// closed to matchmaking, eg for maintenance or game mode rotations.
+type Blackouts struct {
+	Windows []BlackoutWindow
+}
+
+// BlackoutWindow closes the pools or profiles of a name, from
+// "<target>:<name>=<start>/<end>" entries.  Start and end are either RFC 3339
+// times, for a single window, or "15:04" UTC times of day, for a window
+// repeating every day, which wraps around midnight when end is before start.
+type BlackoutWindow struct {
+	// Target is BlackoutPool or BlackoutProfile.
+	Target string
+	Name   string
+	// Start and End bound a single window.
+	Start, End time.Time
+	// Daily is set for windows repeating every day, from DailyStart to
+	// DailyEnd after midnight UTC.
+	Daily                bool
+	DailyStart, DailyEnd time.Duration
+}
+
+// GetBlackouts returns the blackout settings of v.
+func GetBlackouts(v View) Blackouts {
+	windows, _ := parseBlackoutWindows(v.GetStringSlice(KeyBlackoutWindows))
+	return Blackouts{Windows: windows}
+}
+
+// Active returns the end of the blackout the target of the name is in at now,
+// the latest if windows overlap, and whether there is one.
+func (b Blackouts) Active(target, name string, now time.Time) (time.Time, bool) {
+	var until time.Time
+	for _, w := range b.Windows {
+		if w.Target != target || w.Name != name {
+			continue
+		}
+		if end, ok := w.activeUntil(now); ok && end.After(until) {
+			until = end
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// activeUntil returns the end of the window, if now is within it.
+func (w BlackoutWindow) activeUntil(now time.Time) (time.Time, bool) {
+	if !w.Daily {
+		return w.End, !now.Before(w.Start) && now.Before(w.End)
+	}
+
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	offset := now.Sub(midnight)
+	switch {
+	case w.DailyStart < w.DailyEnd:
+		return midnight.Add(w.DailyEnd), offset >= w.DailyStart && offset < w.DailyEnd
+	case offset >= w.DailyStart:
+		return midnight.AddDate(0, 0, 1).Add(w.DailyEnd), true
+	default:
+		return midnight.Add(w.DailyEnd), offset < w.DailyEnd
+	}
+}
+
+// parseBlackoutWindows parses "<target>:<name>=<start>/<end>" entries.
+func parseBlackoutWindows(entries []string) ([]BlackoutWindow, error) {
+	windows := make([]BlackoutWindow, 0, len(entries))
+	var err error
+	for _, entry := range entries {
+		w, entryErr := parseBlackoutWindow(entry)
+		if entryErr != nil {
+			err = entryErr
+			continue
+		}
+		windows = append(windows, w)
+	}
+	return windows, err
+}
+
+func parseBlackoutWindow(entry string) (BlackoutWindow, error) {
+	var w BlackoutWindow
+	i := strings.LastIndex(entry, "=")
+	if i <= 0 {
+		return w, fmt.Errorf("%q is not <target>:<name>=<start>/<end>", entry)
+	}
+	target := strings.SplitN(entry[:i], ":", 2)
+	window := strings.Split(entry[i+1:], "/")
+	if len(target) != 2 || target[1] == "" || len(window) != 2 {
+		return w, fmt.Errorf("%q is not <target>:<name>=<start>/<end>", entry)
+	}
+	if target[0] != BlackoutPool && target[0] != BlackoutProfile {
+		return w, fmt.Errorf("%q has target %q, expected %q or %q", entry, target[0], BlackoutPool, BlackoutProfile)
+	}
+	w.Target, w.Name = target[0], target[1]
+
+	start, startErr := time.Parse(time.RFC3339, window[0])
+	end, endErr := time.Parse(time.RFC3339, window[1])
+	if startErr == nil && endErr == nil {
+		if !start.Before(end) {
+			return w, fmt.Errorf("%q ends before it starts", entry)
+		}
+		w.Start, w.End = start, end
+		return w, nil
+	}
+
+	start, startErr = time.Parse("15:04", window[0])
+	end, endErr = time.Parse("15:04", window[1])
+	if startErr != nil || endErr != nil {
+		return w, fmt.Errorf("%q times must both be RFC 3339 times, or both be 15:04 times of day", entry)
+	}
+	if start.Equal(end) {
+		return w, fmt.Errorf("%q is empty", entry)
+	}
+	w.Daily = true
+	w.DailyStart = time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	w.DailyEnd = time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+	return w, nil
+}
+
+// Validate checks the settings of v which the typed accessors read, so that
+// a mistyped value fails the service at startup rather than when the feature
+// using it first runs.
+func Validate(v View) error {
+	var problems []string
+	check := func(ok bool, key string, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	synchronizer := GetSynchronizer(v)
+	check(synchronizer.RegistrationInterval > 0, KeyRegistrationInterval, "must be positive, got %s", synchronizer.RegistrationInterval)
+	check(synchronizer.ProposalCollectionInterval > 0, KeyProposalCollectionInterval, "must be positive, got %s", synchronizer.ProposalCollectionInterval)
+	switch synchronizer.FairnessPolicy {
+	case FairnessNone, FairnessRoundRobin, FairnessWeighted:
+	default:
+		check(false, KeyFairnessPolicy, "must be %q, %q or %q, got %q", FairnessNone, FairnessRoundRobin, FairnessWeighted, synchronizer.FairnessPolicy)
+	}
+	_, err := parseWeights(v.GetStringSlice(KeyFairnessWeights))
+	check(err == nil, KeyFairnessWeights, "%v", err)
+	_, err = parseGroups(v.GetStringSlice(KeyProfileGroups))
+	check(err == nil, KeyProfileGroups, "%v", err)
+	check(synchronizer.MaxTicketsPerMatch >= 0, KeyMaxTicketsPerMatch, "must not be negative, got %d", synchronizer.MaxTicketsPerMatch)
+	_, err = parseMaxTickets(v.GetStringSlice(KeyProfileMaxTicketsPerMatch))
+	check(err == nil, KeyProfileMaxTicketsPerMatch, "%v", err)
+
+	frontend := GetFrontend(v)
+	check(frontend.TicketIntakeBatchSize > 0, KeyTicketIntakeBatchSize, "must be positive, got %d", frontend.TicketIntakeBatchSize)
+	check(frontend.TicketIntakeMaxDelay > 0, KeyTicketIntakeMaxDelay, "must be positive, got %s", frontend.TicketIntakeMaxDelay)
+	// Streams send heartbeats every third of the timeout, which must be
+	// long enough for clients to keep up with.
+	check(frontend.TicketHeartbeatTimeout == 0 || frontend.TicketHeartbeatTimeout >= time.Second, KeyTicketHeartbeatTimeout, "must be zero or at least 1s, got %s", frontend.TicketHeartbeatTimeout)
+	check(frontend.TicketDeadlineInterval > 0, KeyTicketDeadlineInterval, "must be positive, got %s", frontend.TicketDeadlineInterval)
+
+	query := GetQuery(v)
+	check(query.CursorTTL > 0, KeyQueryCursorTTL, "must be positive, got %s", query.CursorTTL)
+	check(query.MaxSnapshots > 0, KeyQueryMaxSnapshots, "must be positive, got %d", query.MaxSnapshots)
+	check(query.ClientQPS >= 0, KeyQueryClientQPS, "must not be negative, got %v", query.ClientQPS)
+	check(query.ClientTicketsPerSecond >= 0, KeyQueryClientTicketsPerSecond, "must not be negative, got %v", query.ClientTicketsPerSecond)
+	switch query.Source {
+	case QuerySourceStateStore:
+	case QuerySourceChangeStream:
+		check(v.GetBool(KeyTicketChangeStream), KeyQuerySource, "%q requires %s", query.Source, KeyTicketChangeStream)
+	default:
+		check(false, KeyQuerySource, "must be %q or %q, got %q", QuerySourceStateStore, QuerySourceChangeStream, query.Source)
+	}
+
+	backend := GetBackend(v)
+	check(backend.WarmMatchFunctionsInterval > 0, KeyWarmMatchFunctionsInterval, "must be positive, got %s", backend.WarmMatchFunctionsInterval)
+	check(backend.MaxReservationTTL > 0, KeyMaxReservationTTL, "must be positive, got %s", backend.MaxReservationTTL)
+
+	store := GetStateStore(v)
+	check(store.PendingReleaseTimeout > 0, KeyPendingReleaseTimeout, "must be positive, got %s", store.PendingReleaseTimeout)
+	check(store.AssignedDeleteTimeout > 0, KeyAssignedDeleteTimeout, "must be positive, got %s", store.AssignedDeleteTimeout)
+	check(store.BackfillLockTimeout > 0, KeyBackfillLockTimeout, "must be positive, got %s", store.BackfillLockTimeout)
+	check(store.ServerCapacityTimeout > 0, KeyServerCapacityTimeout, "must be positive, got %s", store.ServerCapacityTimeout)
+	check(store.TicketTimelineRetention >= 0, KeyTicketTimelineRetention, "must not be negative, got %s", store.TicketTimelineRetention)
+	check(store.TicketCountsRetention >= 0, KeyTicketCountsRetention, "must not be negative, got %s", store.TicketCountsRetention)
+	check(store.MatchRosterRetention >= 0, KeyMatchRosterRetention, "must not be negative, got %s", store.MatchRosterRetention)
+	check(store.ClaimLeaseTimeout > 0, KeyClaimLeaseTimeout, "must be positive, got %s", store.ClaimLeaseTimeout)
+	check(store.CompressionCodec == "none" || store.CompressionCodec == "snappy", KeyCompressionCodec, "must be \"none\" or \"snappy\", got %q", store.CompressionCodec)
+	check(store.CompressionThreshold >= 0, KeyCompressionThreshold, "must not be negative, got %d", store.CompressionThreshold)
+	check(store.TicketChangeStreamMaxLen > 0, KeyTicketChangeStreamMaxLen, "must be positive, got %d", store.TicketChangeStreamMaxLen)
+	check(store.SlowCommandThreshold >= 0, KeySlowCommandThreshold, "must not be negative, got %s", store.SlowCommandThreshold)
+
+	rt := GetRuntime(v)
+	check(rt.MaxProcs >= 0, KeyMaxProcs, "must not be negative, got %d", rt.MaxProcs)
+	check(rt.DecisionLogSize >= 0, KeyDecisionLogSize, "must not be negative, got %d", rt.DecisionLogSize)
+
+	b := GetBackoff(v)
+	check(b.InitialInterval > 0, KeyBackoffInitialInterval, "must be positive, got %s", b.InitialInterval)
+	check(b.RandFactor >= 0 && b.RandFactor <= 1, KeyBackoffRandFactor, "must be between 0 and 1, got %v", b.RandFactor)
+	check(b.Multiplier >= 1, KeyBackoffMultiplier, "must be at least 1, got %v", b.Multiplier)
+	check(b.MaxInterval >= b.InitialInterval, KeyBackoffMaxInterval, "must be at least %s, got %s", KeyBackoffInitialInterval, b.MaxInterval)
+	check(b.MaxElapsedTime >= 0, KeyBackoffMaxElapsedTime, "must not be negative, got %s", b.MaxElapsedTime)
+
+	ids := GetIDs(v)
+	check(ids.Node >= 0, KeyIDNode, "must not be negative, got %d", ids.Node)
+	_, err = ids.NewGenerator()
+	check(err == nil, KeyIDScheme, "%v", err)
+
+	switch c := GetRPC(v).Compression; c {
+	case "none", "gzip", "snappy":
+	default:
+		check(false, KeyRPCCompression, "must be \"none\", \"gzip\" or \"snappy\", got %q", c)
+	}
+
+	ps := GetPubSub(v)
+	switch ps.Driver {
+	case PubSubNone, PubSubRedis:
+	case PubSubNATS:
+		check(ps.NATSURL != "", KeyPubSubNATSURL, "is required by %s %q", KeyPubSubDriver, ps.Driver)
+	default:
+		check(false, KeyPubSubDriver, "must be %q, %q or %q, got %q", PubSubNone, PubSubRedis, PubSubNATS, ps.Driver)
+	}
+
+	_, err = parsePartitionClients(v.GetStringSlice(KeyPartitionClients))
+	check(err == nil, KeyPartitionClients, "%v", err)
+	_, err = parseBlackoutWindows(v.GetStringSlice(KeyBlackoutWindows))
+	check(err == nil, KeyBlackoutWindows, "%v", err)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func getDuration(v View, key string, def time.Duration) time.Duration {
+	if !v.IsSet(key) {
+		return def
+	}
+	return v.GetDuration(key)
+}
+
+func getInt(v View, key string, def int) int {
+	if !v.IsSet(key) {
+		return def
+	}
+	return v.GetInt(key)
+}
+
+func getFloat64(v View, key string, def float64) float64 {
+	if !v.IsSet(key) {
+		return def
+	}
+	return v.GetFloat64(key)
+}
@@ -0,0 +1,272 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/idgen"
+)
+
+func TestGetQueryPageSize(t *testing.T) {
+	testCases := []struct {
+		name      string
+		configure func(Mutable)
+		expected  int
+	}{
+		{
+			"notSet",
+			func(cfg Mutable) {},
+			1000,
+		},
+		{
+			"set",
+			func(cfg Mutable) {
+				cfg.Set(KeyQueryPageSize, "2156")
+			},
+			2156,
+		},
+		{
+			"low",
+			func(cfg Mutable) {
+				cfg.Set(KeyQueryPageSize, "9")
+			},
+			10,
+		},
+		{
+			"high",
+			func(cfg Mutable) {
+				cfg.Set(KeyQueryPageSize, "10001")
+			},
+			10000,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := viper.New()
+			tt.configure(cfg)
+			require.Equal(t, tt.expected, GetQuery(cfg).PageSize)
+		})
+	}
+}
+
+func TestSettingsDefaults(t *testing.T) {
+	cfg := viper.New()
+
+	require.Equal(t, Synchronizer{
+		RegistrationInterval:       time.Second,
+		ProposalCollectionInterval: 10 * time.Second,
+		FairnessPolicy:             FairnessNone,
+		FairnessWeights:            map[string]float64{},
+		ProfileGroups:              map[string]string{},
+		ProfileMaxTicketsPerMatch:  map[string]int{},
+	}, GetSynchronizer(cfg))
+
+	require.Equal(t, Frontend{
+		TicketIntakeBatchSize:  100,
+		TicketIntakeMaxDelay:   10 * time.Millisecond,
+		TicketDeadlineInterval: time.Second,
+	}, GetFrontend(cfg))
+
+	store := GetStateStore(cfg)
+	require.Equal(t, time.Minute, store.PendingReleaseTimeout)
+	require.Equal(t, 10*time.Minute, store.AssignedDeleteTimeout)
+	require.Equal(t, time.Duration(0), store.TicketTimelineRetention)
+	require.Equal(t, time.Duration(0), store.TicketCountsRetention)
+	require.Equal(t, 10*time.Minute, store.MatchRosterRetention)
+	require.Equal(t, 15*time.Second, store.ClaimLeaseTimeout)
+	require.Equal(t, "none", store.CompressionCodec)
+	require.Equal(t, 1024, store.CompressionThreshold)
+	require.False(t, store.TicketChangeStream)
+	require.Equal(t, 100000, store.TicketChangeStreamMaxLen)
+	require.Equal(t, 100*time.Millisecond, store.SlowCommandThreshold)
+
+	require.Equal(t, QuerySourceStateStore, GetQuery(cfg).Source)
+	require.Empty(t, GetQuery(cfg).FilterPlugins)
+	require.Equal(t, time.Minute, GetQuery(cfg).CursorTTL)
+	require.Equal(t, 1000, GetQuery(cfg).MaxSnapshots)
+
+	require.Equal(t, IDs{Scheme: "xid", Node: idgen.NodeFromHostname()}, GetIDs(cfg))
+	require.Equal(t, RPC{Compression: "none"}, GetRPC(cfg))
+	require.Equal(t, PubSub{Driver: PubSubNone}, GetPubSub(cfg))
+	require.True(t, GetPartitions(cfg).Allowed("10.0.0.1", "studio-a"))
+	require.Empty(t, GetBlackouts(cfg).Windows)
+	require.Equal(t, 10*time.Minute, GetBackend(cfg).MaxReservationTTL)
+	require.Equal(t, 100, GetRuntime(cfg).DecisionLogSize)
+
+	require.NoError(t, Validate(cfg))
+}
+
+func TestSettingsOverrides(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(KeyWarmMatchFunctions, []string{"grpc://om-function:50502"})
+	cfg.Set(KeyWarmMatchFunctionsInterval, "3s")
+	cfg.Set(KeyPausedProfiles, []string{"ranked"})
+	cfg.Set(KeyMaxReservationTTL, "1h")
+	cfg.Set(KeyTicketTimelineClients, []string{"10.0.0.1"})
+	cfg.Set(KeyPendingTicketsClients, []string{"10.0.0.2"})
+	cfg.Set(KeyProfilePauseClients, []string{"10.0.0.3"})
+	cfg.Set(KeyBackoffInitialInterval, "100ms")
+	cfg.Set(KeyBackoffMaxElapsedTime, "3000ms")
+	cfg.Set(KeyFairnessPolicy, FairnessWeighted)
+	cfg.Set(KeyFairnessWeights, []string{"casual=1", "ranked=2.5", "mode=a=3"})
+	cfg.Set(KeyProfileGroups, []string{"ranked-eu=eu", "casual-eu=eu", "ranked-us=us"})
+	cfg.Set(KeyMaxTicketsPerMatch, 10)
+	cfg.Set(KeyProfileMaxTicketsPerMatch, []string{"raid=40", "duel=2"})
+	cfg.Set(KeyPartitionClients, []string{"director-a=studio-a", "director-a=studio-b", "*=shared"})
+	cfg.Set(KeyIDScheme, idgen.Snowflake)
+	cfg.Set(KeyIDNode, 7)
+
+	require.Equal(t, Backend{
+		WarmMatchFunctions:         []string{"grpc://om-function:50502"},
+		WarmMatchFunctionsInterval: 3 * time.Second,
+		PausedProfiles:             []string{"ranked"},
+		MaxReservationTTL:          time.Hour,
+		TimelineClients:            []string{"10.0.0.1"},
+		PendingTicketsClients:      []string{"10.0.0.2"},
+		ProfilePauseClients:        []string{"10.0.0.3"},
+	}, GetBackend(cfg))
+
+	synchronizer := GetSynchronizer(cfg)
+	require.Equal(t, FairnessWeighted, synchronizer.FairnessPolicy)
+	require.Equal(t, map[string]float64{"casual": 1, "ranked": 2.5, "mode=a": 3}, synchronizer.FairnessWeights)
+	require.Equal(t, map[string]string{"ranked-eu": "eu", "casual-eu": "eu", "ranked-us": "us"}, synchronizer.ProfileGroups)
+	require.Equal(t, 10, synchronizer.MaxTicketsPerMatch)
+	require.Equal(t, map[string]int{"raid": 40, "duel": 2}, synchronizer.ProfileMaxTicketsPerMatch)
+
+	partitions := GetPartitions(cfg)
+	require.Equal(t, map[string][]string{"director-a": {"studio-a", "studio-b"}, "*": {"shared"}}, partitions.Clients)
+	require.True(t, partitions.Allowed("director-a", "studio-b"))
+	require.True(t, partitions.Allowed("director-b", "shared"))
+	require.True(t, partitions.Allowed("director-b", ""))
+	require.False(t, partitions.Allowed("director-b", "studio-a"))
+
+	require.Equal(t, IDs{Scheme: idgen.Snowflake, Node: 7}, GetIDs(cfg))
+
+	b := GetBackoff(cfg).NewExponentialBackOff()
+	require.Equal(t, 100*time.Millisecond, b.InitialInterval)
+	require.Equal(t, 3*time.Second, b.MaxElapsedTime)
+}
+
+func TestBlackouts(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(KeyBlackoutWindows, []string{
+		"pool:ranked=2026-10-20T02:00:00Z/2026-10-20T04:00:00Z",
+		"profile:casual=22:00/06:00",
+		"profile:casual=05:00/07:00",
+		"pool:arena=12:00/14:30",
+	})
+	require.NoError(t, Validate(cfg))
+	blackouts := GetBlackouts(cfg)
+	require.Len(t, blackouts.Windows, 4)
+
+	at := func(s string) time.Time {
+		now, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return now
+	}
+	tests := []struct {
+		target string
+		name   string
+		now    string
+		until  string
+	}{
+		{BlackoutPool, "ranked", "2026-10-20T01:59:59Z", ""},
+		{BlackoutPool, "ranked", "2026-10-20T02:00:00Z", "2026-10-20T04:00:00Z"},
+		{BlackoutPool, "ranked", "2026-10-20T04:00:00Z", ""},
+		// Windows only close their target.
+		{BlackoutProfile, "ranked", "2026-10-20T03:00:00Z", ""},
+		{BlackoutPool, "arena", "2026-10-21T13:00:00Z", "2026-10-21T14:30:00Z"},
+		{BlackoutPool, "arena", "2026-10-21T15:00:00+02:00", "2026-10-21T14:30:00Z"},
+		{BlackoutPool, "arena", "2026-10-21T14:30:00Z", ""},
+		// Daily windows wrap around midnight, and overlapping windows end
+		// with the latest.
+		{BlackoutProfile, "casual", "2026-10-21T23:00:00Z", "2026-10-22T06:00:00Z"},
+		{BlackoutProfile, "casual", "2026-10-21T01:00:00Z", "2026-10-21T06:00:00Z"},
+		{BlackoutProfile, "casual", "2026-10-21T05:30:00Z", "2026-10-21T07:00:00Z"},
+		{BlackoutProfile, "casual", "2026-10-21T12:00:00Z", ""},
+	}
+	for _, tt := range tests {
+		until, ok := blackouts.Active(tt.target, tt.name, at(tt.now))
+		if tt.until == "" {
+			require.False(t, ok, "%s:%s at %s", tt.target, tt.name, tt.now)
+			continue
+		}
+		require.True(t, ok, "%s:%s at %s", tt.target, tt.name, tt.now)
+		require.True(t, at(tt.until).Equal(until), "%s:%s at %s ends %s", tt.target, tt.name, tt.now, until)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		key   string
+		value interface{}
+	}{
+		{"negative interval", KeyRegistrationInterval, "-1s"},
+		{"zero timeout", KeyPendingReleaseTimeout, "0s"},
+		{"negative retention", KeyMatchRosterRetention, "-1m"},
+		{"negative counts retention", KeyTicketCountsRetention, "-1m"},
+		{"zero claim lease", KeyClaimLeaseTimeout, "0s"},
+		{"negative slow command threshold", KeySlowCommandThreshold, "-1ms"},
+		{"zero intake batch", KeyTicketIntakeBatchSize, 0},
+		{"zero intake delay", KeyTicketIntakeMaxDelay, "0s"},
+		{"negative heartbeat timeout", KeyTicketHeartbeatTimeout, "-1s"},
+		{"short heartbeat timeout", KeyTicketHeartbeatTimeout, "2ns"},
+		{"zero deadline interval", KeyTicketDeadlineInterval, "0s"},
+		{"zero max reservation ttl", KeyMaxReservationTTL, "0s"},
+		{"negative quota", KeyQueryClientQPS, -1},
+		{"zero max snapshots", KeyQueryMaxSnapshots, 0},
+		{"negative decision log size", KeyDecisionLogSize, -1},
+		{"unknown codec", KeyCompressionCodec, "lz4"},
+		{"mistyped duration", KeyAssignedDeleteTimeout, "ten minutes"},
+		{"backoff multiplier", KeyBackoffMultiplier, 0.5},
+		{"unknown fairness policy", KeyFairnessPolicy, "lottery"},
+		{"missing fairness weight", KeyFairnessWeights, []string{"ranked"}},
+		{"zero fairness weight", KeyFairnessWeights, []string{"ranked=0"}},
+		{"unknown id scheme", KeyIDScheme, "uuidv4"},
+		{"negative id node", KeyIDNode, -1},
+		{"missing profile group", KeyProfileGroups, []string{"ranked="}},
+		{"negative max tickets", KeyMaxTicketsPerMatch, -1},
+		{"zero profile max tickets", KeyProfileMaxTicketsPerMatch, []string{"raid=0"}},
+		{"unknown rpc compression", KeyRPCCompression, "zstd"},
+		{"unknown query source", KeyQuerySource, "kafka"},
+		{"change stream not recorded", KeyQuerySource, QuerySourceChangeStream},
+		{"unknown pubsub driver", KeyPubSubDriver, "kafka"},
+		{"nats without url", KeyPubSubDriver, PubSubNATS},
+		{"missing client partition", KeyPartitionClients, []string{"director-a"}},
+		{"unknown blackout target", KeyBlackoutWindows, []string{"mode:ranked=02:00/04:00"}},
+		{"missing blackout end", KeyBlackoutWindows, []string{"pool:ranked=02:00"}},
+		{"mixed blackout times", KeyBlackoutWindows, []string{"pool:ranked=02:00/2026-10-20T04:00:00Z"}},
+		{"reversed blackout", KeyBlackoutWindows, []string{"pool:ranked=2026-10-20T04:00:00Z/2026-10-20T02:00:00Z"}},
+		{"empty blackout", KeyBlackoutWindows, []string{"pool:ranked=02:00/02:00"}},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := viper.New()
+			cfg.Set(tt.key, tt.value)
+			err := Validate(cfg)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.key)
+		})
+	}
+}
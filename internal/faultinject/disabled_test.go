@@ -0,0 +1,29 @@
+// +build !faultinject
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package faultinject
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDisabled(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("faultInjection.evaluator.mode", ModeFail)
+	require.Equal(t, Fault{Target: TargetEvaluator, Mode: ModeNone}, Get(cfg, TargetEvaluator))
+}
@@ -0,0 +1,33 @@
+// +build faultinject
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package faultinject
+
+import (
+	"open-match.dev/open-match/internal/config"
+)
+
+// Get returns the fault configured for the target.
+func Get(cfg config.View, target string) Fault {
+	fraction := 1.0
+	if key := "faultInjection." + target + ".fraction"; cfg.IsSet(key) {
+		fraction = cfg.GetFloat64(key)
+	}
+	return Fault{
+		Target:   target,
+		Mode:     cfg.GetString("faultInjection." + target + ".mode"),
+		Fraction: fraction,
+	}
+}
@@ -0,0 +1,33 @@
+// +build faultinject
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package faultinject
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	cfg := viper.New()
+	require.Equal(t, Fault{Target: TargetEvaluator, Fraction: 1}, Get(cfg, TargetEvaluator))
+
+	cfg.Set("faultInjection.evaluator.mode", ModeHang)
+	cfg.Set("faultInjection.evaluator.fraction", 0.25)
+	require.Equal(t, Fault{Target: TargetEvaluator, Mode: ModeHang, Fraction: 0.25}, Get(cfg, TargetEvaluator))
+	require.Equal(t, Fault{Target: TargetMatchFunction, Fraction: 1}, Get(cfg, TargetMatchFunction))
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// Package faultinject makes the backend's match function and evaluator clients
+// fail, hang or return malformed data on demand, so that the code handling
+// misbehaving dependencies can be covered by tests rather than only exercised
+// in outages.
+//
+// Faults are only read from the config in binaries built with the faultinject
+// build tag.  Other builds never inject a fault, whatever the config says.
+package faultinject
+
+import (
+	"context"
+	"math/rand"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Targets of the injected faults.  The fault of a target is configured by
+// "faultInjection.<target>.mode", and the fraction of calls it applies to by
+// "faultInjection.<target>.fraction", 1 by default.
+const (
+	TargetMatchFunction = "matchFunction"
+	TargetEvaluator     = "evaluator"
+)
+
+// Modes of the injected faults.
+const (
+	// ModeNone injects no fault.
+	ModeNone = "none"
+	// ModeFail fails the call with Unavailable, without making it.
+	ModeFail = "fail"
+	// ModeHang blocks the call until its context is done.
+	ModeHang = "hang"
+	// ModeMalformed makes the call, and adds malformed data to its results:
+	// a match_id proposed twice by the match function, and an accepted
+	// match_id which was never proposed by the evaluator.
+	ModeMalformed = "malformed"
+)
+
+// MalformedMatchID is the match_id of malformed proposals and evaluator
+// results.
+const MalformedMatchID = "faultinject-malformed"
+
+// Fault is the fault injected into the calls to a target.
+type Fault struct {
+	Target string
+	// Mode is one of ModeNone, ModeFail, ModeHang or ModeMalformed.
+	Mode string
+	// Fraction of calls, between 0 and 1, the fault is injected into.
+	Fraction float64
+}
+
+// Inject injects the fault into a call, when it is picked for the fault.  It
+// returns an error for calls which fail or hang, and true for calls whose
+// results should be malformed.
+func (f Fault) Inject(ctx context.Context) (bool, error) {
+	if f.Mode == "" || f.Mode == ModeNone || rand.Float64() >= f.Fraction {
+		return false, nil
+	}
+
+	switch f.Mode {
+	case ModeFail:
+		return false, status.Errorf(codes.Unavailable, "injected failure of the %s call", f.Target)
+	case ModeHang:
+		<-ctx.Done()
+		return false, ctx.Err()
+	case ModeMalformed:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// MalformedProposals returns proposals for the profile which share a match_id.
+func MalformedProposals(profile *pb.MatchProfile) []*pb.Match {
+	return []*pb.Match{
+		{MatchId: MalformedMatchID, MatchProfile: profile.GetName()},
+		{MatchId: MalformedMatchID, MatchProfile: profile.GetName()},
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package faultinject
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestInject(t *testing.T) {
+	ctx := context.Background()
+
+	for _, f := range []Fault{
+		{Target: TargetEvaluator},
+		{Target: TargetEvaluator, Mode: ModeNone, Fraction: 1},
+		{Target: TargetEvaluator, Mode: ModeFail, Fraction: 0},
+		{Target: TargetEvaluator, Mode: "unknown", Fraction: 1},
+	} {
+		malformed, err := f.Inject(ctx)
+		require.NoError(t, err, "%+v", f)
+		require.False(t, malformed, "%+v", f)
+	}
+
+	malformed, err := Fault{Target: TargetEvaluator, Mode: ModeFail, Fraction: 1}.Inject(ctx)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Contains(t, err.Error(), TargetEvaluator)
+	require.False(t, malformed)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = Fault{Target: TargetEvaluator, Mode: ModeHang, Fraction: 1}.Inject(timeoutCtx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	malformed, err = Fault{Target: TargetEvaluator, Mode: ModeMalformed, Fraction: 1}.Inject(ctx)
+	require.NoError(t, err)
+	require.True(t, malformed)
+}
+
+func TestMalformedProposals(t *testing.T) {
+	proposals := MalformedProposals(&pb.MatchProfile{Name: "profile"})
+	require.Len(t, proposals, 2)
+	require.Equal(t, proposals[0].MatchId, proposals[1].MatchId)
+	require.Equal(t, "profile", proposals[0].MatchProfile)
+}
@@ -18,13 +18,15 @@
 package filter
 
 import (
+	"sort"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/customfilter"
+	"open-match.dev/open-match/pkg/errorinfo"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -40,11 +42,21 @@ var (
 // PoolFilter contains all the filtering criteria from a Pool that the Ticket
 // needs to meet to belong to that Pool.
 type PoolFilter struct {
-	DoubleRangeFilters  []*pb.DoubleRangeFilter
-	StringEqualsFilters []*pb.StringEqualsFilter
-	TagPresentFilters   []*pb.TagPresentFilter
-	CreatedBefore       time.Time
-	CreatedAfter        time.Time
+	DoubleRangeFilters        []*pb.DoubleRangeFilter
+	StringEqualsFilters       []*pb.StringEqualsFilter
+	TagPresentFilters         []*pb.TagPresentFilter
+	StringInFilters           []*pb.StringInFilter
+	StringListContainsFilters []*pb.StringListContainsFilter
+	CreatedBefore             time.Time
+	CreatedAfter              time.Time
+	// MaxAgeCutoff is the create time at or before which entities are past
+	// the pool's max_age, derived when the PoolFilter is created.
+	MaxAgeCutoff  time.Time
+	ReportExpired bool
+	Partition     string
+	// CustomFilters are created from the pool's extensions, and evaluated
+	// after every built-in filter passes.
+	CustomFilters []customfilter.Filter
 }
 
 // NewPoolFilter validates a Pool's filtering criteria and returns a PoolFilter.
@@ -54,40 +66,117 @@ func NewPoolFilter(pool *pb.Pool) (*PoolFilter, error) {
 
 	if pool.GetCreatedBefore() != nil {
 		if cb, err = ptypes.Timestamp(pool.GetCreatedBefore()); err != nil {
-			return nil, status.Error(codes.InvalidArgument, ".invalid created_before value")
+			return nil, invalidPool(pool, ".invalid created_before value")
 		}
 	}
 
 	if pool.GetCreatedAfter() != nil {
 		if ca, err = ptypes.Timestamp(pool.GetCreatedAfter()); err != nil {
-			return nil, status.Error(codes.InvalidArgument, ".invalid created_after value")
+			return nil, invalidPool(pool, ".invalid created_after value")
 		}
 	}
 
+	var cutoff time.Time
+	if pool.GetMaxAge() != nil {
+		maxAge, err := ptypes.Duration(pool.GetMaxAge())
+		if err != nil || maxAge <= 0 {
+			return nil, invalidPool(pool, ".invalid max_age value")
+		}
+		cutoff = time.Now().Add(-maxAge)
+	}
+
+	if pool.GetReportExpired() && cutoff.IsZero() {
+		return nil, invalidPool(pool, ".report_expired requires max_age")
+	}
+
+	custom, err := newCustomFilters(pool)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PoolFilter{
-		DoubleRangeFilters:  pool.GetDoubleRangeFilters(),
-		StringEqualsFilters: pool.GetStringEqualsFilters(),
-		TagPresentFilters:   pool.GetTagPresentFilters(),
-		CreatedBefore:       cb,
-		CreatedAfter:        ca,
+		DoubleRangeFilters:        pool.GetDoubleRangeFilters(),
+		StringEqualsFilters:       pool.GetStringEqualsFilters(),
+		TagPresentFilters:         pool.GetTagPresentFilters(),
+		StringInFilters:           pool.GetStringInFilters(),
+		StringListContainsFilters: pool.GetStringListContainsFilters(),
+		CreatedBefore:             cb,
+		CreatedAfter:              ca,
+		MaxAgeCutoff:              cutoff,
+		ReportExpired:             pool.GetReportExpired(),
+		Partition:                 pool.GetPartition(),
+		CustomFilters:             custom,
 	}, nil
 }
 
+// newCustomFilters creates the custom filters named by the pool's extensions,
+// in the order of their names.
+func newCustomFilters(pool *pb.Pool) ([]customfilter.Filter, error) {
+	if len(pool.GetExtensions()) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(pool.GetExtensions()))
+	for name := range pool.GetExtensions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filters := make([]customfilter.Filter, 0, len(names))
+	for _, name := range names {
+		f, err := customfilter.New(name, pool.GetExtensions()[name])
+		if err != nil {
+			return nil, invalidPool(pool, ".invalid extensions value: "+err.Error())
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+func invalidPool(pool *pb.Pool, msg string) error {
+	return errorinfo.New(codes.InvalidArgument, errorinfo.ReasonInvalidPool, map[string]string{errorinfo.MetadataPoolName: pool.GetName()}, msg)
+}
+
 type filteredEntity interface {
-	GetId() string
-	GetSearchFields() *pb.SearchFields
+	customfilter.Entity
 	GetCreateTime() *timestamp.Timestamp
 }
 
+// partitionedEntity is a filteredEntity which belongs to a partition.  Other
+// entities, such as backfills, pass the filters of every partition.
+type partitionedEntity interface {
+	GetPartition() string
+}
+
 // In returns true if the Ticket meets all the criteria for this PoolFilter.
 func (pf *PoolFilter) In(entity filteredEntity) bool {
+	return pf.matches(entity, false)
+}
+
+// Expired returns true if the Ticket meets all the criteria for this
+// PoolFilter except for the pool's max_age, which it is older than.
+func (pf *PoolFilter) Expired(entity filteredEntity) bool {
+	if pf.MaxAgeCutoff.IsZero() {
+		return false
+	}
+	return pf.matches(entity, true)
+}
+
+// matches returns true if the entity meets all the criteria for this
+// PoolFilter, with its age past the max age cutoff when expired is set, and
+// within it otherwise.
+func (pf *PoolFilter) matches(entity filteredEntity, expired bool) bool {
 	s := entity.GetSearchFields()
 
 	if s == nil {
 		s = emptySearchFields
 	}
 
-	if !pf.CreatedAfter.IsZero() || !pf.CreatedBefore.IsZero() {
+	if p, ok := entity.(partitionedEntity); ok && p.GetPartition() != pf.Partition {
+		return false
+	}
+
+	if !pf.CreatedAfter.IsZero() || !pf.CreatedBefore.IsZero() || !pf.MaxAgeCutoff.IsZero() {
 		// CreateTime is only populated by Open Match and hence expected to be valid.
 		if ct, err := ptypes.Timestamp(entity.GetCreateTime()); err == nil {
 			if !pf.CreatedAfter.IsZero() {
@@ -101,11 +190,20 @@ func (pf *PoolFilter) In(entity filteredEntity) bool {
 					return false
 				}
 			}
+
+			if !pf.MaxAgeCutoff.IsZero() {
+				if ct.After(pf.MaxAgeCutoff) == expired {
+					return false
+				}
+			}
 		} else {
 			logger.WithFields(logrus.Fields{
 				"error": err.Error(),
 				"id":    entity.GetId(),
 			}).Error("failed to get time from Timestamp proto")
+			if expired {
+				return false
+			}
 		}
 	}
 
@@ -157,5 +255,46 @@ outer:
 		return false
 	}
 
+	for _, f := range pf.StringInFilters {
+		v, ok := s.StringArgs[f.StringArg]
+		if !ok {
+			return false
+		}
+		if !containsString(f.Values, v) {
+			return false
+		}
+	}
+
+listOuter:
+	for _, f := range pf.StringListContainsFilters {
+		l, ok := s.StringListArgs[f.StringListArg]
+		if !ok {
+			return false
+		}
+		for _, v := range l.GetValues() {
+			if containsString(f.Values, v) {
+				continue listOuter
+			}
+		}
+		return false
+	}
+
+	for _, f := range pf.CustomFilters {
+		if !f.In(entity) {
+			return false
+		}
+	}
+
 	return true
 }
+
+// containsString returns true if v is one of values.  Filter value lists are
+// expected to be small, so a linear scan beats building a set per ticket.
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
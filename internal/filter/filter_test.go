@@ -15,14 +15,19 @@
 package filter
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/filter/testcases"
+	"open-match.dev/open-match/pkg/customfilter"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -101,6 +106,22 @@ func TestValidPoolFilter(t *testing.T) {
 			codes.InvalidArgument,
 			".invalid created_after value",
 		},
+		{
+			"negative max age",
+			&pb.Pool{
+				MaxAge: ptypes.DurationProto(-time.Second),
+			},
+			codes.InvalidArgument,
+			".invalid max_age value",
+		},
+		{
+			"report expired without max age",
+			&pb.Pool{
+				ReportExpired: true,
+			},
+			codes.InvalidArgument,
+			".report_expired requires max_age",
+		},
 	} {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -115,3 +136,206 @@ func TestValidPoolFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxAge(t *testing.T) {
+	now := time.Now()
+	pf, err := NewPoolFilter(&pb.Pool{
+		StringEqualsFilters: []*pb.StringEqualsFilter{
+			{StringArg: "mode", Value: "ranked"},
+		},
+		MaxAge:        ptypes.DurationProto(time.Minute),
+		ReportExpired: true,
+	})
+	require.NoError(t, err)
+
+	ticket := func(mode string, age time.Duration) *pb.Ticket {
+		return &pb.Ticket{
+			SearchFields: &pb.SearchFields{StringArgs: map[string]string{"mode": mode}},
+			CreateTime:   mustTimestampProto(t, now.Add(-age)),
+		}
+	}
+
+	fresh := ticket("ranked", time.Second)
+	require.True(t, pf.In(fresh))
+	require.False(t, pf.Expired(fresh))
+
+	stale := ticket("ranked", time.Hour)
+	require.False(t, pf.In(stale))
+	require.True(t, pf.Expired(stale))
+
+	// Tickets failing the other filters are neither in the pool nor expired.
+	other := ticket("casual", time.Hour)
+	require.False(t, pf.In(other))
+	require.False(t, pf.Expired(other))
+
+	// Without a max age nothing expires.
+	pf, err = NewPoolFilter(&pb.Pool{})
+	require.NoError(t, err)
+	require.True(t, pf.In(stale))
+	require.False(t, pf.Expired(stale))
+}
+
+func TestPartition(t *testing.T) {
+	pf, err := NewPoolFilter(&pb.Pool{Partition: "studio-a"})
+	require.NoError(t, err)
+
+	require.True(t, pf.In(&pb.Ticket{Partition: "studio-a"}))
+	require.False(t, pf.In(&pb.Ticket{Partition: "studio-b"}))
+	require.False(t, pf.In(&pb.Ticket{}))
+	// Backfills have no partition.
+	require.True(t, pf.In(&pb.Backfill{}))
+
+	// Pools without a partition only select tickets of the default partition.
+	pf, err = NewPoolFilter(&pb.Pool{})
+	require.NoError(t, err)
+	require.True(t, pf.In(&pb.Ticket{}))
+	require.False(t, pf.In(&pb.Ticket{Partition: "studio-a"}))
+}
+
+// tagFilter passes entities with the tag given by the pool's extension.
+type tagFilter string
+
+func (f tagFilter) In(entity customfilter.Entity) bool {
+	for _, tag := range entity.GetSearchFields().GetTags() {
+		if tag == string(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCustomFilter(t *testing.T) {
+	customfilter.Register("tag", func(config *any.Any) (customfilter.Filter, error) {
+		tag := &wrappers.StringValue{}
+		if err := ptypes.UnmarshalAny(config, tag); err != nil {
+			return nil, err
+		}
+		return tagFilter(tag.GetValue()), nil
+	})
+	config, err := ptypes.MarshalAny(&wrappers.StringValue{Value: "eu"})
+	require.NoError(t, err)
+
+	pf, err := NewPoolFilter(&pb.Pool{Extensions: map[string]*any.Any{"tag": config}})
+	require.NoError(t, err)
+	require.True(t, pf.In(&pb.Ticket{SearchFields: &pb.SearchFields{Tags: []string{"eu"}}}))
+	require.False(t, pf.In(&pb.Ticket{SearchFields: &pb.SearchFields{Tags: []string{"us"}}}))
+	require.False(t, pf.In(&pb.Backfill{}))
+
+	// Built-in filters are still applied.
+	pf, err = NewPoolFilter(&pb.Pool{
+		TagPresentFilters: []*pb.TagPresentFilter{{Tag: "ranked"}},
+		Extensions:        map[string]*any.Any{"tag": config},
+	})
+	require.NoError(t, err)
+	require.True(t, pf.In(&pb.Ticket{SearchFields: &pb.SearchFields{Tags: []string{"eu", "ranked"}}}))
+	require.False(t, pf.In(&pb.Ticket{SearchFields: &pb.SearchFields{Tags: []string{"eu"}}}))
+
+	// Unregistered filters and invalid configurations make the pool invalid.
+	for _, extensions := range []map[string]*any.Any{
+		{"geo": config},
+		{"tag": {TypeUrl: "type.googleapis.com/google.protobuf.Int64Value"}},
+	} {
+		_, err = NewPoolFilter(&pb.Pool{Name: "pool", Extensions: extensions})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+// BenchmarkPoolFilterIn measures filter evaluation across a range of pool
+// shapes and ticket counts.  Roughly half of the tickets pass each pool, so
+// both the accept and early-reject paths are exercised.
+//
+// CPU and memory profiles can be collected with `make profile-filter`.
+func BenchmarkPoolFilterIn(b *testing.B) {
+	now := time.Now()
+	pools := []struct {
+		name string
+		pool *pb.Pool
+	}{
+		{"empty", &pb.Pool{}},
+		{"doubleRange", &pb.Pool{
+			DoubleRangeFilters: []*pb.DoubleRangeFilter{
+				{DoubleArg: "level", Min: 0, Max: 49},
+			},
+		}},
+		{"stringEquals", &pb.Pool{
+			StringEqualsFilters: []*pb.StringEqualsFilter{
+				{StringArg: "mode", Value: "mode-0"},
+			},
+		}},
+		{"tagPresent", &pb.Pool{
+			TagPresentFilters: []*pb.TagPresentFilter{
+				{Tag: "tag-0"},
+			},
+		}},
+		{"createTime", &pb.Pool{
+			CreatedAfter:  mustTimestampProto(b, now.Add(-time.Hour)),
+			CreatedBefore: mustTimestampProto(b, now.Add(-30*time.Minute)),
+		}},
+		{"mixed", &pb.Pool{
+			DoubleRangeFilters: []*pb.DoubleRangeFilter{
+				{DoubleArg: "level", Min: 0, Max: 99},
+				{DoubleArg: "latency", Min: 0, Max: 49, Exclude: pb.DoubleRangeFilter_BOTH},
+			},
+			StringEqualsFilters: []*pb.StringEqualsFilter{
+				{StringArg: "region", Value: "region-0"},
+			},
+			TagPresentFilters: []*pb.TagPresentFilter{
+				{Tag: "tag-0"},
+				{Tag: "tag-1"},
+			},
+			CreatedAfter: mustTimestampProto(b, now.Add(-time.Hour)),
+		}},
+	}
+
+	for _, count := range []int{100, 1000, 10000} {
+		tickets := benchmarkTickets(b, count, now)
+		for _, p := range pools {
+			pf, err := NewPoolFilter(p.pool)
+			require.NoError(b, err)
+
+			b.Run(fmt.Sprintf("%s/tickets=%d", p.name, count), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					for _, t := range tickets {
+						pf.In(t)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchmarkTickets returns count tickets with search fields spread evenly
+// across the values filtered on by BenchmarkPoolFilterIn.
+func benchmarkTickets(b *testing.B, count int, now time.Time) []*pb.Ticket {
+	tickets := make([]*pb.Ticket, 0, count)
+	for i := 0; i < count; i++ {
+		tags := []string{fmt.Sprintf("tag-%d", i%2)}
+		if i%4 == 0 {
+			tags = append(tags, "tag-1")
+		}
+		tickets = append(tickets, &pb.Ticket{
+			Id: fmt.Sprintf("ticket-%d", i),
+			SearchFields: &pb.SearchFields{
+				DoubleArgs: map[string]float64{
+					"level":   float64(i % 100),
+					"latency": float64(i % 100),
+				},
+				StringArgs: map[string]string{
+					"mode":   fmt.Sprintf("mode-%d", i%2),
+					"region": fmt.Sprintf("region-%d", i%2),
+				},
+				Tags: tags,
+			},
+			CreateTime: mustTimestampProto(b, now.Add(-time.Duration(i%60)*time.Minute)),
+		})
+	}
+	return tickets
+}
+
+func mustTimestampProto(tb testing.TB, t time.Time) *timestamp.Timestamp {
+	ts, err := ptypes.TimestampProto(t)
+	require.NoError(tb, err)
+	return ts
+}
@@ -123,6 +123,57 @@ func IncludedTestCases() []TestCase {
 
 		multipleFilters(true, true, true),
 
+		{
+			"StringIn simple positive",
+			&pb.SearchFields{
+				StringArgs: map[string]string{
+					"field": "b",
+				},
+			},
+			&pb.Pool{
+				StringInFilters: []*pb.StringInFilter{
+					{
+						StringArg: "field",
+						Values:    []string{"a", "b", "c"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringListContains simple positive",
+			&pb.SearchFields{
+				StringListArgs: map[string]*pb.StringList{
+					"modes": {Values: []string{"ranked", "casual"}},
+				},
+			},
+			&pb.Pool{
+				StringListContainsFilters: []*pb.StringListContainsFilter{
+					{
+						StringListArg: "modes",
+						Values:        []string{"casual"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringListContains any of values",
+			&pb.SearchFields{
+				StringListArgs: map[string]*pb.StringList{
+					"modes": {Values: []string{"ranked"}},
+				},
+			},
+			&pb.Pool{
+				StringListContainsFilters: []*pb.StringListContainsFilter{
+					{
+						StringListArg: "modes",
+						Values:        []string{"casual", "ranked"},
+					},
+				},
+			},
+		},
+
 		{
 			"CreatedBefore simple positive",
 			nil,
@@ -145,6 +196,13 @@ func IncludedTestCases() []TestCase {
 				CreatedAfter:  timestamp(now.Add(time.Hour * -1)),
 			},
 		},
+		{
+			"MaxAge positive",
+			nil,
+			&pb.Pool{
+				MaxAge: ptypes.DurationProto(time.Hour),
+			},
+		},
 		{
 			"No time search criteria positive",
 			nil,
@@ -339,6 +397,103 @@ func ExcludedTestCases() []TestCase {
 			},
 		},
 
+		{
+			"StringIn simple negative",
+			&pb.SearchFields{
+				StringArgs: map[string]string{
+					"field": "d",
+				},
+			},
+			&pb.Pool{
+				StringInFilters: []*pb.StringInFilter{
+					{
+						StringArg: "field",
+						Values:    []string{"a", "b", "c"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringIn no values",
+			&pb.SearchFields{
+				StringArgs: map[string]string{
+					"field": "a",
+				},
+			},
+			&pb.Pool{
+				StringInFilters: []*pb.StringInFilter{
+					{
+						StringArg: "field",
+					},
+				},
+			},
+		},
+
+		{
+			"StringIn no SearchFields",
+			nil,
+			&pb.Pool{
+				StringInFilters: []*pb.StringInFilter{
+					{
+						StringArg: "field",
+						Values:    []string{"a"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringListContains simple negative",
+			&pb.SearchFields{
+				StringListArgs: map[string]*pb.StringList{
+					"modes": {Values: []string{"ranked", "casual"}},
+				},
+			},
+			&pb.Pool{
+				StringListContainsFilters: []*pb.StringListContainsFilter{
+					{
+						StringListArg: "modes",
+						Values:        []string{"custom"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringListContains empty list",
+			&pb.SearchFields{
+				StringListArgs: map[string]*pb.StringList{
+					"modes": {},
+				},
+			},
+			&pb.Pool{
+				StringListContainsFilters: []*pb.StringListContainsFilter{
+					{
+						StringListArg: "modes",
+						Values:        []string{"ranked"},
+					},
+				},
+			},
+		},
+
+		{
+			"StringListContains missing field",
+			&pb.SearchFields{
+				StringListArgs: map[string]*pb.StringList{
+					"othermodes": {Values: []string{"ranked"}},
+				},
+			},
+			&pb.Pool{
+				StringListContainsFilters: []*pb.StringListContainsFilter{
+					{
+						StringListArg: "modes",
+						Values:        []string{"ranked"},
+					},
+				},
+			},
+		},
+
 		{
 			"CreatedBefore simple negative",
 			nil,
@@ -92,6 +92,65 @@ func (x *BackfillInternal) GetTicketIds() []string {
 	return nil
 }
 
+// TicketEvents are published on the internal pubsub when tickets go through a
+// step of their lifecycle.
+type TicketEvents struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Ids of the tickets which went through the step.
+	TicketIds []string `protobuf:"bytes,1,rep,name=ticket_ids,json=ticketIds,proto3" json:"ticket_ids,omitempty"`
+	// The step.
+	Event *pb.TicketEvent `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+}
+
+func (x *TicketEvents) Reset() {
+	*x = TicketEvents{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_api_messages_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TicketEvents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketEvents) ProtoMessage() {}
+
+func (x *TicketEvents) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_api_messages_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketEvents.ProtoReflect.Descriptor instead.
+func (*TicketEvents) Descriptor() ([]byte, []int) {
+	return file_internal_api_messages_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TicketEvents) GetTicketIds() []string {
+	if x != nil {
+		return x.TicketIds
+	}
+	return nil
+}
+
+func (x *TicketEvents) GetEvent() *pb.TicketEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
 var File_internal_api_messages_proto protoreflect.FileDescriptor
 
 var file_internal_api_messages_proto_rawDesc = []byte{
@@ -105,10 +164,16 @@ var file_internal_api_messages_proto_rawDesc = []byte{
 	0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c,
 	0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69,
 	0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
-	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x42, 0x28, 0x5a, 0x26, 0x6f, 0x70, 0x65,
-	0x6e, 0x2d, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x64, 0x65, 0x76, 0x2f, 0x6f, 0x70, 0x65, 0x6e,
-	0x2d, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f,
-	0x69, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x22, 0x5b, 0x0a, 0x0c, 0x54, 0x69, 0x63,
+	0x6b, 0x65, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x63,
+	0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x74,
+	0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x12, 0x2c, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x42, 0x28, 0x5a, 0x26, 0x6f, 0x70, 0x65, 0x6e, 0x2d, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x2e, 0x64, 0x65, 0x76, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x2d, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x69, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -123,18 +188,21 @@ func file_internal_api_messages_proto_rawDescGZIP() []byte {
 	return file_internal_api_messages_proto_rawDescData
 }
 
-var file_internal_api_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_internal_api_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_internal_api_messages_proto_goTypes = []interface{}{
 	(*BackfillInternal)(nil), // 0: openmatch.internal.BackfillInternal
-	(*pb.Backfill)(nil),      // 1: openmatch.Backfill
+	(*TicketEvents)(nil),     // 1: openmatch.internal.TicketEvents
+	(*pb.Backfill)(nil),      // 2: openmatch.Backfill
+	(*pb.TicketEvent)(nil),   // 3: openmatch.TicketEvent
 }
 var file_internal_api_messages_proto_depIdxs = []int32{
-	1, // 0: openmatch.internal.BackfillInternal.backfill:type_name -> openmatch.Backfill
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2, // 0: openmatch.internal.BackfillInternal.backfill:type_name -> openmatch.Backfill
+	3, // 1: openmatch.internal.TicketEvents.event:type_name -> openmatch.TicketEvent
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_internal_api_messages_proto_init() }
@@ -155,6 +223,18 @@ func file_internal_api_messages_proto_init() {
 				return nil
 			}
 		}
+		file_internal_api_messages_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TicketEvents); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -162,7 +242,7 @@ func file_internal_api_messages_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_api_messages_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
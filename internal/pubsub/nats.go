@@ -0,0 +1,68 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// natsPubSub publishes on a NATS server, which fans messages out to the
+// subscribed replicas without adding load to the state store.  The client
+// reconnects, and resubscribes, on its own.
+type natsPubSub struct {
+	conn *nats.Conn
+}
+
+func newNATS(url string) (Service, error) {
+	conn, err := nats.Connect(url, nats.Name("open-match"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to connect to NATS at %s: %v", url, err)
+	}
+	return &natsPubSub{conn: conn}, nil
+}
+
+func (n *natsPubSub) Publish(ctx context.Context, topic string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return status.Errorf(codes.Unavailable, "Publish, %v", err)
+	}
+	if err := n.conn.Publish(topic, data); err != nil {
+		return status.Errorf(codes.Unavailable, "Publish, failed to publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+func (n *natsPubSub) Subscribe(ctx context.Context, topic string, handler func(data []byte)) error {
+	sub, err := n.conn.Subscribe(topic, func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "Subscribe, failed to subscribe to %s: %v", topic, err)
+	}
+	defer func() {
+		_ = sub.Unsubscribe()
+	}()
+
+	<-ctx.Done()
+	return nil
+}
+
+func (n *natsPubSub) Close() error {
+	n.conn.Close()
+	return nil
+}
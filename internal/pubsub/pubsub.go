@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pubsub carries the notifications the core services send each other.
+// Every step of a ticket's lifecycle is published as a ticket event:
+// frontends wake WatchAssignments calls when tickets are assigned, query
+// services invalidate the paginated query snapshots holding tickets which are
+// no longer active, and other systems may follow the events too.  Delivery is
+// best effort, so consumers keep polling the state store as a fallback.
+package pubsub
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+var (
+	logger = logrus.WithFields(logrus.Fields{
+		"app":       "openmatch",
+		"component": "pubsub",
+	})
+)
+
+// TopicTicketEvents is the topic ipb.TicketEvents are published on.
+const TopicTicketEvents = "openmatch.tickets"
+
+// Service publishes messages to topics, and delivers them to the subscribers
+// connected at the time.  Messages published while a subscriber is
+// disconnected are lost.
+type Service interface {
+	// Publish sends data to the subscribers of topic.
+	Publish(ctx context.Context, topic string, data []byte) error
+
+	// Subscribe calls handler with the messages published to topic, one at a
+	// time, until ctx is canceled, in which case it returns nil, or the
+	// subscription fails.
+	Subscribe(ctx context.Context, topic string, handler func(data []byte)) error
+
+	// Close releases the connections of the service.
+	Close() error
+}
+
+// New returns the Service of the pubsub driver configured in cfg, or nil if
+// it is config.PubSubNone.
+func New(cfg config.View) (Service, error) {
+	ps := config.GetPubSub(cfg)
+	switch ps.Driver {
+	case config.PubSubNone:
+		return nil, nil
+	case config.PubSubRedis:
+		return newRedis(cfg), nil
+	case config.PubSubNATS:
+		return newNATS(ps.NATSURL)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown pubsub driver %q", ps.Driver)
+	}
+}
+
+// PublishTicketEvent publishes the event of the tickets on s.  It does
+// nothing if s is nil, or there are no ticket ids.
+func PublishTicketEvent(ctx context.Context, s Service, ids []string, event *pb.TicketEvent) error {
+	if s == nil || len(ids) == 0 {
+		return nil
+	}
+	data, err := proto.Marshal(&ipb.TicketEvents{TicketIds: ids, Event: event})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal ticket events")
+	}
+	return s.Publish(ctx, TopicTicketEvents, data)
+}
+
+// SubscribeTicketEvents calls handler with the ticket events published on s,
+// as Subscribe does.  Malformed events are logged and skipped.
+func SubscribeTicketEvents(ctx context.Context, s Service, handler func(*ipb.TicketEvents)) error {
+	return s.Subscribe(ctx, TopicTicketEvents, func(data []byte) {
+		events := &ipb.TicketEvents{}
+		if err := proto.Unmarshal(data, events); err != nil {
+			logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Warning("failed to unmarshal ticket events")
+			return
+		}
+		handler(events)
+	})
+}
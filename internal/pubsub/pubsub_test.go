@@ -0,0 +1,97 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/internal/statestore"
+	statestoreTesting "open-match.dev/open-match/internal/statestore/testing"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestNewNone(t *testing.T) {
+	s, err := New(viper.New())
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	// Publishing on a disabled service does nothing.
+	require.NoError(t, PublishTicketEvent(utilTesting.NewContext(t), s, []string{"a"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED}))
+}
+
+func TestRedisTicketEvents(t *testing.T) {
+	cfg := viper.New()
+	closer := statestoreTesting.New(t, cfg)
+	defer closer()
+	cfg.Set(config.KeyPubSubDriver, config.PubSubRedis)
+
+	s, err := New(cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	store := WithTicketEvents(statestore.New(cfg), s)
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(utilTesting.NewContext(t))
+	events := make(chan *ipb.TicketEvents, 1)
+	subscribed := make(chan error, 1)
+	go func() {
+		subscribed <- SubscribeTicketEvents(ctx, s, func(e *ipb.TicketEvents) {
+			select {
+			case events <- e:
+			default:
+			}
+		})
+	}()
+
+	// Events published before the subscription is made are lost, so record
+	// events until the subscriber receives one.
+	var got *ipb.TicketEvents
+	for got == nil {
+		require.NoError(t, store.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED}))
+		select {
+		case got = <-events:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	require.Equal(t, []string{"a", "b"}, got.GetTicketIds())
+	require.Equal(t, pb.TicketEvent_ASSIGNED, got.GetEvent().GetType())
+
+	// Canceling the context ends the subscription without an error.
+	cancel()
+	select {
+	case err = <-subscribed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "subscription didn't end")
+	}
+}
+
+func TestNATSUnreachable(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set(config.KeyPubSubDriver, config.PubSubNATS)
+	cfg.Set(config.KeyPubSubNATSURL, "nats://127.0.0.1:1")
+
+	s, err := New(cfg)
+	require.Error(t, err)
+	require.Nil(t, s)
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+)
+
+// redisPubSub publishes on the state store's redis, so it needs no other
+// infrastructure, but every message is sent to every subscribed replica
+// through the redis primary.
+type redisPubSub struct {
+	pool *redis.Pool
+}
+
+func newRedis(cfg config.View) Service {
+	return &redisPubSub{pool: statestore.GetRedisPool(cfg)}
+}
+
+func (r *redisPubSub) Publish(ctx context.Context, topic string, data []byte) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "Publish, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(conn)
+
+	if _, err = conn.Do("PUBLISH", topic, data); err != nil {
+		return status.Errorf(codes.Internal, "Publish, failed to publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+func (r *redisPubSub) Subscribe(ctx context.Context, topic string, handler func(data []byte)) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "Subscribe, failed to connect to redis: %v", err)
+	}
+	// Closing a pooled connection unsubscribes it before it is reused.
+	defer handleConnectionClose(conn)
+
+	psc := redis.PubSubConn{Conn: conn}
+	if err = psc.Subscribe(topic); err != nil {
+		return status.Errorf(codes.Unavailable, "Subscribe, failed to subscribe to %s: %v", topic, err)
+	}
+
+	// Unsubscribing ends the receive loop below with a subscription count of
+	// zero.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := psc.Unsubscribe(topic); err != nil {
+				logger.WithFields(logrus.Fields{
+					"error": err.Error(),
+					"topic": topic,
+				}).Debug("failed to unsubscribe")
+			}
+		case <-done:
+		}
+	}()
+
+	for {
+		switch v := psc.ReceiveWithTimeout(0).(type) {
+		case redis.Message:
+			handler(v.Data)
+		case redis.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return status.Errorf(codes.Unavailable, "Subscribe, failed to receive from %s: %v", topic, v)
+		}
+	}
+}
+
+func (r *redisPubSub) Close() error {
+	return r.pool.Close()
+}
+
+func handleConnectionClose(conn redis.Conn) {
+	if err := conn.Close(); err != nil {
+		logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Debug("failed to close redis client connection.")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// publishingStore publishes the ticket events recorded in the state store.
+type publishingStore struct {
+	statestore.Service
+	events Service
+}
+
+// WithTicketEvents returns store, publishing the ticket events recorded with
+// RecordTicketEvent on events as well.  Publishing is best effort, so failures
+// are logged rather than returned.  store is returned as is if events is nil.
+func WithTicketEvents(store statestore.Service, events Service) statestore.Service {
+	if events == nil {
+		return store
+	}
+	return &publishingStore{Service: store, events: events}
+}
+
+func (s *publishingStore) RecordTicketEvent(ctx context.Context, ids []string, event *pb.TicketEvent) error {
+	err := s.Service.RecordTicketEvent(ctx, ids, event)
+	if perr := PublishTicketEvent(ctx, s.events, ids, event); perr != nil {
+		logger.WithFields(logrus.Fields{
+			"error":      perr.Error(),
+			"type":       event.GetType(),
+			"ticket_ids": ids,
+		}).Warning("failed to publish ticket event")
+	}
+	return err
+}
@@ -54,6 +54,21 @@ func TestInsecureGRPCFromConfig(t *testing.T) {
 	runSuccessGrpcClientTests(t, require, cfg, rpcParams)
 }
 
+func TestCompressedGRPCFromConfig(t *testing.T) {
+	for _, compression := range []string{"gzip", "snappy"} {
+		compression := compression
+		t.Run(compression, func(t *testing.T) {
+			require := require.New(t)
+
+			cfg, rpcParams, closer := configureConfigAndKeysForTesting(t, require, false, "localhost")
+			defer closer()
+			cfg.(*viper.Viper).Set(config.KeyRPCCompression, compression)
+
+			runSuccessGrpcClientTests(t, require, cfg, rpcParams)
+		})
+	}
+}
+
 func TestUnavailableGRPCFromConfig(t *testing.T) {
 	require := require.New(t)
 
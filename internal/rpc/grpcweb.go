@@ -0,0 +1,283 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+)
+
+const (
+	grpcContentType        = "application/grpc"
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// grpcWebTrailerFlag marks the final frame of a gRPC-Web response, which
+	// carries the gRPC trailers in the body as browsers can't read real ones.
+	grpcWebTrailerFlag = 0x80
+)
+
+var grpcWebAllowedHeaders = []string{
+	"Content-Type",
+	"Grpc-Timeout",
+	"X-Grpc-Web",
+	"X-User-Agent",
+}
+
+var grpcWebExposedHeaders = []string{
+	"Grpc-Status",
+	"Grpc-Message",
+	"Grpc-Status-Details-Bin",
+}
+
+// grpcWebHandler serves gRPC-Web requests directly from the gRPC server so
+// browser clients don't need a separate translating proxy.  Anything which
+// isn't gRPC-Web is passed through to next.
+type grpcWebHandler struct {
+	grpcServer     *grpc.Server
+	next           http.Handler
+	allowedOrigins map[string]struct{}
+	allowAny       bool
+}
+
+// newGrpcWebHandler returns next unchanged when gRPC-Web is disabled.
+func newGrpcWebHandler(params *ServerParams, grpcServer *grpc.Server, next http.Handler) http.Handler {
+	if !params.enableGrpcWeb {
+		return next
+	}
+	h := &grpcWebHandler{
+		grpcServer:     grpcServer,
+		next:           next,
+		allowedOrigins: map[string]struct{}{},
+	}
+	for _, o := range params.grpcWebAllowedOrigins {
+		if o == "*" {
+			h.allowAny = true
+		}
+		h.allowedOrigins[strings.ToLower(o)] = struct{}{}
+	}
+	return h
+}
+
+func (h *grpcWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		h.servePreflight(w, r)
+		return
+	}
+	if !isGrpcWebRequest(r) {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if !h.originAllowed(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(grpcWebExposedHeaders, ", "))
+		w.Header().Add("Vary", "Origin")
+	}
+
+	text := strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebTextContentType)
+	gw := newGrpcWebResponseWriter(w, text)
+	h.grpcServer.ServeHTTP(gw, asGrpcRequest(r, text))
+	gw.finish()
+}
+
+func (h *grpcWebHandler) servePreflight(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if !h.originAllowed(origin) {
+		// Let the proxy answer preflights that aren't meant for us.
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := grpcWebAllowedHeaders
+	if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		// Echo back requested headers, so clients can send their own metadata.
+		allowed = append(append([]string{}, allowed...), requested)
+	}
+
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Methods", http.MethodPost)
+	header.Set("Access-Control-Allow-Headers", strings.Join(allowed, ", "))
+	header.Set("Access-Control-Max-Age", "600")
+	header.Add("Vary", "Origin")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *grpcWebHandler) originAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if h.allowAny {
+		return true
+	}
+	_, ok := h.allowedOrigins[strings.ToLower(origin)]
+	return ok
+}
+
+func isGrpcWebRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// asGrpcRequest rewrites a gRPC-Web request into the form grpc.Server.ServeHTTP
+// expects.  gRPC-Web only supports unary and server streaming calls, so the
+// whole request body is available up front and HTTP/1.1 is sufficient.
+func asGrpcRequest(r *http.Request, text bool) *http.Request {
+	req := r.Clone(r.Context())
+	req.ProtoMajor = 2
+	req.ProtoMinor = 0
+
+	contentType := req.Header.Get("Content-Type")
+	if text {
+		contentType = strings.Replace(contentType, grpcWebTextContentType, grpcContentType, 1)
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{base64.NewDecoder(base64.StdEncoding, r.Body), r.Body}
+	} else {
+		contentType = strings.Replace(contentType, grpcWebContentType, grpcContentType, 1)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	return req
+}
+
+// grpcWebResponseWriter translates the response written by the gRPC server:
+// headers are sent as is, but trailers are buffered and written as a final
+// length prefixed frame once the call is complete.
+type grpcWebResponseWriter struct {
+	w             http.ResponseWriter
+	header        http.Header
+	text          bool
+	wroteHeader   bool
+	code          int
+	contentType   string
+	headerAtWrite map[string]struct{}
+}
+
+func newGrpcWebResponseWriter(w http.ResponseWriter, text bool) *grpcWebResponseWriter {
+	contentType := grpcWebContentType + "+proto"
+	if text {
+		contentType = grpcWebTextContentType + "+proto"
+	}
+	return &grpcWebResponseWriter{
+		w:           w,
+		header:      http.Header{},
+		text:        text,
+		contentType: contentType,
+	}
+}
+
+func (gw *grpcWebResponseWriter) Header() http.Header {
+	return gw.header
+}
+
+func (gw *grpcWebResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	gw.code = code
+
+	gw.headerAtWrite = map[string]struct{}{}
+	out := gw.w.Header()
+	for k, vv := range gw.header {
+		if k == "Trailer" || strings.HasPrefix(k, http2.TrailerPrefix) {
+			continue
+		}
+		gw.headerAtWrite[k] = struct{}{}
+		out[k] = vv
+	}
+	out.Set("Content-Type", gw.contentType)
+	// The response is streamed, so its length isn't known.
+	out.Del("Content-Length")
+	gw.w.WriteHeader(code)
+}
+
+func (gw *grpcWebResponseWriter) Write(b []byte) (int, error) {
+	gw.WriteHeader(http.StatusOK)
+	if gw.text {
+		if _, err := gw.w.Write([]byte(base64.StdEncoding.EncodeToString(b))); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return gw.w.Write(b)
+}
+
+func (gw *grpcWebResponseWriter) Flush() {
+	gw.WriteHeader(http.StatusOK)
+	if f, ok := gw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailer frame.  If the gRPC server rejected the request
+// outright, eg because it was malformed, its plain HTTP error is already the
+// whole response.
+func (gw *grpcWebResponseWriter) finish() {
+	gw.WriteHeader(http.StatusOK)
+	if gw.code != http.StatusOK {
+		return
+	}
+
+	trailers := http.Header{}
+	for _, k := range gw.header.Values("Trailer") {
+		k = http.CanonicalHeaderKey(strings.TrimSpace(k))
+		if vv, ok := gw.header[k]; ok {
+			if _, sent := gw.headerAtWrite[k]; !sent {
+				trailers[k] = vv
+			}
+		}
+	}
+	for k, vv := range gw.header {
+		if strings.HasPrefix(k, http2.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(k, http2.TrailerPrefix))] = vv
+		}
+	}
+
+	var body bytes.Buffer
+	for k, vv := range trailers {
+		for _, v := range vv {
+			body.WriteString(strings.ToLower(k))
+			body.WriteString(": ")
+			body.WriteString(v)
+			body.WriteString("\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+body.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(body.Len()))
+	frame = append(frame, body.Bytes()...)
+
+	if _, err := gw.Write(frame); err != nil {
+		serverLogger.WithError(err).Debug("error writing gRPC-Web trailers")
+		return
+	}
+	gw.Flush()
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	shellTesting "open-match.dev/open-match/internal/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+const testGrpcWebOrigin = "https://game.example.com"
+
+func startGrpcWebServer(t *testing.T) (string, func()) {
+	grpcL := MustListen()
+	httpL := MustListen()
+	ff := &shellTesting.FakeFrontend{}
+
+	params := NewServerParamsFromListeners(grpcL, httpL)
+	params.enableGrpcWeb = true
+	params.grpcWebAllowedOrigins = []string{testGrpcWebOrigin}
+	params.AddHandleFunc(func(s *grpc.Server) {
+		pb.RegisterFrontendServiceServer(s, ff)
+	}, pb.RegisterFrontendServiceHandlerFromEndpoint)
+	s := newInsecureServer(grpcL, httpL)
+	require.NoError(t, s.start(params))
+
+	return fmt.Sprintf("http://localhost:%s", MustGetPortNumber(httpL)), func() { s.stop() }
+}
+
+func grpcWebFrame(flag byte, msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// readGrpcWebFrames splits a gRPC-Web response body into its messages and trailers.
+func readGrpcWebFrames(t *testing.T, body []byte) ([][]byte, string) {
+	var msgs [][]byte
+	for len(body) > 0 {
+		require.True(t, len(body) >= 5)
+		flag := body[0]
+		n := binary.BigEndian.Uint32(body[1:5])
+		require.True(t, len(body) >= 5+int(n))
+		data := body[5 : 5+n]
+		body = body[5+n:]
+		if flag == grpcWebTrailerFlag {
+			require.Empty(t, body, "trailers must be the final frame")
+			return msgs, string(data)
+		}
+		msgs = append(msgs, data)
+	}
+	t.Fatal("missing trailer frame")
+	return nil, ""
+}
+
+func doGrpcWeb(t *testing.T, endpoint, method, contentType string, req proto.Message) (*http.Response, []byte) {
+	msg, err := proto.Marshal(req)
+	require.NoError(t, err)
+	body := grpcWebFrame(0, msg)
+	if strings.HasPrefix(contentType, grpcWebTextContentType) {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint+method, bytes.NewReader(body))
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Origin", testGrpcWebOrigin)
+	httpReq.Header.Set("X-Grpc-Web", "1")
+
+	httpResp, err := (&http.Client{Timeout: time.Second}).Do(httpReq)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	require.NoError(t, err)
+	return httpResp, respBody
+}
+
+func TestGrpcWeb(t *testing.T) {
+	endpoint, stop := startGrpcWebServer(t)
+	defer stop()
+
+	resp, body := doGrpcWeb(t, endpoint, "/openmatch.FrontendService/CreateTicket", "application/grpc-web+proto", &pb.CreateTicketRequest{})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/grpc-web+proto", resp.Header.Get("Content-Type"))
+	require.Equal(t, testGrpcWebOrigin, resp.Header.Get("Access-Control-Allow-Origin"))
+	msgs, trailers := readGrpcWebFrames(t, body)
+	require.Len(t, msgs, 1)
+	require.NoError(t, proto.Unmarshal(msgs[0], &pb.Ticket{}))
+	require.Contains(t, trailers, "grpc-status: 0\r\n")
+
+	resp, body = doGrpcWeb(t, endpoint, "/openmatch.FrontendService/GetTicket", "application/grpc-web", &pb.GetTicketRequest{TicketId: "1"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	msgs, trailers = readGrpcWebFrames(t, body)
+	require.Empty(t, msgs)
+	require.Contains(t, trailers, "grpc-status: 12\r\n")
+	require.Contains(t, trailers, "grpc-message: not implemented\r\n")
+}
+
+func TestGrpcWebText(t *testing.T) {
+	endpoint, stop := startGrpcWebServer(t)
+	defer stop()
+
+	resp, body := doGrpcWeb(t, endpoint, "/openmatch.FrontendService/CreateTicket", "application/grpc-web-text", &pb.CreateTicketRequest{})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/grpc-web-text+proto", resp.Header.Get("Content-Type"))
+
+	// Each write is encoded separately, so decode one padded chunk at a time.
+	var decoded []byte
+	for len(body) > 0 {
+		end := bytes.IndexByte(body, '=')
+		for end >= 0 && end+1 < len(body) && body[end+1] == '=' {
+			end++
+		}
+		chunk := body
+		if end >= 0 {
+			chunk, body = body[:end+1], body[end+1:]
+		} else {
+			body = nil
+		}
+		b, err := base64.StdEncoding.DecodeString(string(chunk))
+		require.NoError(t, err)
+		decoded = append(decoded, b...)
+	}
+
+	msgs, trailers := readGrpcWebFrames(t, decoded)
+	require.Len(t, msgs, 1)
+	require.Contains(t, trailers, "grpc-status: 0\r\n")
+}
+
+func TestGrpcWebCORS(t *testing.T) {
+	endpoint, stop := startGrpcWebServer(t)
+	defer stop()
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, endpoint+"/openmatch.FrontendService/CreateTicket", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "x-custom")
+		resp, err := (&http.Client{Timeout: time.Second}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := preflight(testGrpcWebOrigin)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, testGrpcWebOrigin, resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, http.MethodPost, resp.Header.Get("Access-Control-Allow-Methods"))
+	require.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "x-custom")
+
+	resp = preflight("https://other.example.com")
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	req, err := http.NewRequest(http.MethodPost, endpoint+"/openmatch.FrontendService/CreateTicket", bytes.NewReader(grpcWebFrame(0, nil)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Origin", "https://other.example.com")
+	resp, err = (&http.Client{Timeout: time.Second}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
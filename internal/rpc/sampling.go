@@ -0,0 +1,186 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// ConfigNameRPCSamplingFraction is the fraction of gRPC calls, between 0
+	// and 1, whose request and response payloads are logged.
+	ConfigNameRPCSamplingFraction = "logging.sampling.fraction"
+	// ConfigNameRPCSamplingMethods optionally restricts payload sampling to
+	// these full method names, eg "/openmatch.BackendService/FetchMatches".
+	ConfigNameRPCSamplingMethods = "logging.sampling.methods"
+)
+
+var (
+	samplingLogger = logrus.WithFields(logrus.Fields{
+		"app":       "openmatch",
+		"component": "grpc.sampling",
+	})
+
+	// redactedFields hold customer defined data which may be large or
+	// sensitive, so they are cleared, at any depth, from sampled payloads.
+	redactedFields = map[protoreflect.Name]bool{
+		"extensions":       true,
+		"persistent_field": true,
+	}
+)
+
+// payloadSampler logs the payloads of a random sample of gRPC calls, so that
+// incidents such as "which profile caused this" can be reproduced without
+// logging every call.
+type payloadSampler struct {
+	fraction float64
+	// methods to sample, all methods are sampled when empty.
+	methods map[string]bool
+	random  func() float64
+}
+
+func newPayloadSampler(fraction float64, methods []string) *payloadSampler {
+	s := &payloadSampler{
+		fraction: fraction,
+		methods:  make(map[string]bool),
+		random:   rand.Float64,
+	}
+	for _, m := range methods {
+		s.methods[m] = true
+	}
+	return s
+}
+
+func (s *payloadSampler) sampled(method string) bool {
+	if len(s.methods) > 0 && !s.methods[method] {
+		return false
+	}
+	return s.random() < s.fraction
+}
+
+func (s *payloadSampler) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !s.sampled(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	logPayload(info.FullMethod, "request", req)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		samplingLogger.WithFields(logrus.Fields{
+			"grpc.method": info.FullMethod,
+			"error":       err.Error(),
+		}).Info("Sampled gRPC call failed.")
+	} else {
+		logPayload(info.FullMethod, "response", resp)
+	}
+	return resp, err
+}
+
+func (s *payloadSampler) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !s.sampled(info.FullMethod) {
+		return handler(srv, stream)
+	}
+
+	err := handler(srv, &sampledServerStream{ServerStream: stream, method: info.FullMethod})
+	if err != nil {
+		samplingLogger.WithFields(logrus.Fields{
+			"grpc.method": info.FullMethod,
+			"error":       err.Error(),
+		}).Info("Sampled gRPC call failed.")
+	}
+	return err
+}
+
+// sampledServerStream logs every message received and sent on a sampled
+// stream.
+type sampledServerStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *sampledServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		logPayload(s.method, "request", m)
+	}
+	return err
+}
+
+func (s *sampledServerStream) SendMsg(m interface{}) error {
+	logPayload(s.method, "response", m)
+	return s.ServerStream.SendMsg(m)
+}
+
+func logPayload(method, kind string, payload interface{}) {
+	m, ok := payload.(proto.Message)
+	if !ok {
+		return
+	}
+
+	b, err := protojson.Marshal(redact(m))
+	if err != nil {
+		samplingLogger.WithError(err).Warning("failed to marshal sampled payload")
+		return
+	}
+
+	samplingLogger.WithFields(logrus.Fields{
+		"grpc.method":  method,
+		"grpc." + kind: string(b),
+	}).Infof("Sampled gRPC %s.", kind)
+}
+
+// redact returns a copy of m with the redacted fields cleared.
+func redact(m proto.Message) proto.Message {
+	m = proto.Clone(m)
+	redactMessage(m.ProtoReflect())
+	return m
+}
+
+func redactMessage(m protoreflect.Message) {
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case redactedFields[fd.Name()]:
+			cleared = append(cleared, fd)
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactMessage(mv.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				l := v.List()
+				for i := 0; i < l.Len(); i++ {
+					redactMessage(l.Get(i).Message())
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message())
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
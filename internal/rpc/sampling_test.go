@@ -0,0 +1,62 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestRedact(t *testing.T) {
+	ext := map[string]*any.Any{"secret": {TypeUrl: "type.googleapis.com/secret"}}
+	in := &pb.FetchMatchesResponse{
+		Match: &pb.Match{
+			MatchId:    "1",
+			Extensions: ext,
+			Tickets: []*pb.Ticket{
+				{Id: "a", Extensions: ext, PersistentField: ext},
+			},
+			Backfill: &pb.Backfill{Id: "b", Extensions: ext},
+		},
+	}
+	original := proto.Clone(in)
+
+	got := redact(in)
+
+	require.True(t, proto.Equal(&pb.FetchMatchesResponse{
+		Match: &pb.Match{
+			MatchId:  "1",
+			Tickets:  []*pb.Ticket{{Id: "a"}},
+			Backfill: &pb.Backfill{Id: "b"},
+		},
+	}, got), got)
+	require.True(t, proto.Equal(original, in), "redact must not modify its input")
+}
+
+func TestPayloadSamplerSampled(t *testing.T) {
+	s := newPayloadSampler(0.5, nil)
+	s.random = func() float64 { return 0.4 }
+	require.True(t, s.sampled("/openmatch.BackendService/FetchMatches"))
+	s.random = func() float64 { return 0.6 }
+	require.False(t, s.sampled("/openmatch.BackendService/FetchMatches"))
+
+	s = newPayloadSampler(1, []string{"/openmatch.BackendService/FetchMatches"})
+	require.True(t, s.sampled("/openmatch.BackendService/FetchMatches"))
+	require.False(t, s.sampled("/openmatch.FrontendService/CreateTicket"))
+}
@@ -39,12 +39,17 @@ import (
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/logging"
 	"open-match.dev/open-match/internal/telemetry"
+	"open-match.dev/open-match/pkg/errorinfo"
 )
 
 const (
 	configNameServerPublicCertificateFile = "api.tls.certificateFile"
 	configNameServerPrivateKeyFile        = "api.tls.privateKey"
 	configNameServerRootCertificatePath   = "api.tls.rootCertificateFile"
+
+	// Per-service gRPC-Web settings, eg "api.frontend.grpcweb.enabled".
+	configNameGrpcWebEnabled        = "grpcweb.enabled"
+	configNameGrpcWebAllowedOrigins = "grpcweb.allowedOrigins"
 )
 
 var (
@@ -83,6 +88,16 @@ type ServerParams struct {
 	enableRPCLogging        bool
 	enableRPCPayloadLogging bool
 	enableMetrics           bool
+
+	// rpcSamplingFraction of calls have their payloads logged, optionally
+	// only for rpcSamplingMethods.
+	rpcSamplingFraction float64
+	rpcSamplingMethods  []string
+
+	// enableGrpcWeb serves gRPC-Web from the HTTP port, for browser clients.
+	enableGrpcWeb bool
+	// grpcWebAllowedOrigins are the CORS origins gRPC-Web may be called from, "*" allows any.
+	grpcWebAllowedOrigins []string
 }
 
 // NewServerParamsFromConfig returns server Params initialized from the configuration file.
@@ -137,6 +152,10 @@ func NewServerParamsFromConfig(cfg config.View, prefix string, listen func(netwo
 	p.enableMetrics = cfg.GetBool(telemetry.ConfigNameEnableMetrics)
 	p.enableRPCLogging = cfg.GetBool(ConfigNameEnableRPCLogging)
 	p.enableRPCPayloadLogging = logging.IsDebugEnabled(cfg)
+	p.rpcSamplingFraction = cfg.GetFloat64(ConfigNameRPCSamplingFraction)
+	p.rpcSamplingMethods = cfg.GetStringSlice(ConfigNameRPCSamplingMethods)
+	p.enableGrpcWeb = cfg.GetBool(prefix + "." + configNameGrpcWebEnabled)
+	p.grpcWebAllowedOrigins = cfg.GetStringSlice(prefix + "." + configNameGrpcWebAllowedOrigins)
 
 	return p, nil
 }
@@ -288,6 +307,13 @@ func newGRPCServerOptions(params *ServerParams) []grpc.ServerOption {
 		}
 	}
 
+	// Sampling is redundant when every payload is already logged.
+	if params.rpcSamplingFraction > 0 && !(params.enableRPCLogging && params.enableRPCPayloadLogging) {
+		sampler := newPayloadSampler(params.rpcSamplingFraction, params.rpcSamplingMethods)
+		si = append(si, sampler.streamInterceptor)
+		ui = append(ui, sampler.unaryInterceptor)
+	}
+
 	ui = append(ui, serverUnaryInterceptor)
 	si = append(si, serverStreamInterceptor)
 
@@ -314,7 +340,7 @@ func serverStreamInterceptor(srv interface{},
 	if err != nil {
 		serverLogger.Error(err)
 	}
-	return err
+	return errorinfo.WithDefault(err)
 }
 
 func serverUnaryInterceptor(ctx context.Context,
@@ -325,5 +351,5 @@ func serverUnaryInterceptor(ctx context.Context,
 	if err != nil {
 		serverLogger.Error(err)
 	}
-	return h, err
+	return h, errorinfo.WithDefault(err)
 }
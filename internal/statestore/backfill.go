@@ -28,6 +28,7 @@ import (
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/ipb"
+	"open-match.dev/open-match/pkg/errorinfo"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -45,7 +46,7 @@ const (
 
 // CreateBackfill creates a new Backfill in the state storage if one doesn't exist. The xids algorithm used to create the ids ensures that they are unique with no system wide synchronization. Calling clients are forbidden from choosing an id during create. So no conflicts will occur.
 func (rb *redisBackend) CreateBackfill(ctx context.Context, backfill *pb.Backfill, ticketIDs []string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "CreateBackfill, id: %s, failed to connect to redis: %v", backfill.GetId(), err)
 	}
@@ -77,7 +78,7 @@ func (rb *redisBackend) CreateBackfill(ctx context.Context, backfill *pb.Backfil
 
 // GetBackfill gets the Backfill with the specified id from state storage. This method fails if the Backfill does not exist. Returns the Backfill and associated ticketIDs if they exist.
 func (rb *redisBackend) GetBackfill(ctx context.Context, id string) (*pb.Backfill, []string, error) {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, nil, status.Errorf(codes.Unavailable, "GetBackfill, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -87,7 +88,7 @@ func (rb *redisBackend) GetBackfill(ctx context.Context, id string) (*pb.Backfil
 	if err != nil {
 		// Return NotFound if redigo did not find the backfill in storage.
 		if err == redis.ErrNil {
-			return nil, nil, status.Errorf(codes.NotFound, "Backfill id: %s not found", id)
+			return nil, nil, backfillNotFound(id)
 		}
 
 		err = errors.Wrapf(err, "failed to get the backfill from state storage, id: %s", id)
@@ -95,7 +96,7 @@ func (rb *redisBackend) GetBackfill(ctx context.Context, id string) (*pb.Backfil
 	}
 
 	if value == nil {
-		return nil, nil, status.Errorf(codes.NotFound, "Backfill id: %s not found", id)
+		return nil, nil, backfillNotFound(id)
 	}
 
 	bi := &ipb.BackfillInternal{}
@@ -114,7 +115,7 @@ func (rb *redisBackend) GetBackfills(ctx context.Context, ids []string) ([]*pb.B
 		return nil, nil
 	}
 
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetBackfills, failed to connect to redis: %v", err)
 	}
@@ -166,7 +167,7 @@ func (rb *redisBackend) GetBackfills(ctx context.Context, ids []string) ([]*pb.B
 
 // DeleteBackfill removes the Backfill with the specified id from state storage.
 func (rb *redisBackend) DeleteBackfill(ctx context.Context, id string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "DeleteBackfill, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -179,7 +180,7 @@ func (rb *redisBackend) DeleteBackfill(ctx context.Context, id string) error {
 	}
 
 	if value == 0 {
-		return status.Errorf(codes.NotFound, "Backfill id: %s not found", id)
+		return backfillNotFound(id)
 	}
 
 	return rb.deleteExpiredBackfillID(redisConn, id)
@@ -187,7 +188,7 @@ func (rb *redisBackend) DeleteBackfill(ctx context.Context, id string) error {
 
 // UpdateBackfill updates an existing Backfill with a new data. ticketIDs can be nil.
 func (rb *redisBackend) UpdateBackfill(ctx context.Context, backfill *pb.Backfill, ticketIDs []string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "UpdateBackfill, id: %s, failed to connect to redis: %v", backfill.GetId(), err)
 	}
@@ -310,7 +311,8 @@ func (rb *redisBackend) CleanupBackfills(ctx context.Context) error {
 	wg.Add(len(expiredBfIDs))
 	backfillIDsCh := make(chan string, len(expiredBfIDs))
 
-	for w := 1; w <= 3; w++ {
+	workers := config.GetStateStore(rb.cfg).BackfillCleanupConcurrency
+	for w := 0; w < workers && w < len(expiredBfIDs); w++ {
 		go rb.cleanupWorker(ctx, backfillIDsCh, &wg)
 	}
 
@@ -326,7 +328,7 @@ func (rb *redisBackend) CleanupBackfills(ctx context.Context) error {
 // UpdateAcknowledgmentTimestamp stores Backfill's last acknowledgement time.
 // Check on Backfill existence should be performed on Frontend side
 func (rb *redisBackend) UpdateAcknowledgmentTimestamp(ctx context.Context, id string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "UpdateAcknowledgmentTimestamp, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -358,7 +360,7 @@ func doUpdateAcknowledgmentTimestamp(conn redis.Conn, backfillID string) error {
 
 // GetExpiredBackfillIDs gets all backfill IDs which are expired
 func (rb *redisBackend) GetExpiredBackfillIDs(ctx context.Context) ([]string, error) {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetExpiredBackfillIDs, failed to connect to redis: %v", err)
 	}
@@ -391,7 +393,7 @@ func (rb *redisBackend) deleteExpiredBackfillID(conn redis.Conn, backfillID stri
 
 // IndexBackfill adds the backfill to the index.
 func (rb *redisBackend) IndexBackfill(ctx context.Context, backfill *pb.Backfill) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "IndexBackfill, id: %s, failed to connect to redis: %v", backfill.GetId(), err)
 	}
@@ -408,7 +410,7 @@ func (rb *redisBackend) IndexBackfill(ctx context.Context, backfill *pb.Backfill
 
 // DeindexBackfill removes specified Backfill ID from the index. The Backfill continues to exist.
 func (rb *redisBackend) DeindexBackfill(ctx context.Context, id string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "DeindexBackfill, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -425,7 +427,7 @@ func (rb *redisBackend) DeindexBackfill(ctx context.Context, id string) error {
 
 // GetIndexedBackfills returns the ids of all backfills currently indexed.
 func (rb *redisBackend) GetIndexedBackfills(ctx context.Context) (map[string]int, error) {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetIndexedBackfills, failed to connect to redis: %v", err)
 	}
@@ -464,6 +466,10 @@ func (rb *redisBackend) GetIndexedBackfills(ctx context.Context) (map[string]int
 
 func getBackfillReleaseTimeout(cfg config.View) time.Duration {
 	// Use a fraction 80% of pendingRelease Tickets TTL
-	ttl := cfg.GetDuration("pendingReleaseTimeout") / 5 * 4
+	ttl := config.GetStateStore(cfg).PendingReleaseTimeout / 5 * 4
 	return ttl
 }
+
+func backfillNotFound(id string) error {
+	return errorinfo.Errorf(codes.NotFound, errorinfo.ReasonBackfillNotFound, map[string]string{errorinfo.MetadataBackfillID: id}, "Backfill id: %s not found", id)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+const allServerCapacity = "allServerCapacity"
+
+// UpdateServerCapacity stores the capacity for its region and fleet, replacing any previous value.
+func (rb *redisBackend) UpdateServerCapacity(ctx context.Context, capacity *pb.ServerCapacity) error {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "UpdateServerCapacity, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	value, err := proto.Marshal(capacity)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal the server capacity proto, region: %s, fleet: %s", capacity.GetRegion(), capacity.GetFleet())
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	_, err = redisConn.Do("HSET", allServerCapacity, serverCapacityField(capacity), value)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to set the server capacity, region: %s, fleet: %s", capacity.GetRegion(), capacity.GetFleet())
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return nil
+}
+
+// GetServerCapacity returns the unexpired capacity for the region, or for all regions if region is empty.
+// Expired capacity is removed from state storage.
+func (rb *redisBackend) GetServerCapacity(ctx context.Context, region string) ([]*pb.ServerCapacity, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetServerCapacity, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	values, err := redis.StringMap(redisConn.Do("HGETALL", allServerCapacity))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting all server capacity %v", err)
+	}
+
+	expireBefore := time.Now().Add(-config.GetStateStore(rb.cfg).ServerCapacityTimeout)
+	var expired []interface{}
+	capacities := make([]*pb.ServerCapacity, 0, len(values))
+	for field, value := range values {
+		capacity := &pb.ServerCapacity{}
+		err = proto.Unmarshal([]byte(value), capacity)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to unmarshal the server capacity proto, field: %s", field)
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+
+		updateTime, err := ptypes.Timestamp(capacity.GetUpdateTime())
+		if err != nil || updateTime.Before(expireBefore) {
+			expired = append(expired, field)
+			continue
+		}
+
+		if region == "" || capacity.GetRegion() == region {
+			capacities = append(capacities, capacity)
+		}
+	}
+
+	if len(expired) > 0 {
+		_, err = redisConn.Do("HDEL", append([]interface{}{allServerCapacity}, expired...)...)
+		if err != nil {
+			// Expired capacity is filtered on every read, so failing to delete it is harmless.
+			logger.WithError(err).Warning("failed to delete expired server capacity")
+		}
+	}
+
+	return capacities, nil
+}
+
+func serverCapacityField(capacity *pb.ServerCapacity) string {
+	return fmt.Sprintf("%q/%q", capacity.GetRegion(), capacity.GetFleet())
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestServerCapacity(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	newCapacity := func(region, fleet string, available int32, age time.Duration) *pb.ServerCapacity {
+		ts, err := ptypes.TimestampProto(time.Now().Add(-age))
+		require.NoError(t, err)
+		return &pb.ServerCapacity{Region: region, Fleet: fleet, Available: available, UpdateTime: ts}
+	}
+
+	west := newCapacity("us-west1", "a", 3, 0)
+	east := newCapacity("us-east1", "a", 5, 0)
+	expired := newCapacity("us-east1", "b", 7, 2*time.Minute)
+	for _, c := range []*pb.ServerCapacity{newCapacity("us-west1", "a", 1, 0), west, east, expired} {
+		require.NoError(t, service.UpdateServerCapacity(ctx, c))
+	}
+
+	got, err := service.GetServerCapacity(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, want := range []*pb.ServerCapacity{west, east} {
+		found := false
+		for _, c := range got {
+			found = found || proto.Equal(want, c)
+		}
+		require.True(t, found, "missing %v", want)
+	}
+
+	got, err = service.GetServerCapacity(ctx, "us-east1")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.True(t, proto.Equal(east, got[0]))
+
+	// Expired capacity is removed from storage when read.
+	n, err := GetRedisPool(cfg).Get().Do("HLEN", allServerCapacity)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+}
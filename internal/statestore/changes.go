@@ -0,0 +1,332 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+const (
+	// ticketChangeStream is a redis stream of the changes to the active
+	// tickets, recorded in the same transaction as the changes.
+	ticketChangeStream = "ticket_changes"
+	// maxTicketChanges is the most changes returned by one ReadTicketChanges.
+	maxTicketChanges = 10000
+)
+
+// Types of the changes on the ticket change stream.
+const (
+	// TicketIndexed adds TicketChange.Ticket to the index.
+	TicketIndexed = "indexed"
+	// TicketsDeindexed removes TicketChange.TicketIDs from the index.
+	TicketsDeindexed = "deindexed"
+	// TicketsPending makes TicketChange.TicketIDs pending release from
+	// TicketChange.Time, until pendingReleaseTimeout passes.
+	TicketsPending = "pending"
+	// TicketsReleased makes TicketChange.TicketIDs no longer pending.
+	TicketsReleased = "released"
+	// AllTicketsReleased makes every ticket no longer pending.
+	AllTicketsReleased = "releasedAll"
+	// TicketChangeMark marks the position of a snapshot, and changes nothing.
+	TicketChangeMark = "mark"
+)
+
+// TicketChange is a change to the active tickets, read from the ticket change
+// stream.
+type TicketChange struct {
+	// Position is the position of the change in the stream.
+	Position string
+	// Type is one of TicketIndexed, TicketsDeindexed, TicketsPending,
+	// TicketsReleased, AllTicketsReleased or TicketChangeMark.
+	Type string
+	// Ticket is the indexed ticket.
+	Ticket *pb.Ticket
+	// TicketIDs are the tickets changed.
+	TicketIDs []string
+	// Time is when the tickets were proposed.
+	Time time.Time
+}
+
+// TicketSnapshot is the ticket index at a position of the ticket change
+// stream.
+type TicketSnapshot struct {
+	// Position is the position of the stream the snapshot was taken at.
+	// Changes after it are not in the snapshot.
+	Position string
+	// Indexed are the indexed tickets, including those pending release.
+	Indexed []*pb.Ticket
+	// Pending are the times the tickets pending release were proposed.
+	Pending map[string]time.Time
+}
+
+// sendTicketChange queues a change on the ticket change stream, if it is
+// enabled, on a connection in MULTI.  args are the fields of the change.
+func (rb *redisBackend) sendTicketChange(redisConn redis.Conn, changeType string, args ...interface{}) error {
+	store := config.GetStateStore(rb.cfg)
+	if !store.TicketChangeStream {
+		return nil
+	}
+	cmd := append([]interface{}{ticketChangeStream, "MAXLEN", "~", store.TicketChangeStreamMaxLen, "*", "type", changeType}, args...)
+	err := redisConn.Send("XADD", cmd...)
+	if err != nil {
+		return errors.Wrapf(err, "error sending %s ticket change", changeType)
+	}
+	return nil
+}
+
+// ticketIDFields returns an "id" field for each of the ids.
+func ticketIDFields(ids []string) []interface{} {
+	fields := make([]interface{}, 0, 2*len(ids))
+	for _, id := range ids {
+		fields = append(fields, "id", id)
+	}
+	return fields
+}
+
+// SnapshotTickets returns the ticket index, and the position of the ticket
+// change stream to follow it from.  Taking a snapshot adds a mark to the
+// stream, so that the position stays readable until the stream is trimmed
+// past it.
+func (rb *redisBackend) SnapshotTickets(ctx context.Context) (*TicketSnapshot, error) {
+	store := config.GetStateStore(rb.cfg)
+	if !store.TicketChangeStream {
+		return nil, status.Errorf(codes.FailedPrecondition, "SnapshotTickets, %s is not set", config.KeyTicketChangeStream)
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "SnapshotTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting redis multi")
+	}
+	err = rb.sendTicketChange(redisConn, TicketChangeMark)
+	if err != nil {
+		return nil, err
+	}
+	err = redisConn.Send("SMEMBERS", allTickets)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending indexed tickets read")
+	}
+	err = redisConn.Send("ZRANGEBYSCORE", proposedTicketIDs, time.Now().Add(-store.PendingReleaseTimeout).UnixNano(), "+inf", "WITHSCORES")
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending pending tickets read")
+	}
+	replies, err := redis.Values(redisConn.Do("EXEC"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to snapshot the ticket index: %v", err)
+	}
+
+	position, err := redis.String(replies[0], nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error reading the ticket change stream position: %v", err)
+	}
+	ids, err := redis.Strings(replies[1], nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting all indexed ticket ids %v", err)
+	}
+	values, err := redis.Strings(replies[2], nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting pending release %v", err)
+	}
+
+	snapshot := &TicketSnapshot{
+		Position: position,
+		Pending:  make(map[string]time.Time, len(values)/2),
+	}
+	for i := 0; i+1 < len(values); i += 2 {
+		proposed, err := strconv.ParseInt(values[i+1], 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error while parsing proposed time into number: %v", err)
+		}
+		snapshot.Pending[values[i]] = time.Unix(0, proposed)
+	}
+
+	snapshot.Indexed, err = rb.GetTickets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ReadTicketChanges returns the changes after the position, waiting up to
+// wait for one if there are none.  Returns OutOfRange if the stream has been
+// trimmed past the position, as changes may have been missed.
+func (rb *redisBackend) ReadTicketChanges(ctx context.Context, after string, wait time.Duration) ([]*TicketChange, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ReadTicketChanges, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	changes, err := readTicketChanges(redisConn, after)
+	if err != nil || len(changes) > 0 || wait <= 0 {
+		return changes, err
+	}
+
+	// Block until there is a change, then read it with the trim check.
+	_, err = redis.DoWithTimeout(redisConn, wait+time.Second, "XREAD", "COUNT", 1, "BLOCK", wait.Milliseconds(), "STREAMS", ticketChangeStream, after)
+	if err != nil && err != redis.ErrNil {
+		return nil, status.Errorf(codes.Internal, "error waiting for ticket changes: %v", err)
+	}
+	return readTicketChanges(redisConn, after)
+}
+
+// readTicketChanges reads the changes after the position, and the first
+// change in the stream in the same transaction to check for a trim past it.
+func readTicketChanges(redisConn redis.Conn, after string) ([]*TicketChange, error) {
+	err := redisConn.Send("MULTI")
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting redis multi")
+	}
+	err = redisConn.Send("XRANGE", ticketChangeStream, "-", "+", "COUNT", 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending ticket change stream start read")
+	}
+	err = redisConn.Send("XREAD", "COUNT", maxTicketChanges, "STREAMS", ticketChangeStream, after)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending ticket changes read")
+	}
+	replies, err := redis.Values(redisConn.Do("EXEC"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read ticket changes: %v", err)
+	}
+
+	first, err := parseStreamEntries(replies[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(first) == 0 || compareStreamIDs(first[0].id, after) > 0 {
+		return nil, status.Errorf(codes.OutOfRange, "ticket change stream has been trimmed past %s", after)
+	}
+
+	if replies[1] == nil {
+		return nil, nil
+	}
+	streams, err := redis.Values(replies[1], nil)
+	if err != nil || len(streams) != 1 {
+		return nil, status.Errorf(codes.Internal, "unexpected ticket changes reply: %v", err)
+	}
+	stream, err := redis.Values(streams[0], nil)
+	if err != nil || len(stream) != 2 {
+		return nil, status.Errorf(codes.Internal, "unexpected ticket changes reply: %v", err)
+	}
+	entries, err := parseStreamEntries(stream[1])
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*TicketChange, 0, len(entries))
+	for _, e := range entries {
+		c, err := parseTicketChange(e)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+type streamEntry struct {
+	id     string
+	fields []string
+}
+
+func parseStreamEntries(reply interface{}) ([]streamEntry, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unexpected ticket change stream reply: %v", err)
+	}
+	entries := make([]streamEntry, 0, len(values))
+	for _, v := range values {
+		entry, err := redis.Values(v, nil)
+		if err != nil || len(entry) != 2 {
+			return nil, status.Errorf(codes.Internal, "unexpected ticket change stream entry: %v", err)
+		}
+		id, err := redis.String(entry[0], nil)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unexpected ticket change stream entry id: %v", err)
+		}
+		fields, err := redis.Strings(entry[1], nil)
+		if err != nil || len(fields)%2 != 0 {
+			return nil, status.Errorf(codes.Internal, "unexpected ticket change stream entry fields: %v", err)
+		}
+		entries = append(entries, streamEntry{id: id, fields: fields})
+	}
+	return entries, nil
+}
+
+func parseTicketChange(e streamEntry) (*TicketChange, error) {
+	c := &TicketChange{Position: e.id}
+	for i := 0; i < len(e.fields); i += 2 {
+		value := e.fields[i+1]
+		switch e.fields[i] {
+		case "type":
+			c.Type = value
+		case "id":
+			c.TicketIDs = append(c.TicketIDs, value)
+		case "time":
+			nanos, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "error parsing ticket change %s time: %v", e.id, err)
+			}
+			c.Time = time.Unix(0, nanos)
+		case "ticket":
+			c.Ticket = &pb.Ticket{}
+			if err := unmarshalTicket([]byte(value), c.Ticket); err != nil {
+				err = errors.Wrapf(err, "failed to unmarshal the ticket proto of ticket change %s", e.id)
+				return nil, status.Errorf(codes.Internal, "%v", err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// compareStreamIDs compares redis stream ids, "<ms>-<seq>".
+func compareStreamIDs(a, b string) int {
+	am, as := splitStreamID(a)
+	bm, bs := splitStreamID(b)
+	switch {
+	case am < bm || (am == bm && as < bs):
+		return -1
+	case am == bm && as == bs:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func splitStreamID(id string) (uint64, uint64) {
+	parts := strings.SplitN(id, "-", 2)
+	ms, _ := strconv.ParseUint(parts[0], 10, 64)
+	var seq uint64
+	if len(parts) == 2 {
+		seq, _ = strconv.ParseUint(parts[1], 10, 64)
+	}
+	return ms, seq
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketChanges(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	cfg.(*viper.Viper).Set(config.KeyTicketChangeStream, true)
+	cfg.(*viper.Viper).Set("pendingReleaseTimeout", "1m")
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	for _, id := range []string{"a", "b"} {
+		ticket := &pb.Ticket{Id: id}
+		require.NoError(t, service.CreateTicket(ctx, ticket))
+		require.NoError(t, service.IndexTicket(ctx, ticket))
+	}
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{{Tickets: []*pb.Ticket{{Id: "b"}}}}))
+
+	snapshot, err := service.SnapshotTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Indexed, 2)
+	require.Len(t, snapshot.Pending, 1)
+	require.Contains(t, snapshot.Pending, "b")
+
+	// Nothing has changed since the snapshot.
+	changes, err := service.ReadTicketChanges(ctx, snapshot.Position, 0)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "c"}))
+	require.NoError(t, service.DeindexTicket(ctx, "a"))
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{{Tickets: []*pb.Ticket{{Id: "c"}}}}))
+	require.NoError(t, service.DeleteTicketsFromPendingRelease(ctx, []string{"b", "c"}))
+	require.NoError(t, service.ReleaseAllTickets(ctx))
+
+	changes, err = service.ReadTicketChanges(ctx, snapshot.Position, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	require.Equal(t, TicketIndexed, changes[0].Type)
+	require.Equal(t, "c", changes[0].Ticket.GetId())
+	require.Equal(t, TicketsDeindexed, changes[1].Type)
+	require.Equal(t, []string{"a"}, changes[1].TicketIDs)
+	require.Equal(t, TicketsPending, changes[2].Type)
+	require.Equal(t, []string{"c"}, changes[2].TicketIDs)
+	require.WithinDuration(t, time.Now(), changes[2].Time, time.Minute)
+	require.Equal(t, TicketsReleased, changes[3].Type)
+	require.Equal(t, []string{"b", "c"}, changes[3].TicketIDs)
+	require.Equal(t, AllTicketsReleased, changes[4].Type)
+
+	changes, err = service.ReadTicketChanges(ctx, changes[4].Position, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestTicketChangesTrimmed(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	cfg.(*viper.Viper).Set(config.KeyTicketChangeStream, true)
+	cfg.(*viper.Viper).Set(config.KeyTicketChangeStreamMaxLen, 2)
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	snapshot, err := service.SnapshotTickets(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "a"}))
+	changes, err := service.ReadTicketChanges(ctx, snapshot.Position, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	// The snapshot's mark is trimmed, but the position read up to is kept.
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "b"}))
+	changes, err = service.ReadTicketChanges(ctx, changes[0].Position, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "b", changes[0].Ticket.GetId())
+
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "c"}))
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "d"}))
+	_, err = service.ReadTicketChanges(ctx, changes[0].Position, 0)
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+}
+
+func TestTicketChangesDisabled(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, service.IndexTicket(ctx, &pb.Ticket{Id: "a"}))
+	_, err := service.SnapshotTickets(ctx)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Tickets claimed for a FetchMatches call, and not yet returned to its caller,
+// are recorded under the call's owner id, with the match which claimed them.
+// The backend serving the call renews the owner's lease while it runs.  If the
+// backend dies before returning the matches, the lease expires and any backend
+// releases the claims, instead of the tickets waiting out
+// pendingReleaseTimeout.
+const (
+	// claimOwners is the set of owners which have recorded claims.
+	claimOwners = "claimOwners"
+	// claimsPrefix keys a hash of the owner's unreturned ticket ids to the ids
+	// of the matches which claimed them.
+	claimsPrefix = "claims:"
+	// claimLeasePrefix keys a value which exists while the owner is alive.
+	claimLeasePrefix = "claimLease:"
+)
+
+// sendOwnerClaims sends the commands recording the tickets of the matches as
+// claimed by owner, within a transaction.  Does nothing for an empty owner.
+func sendOwnerClaims(redisConn redis.Conn, owner string, matches []*pb.Match) error {
+	if owner == "" {
+		return nil
+	}
+
+	args := []interface{}{claimsPrefix + owner}
+	for _, match := range matches {
+		for _, ticket := range match.GetTickets() {
+			args = append(args, ticket.GetId(), match.GetMatchId())
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+
+	err := redisConn.Send("HSET", args...)
+	if err != nil {
+		return errors.Wrapf(err, "error sending ticket claims of owner %s", owner)
+	}
+	err = redisConn.Send("SADD", claimOwners, owner)
+	if err != nil {
+		return errors.Wrapf(err, "error sending claim owner %s", owner)
+	}
+	return nil
+}
+
+// RenewClaimLease keeps the claims of owner from being released by
+// ReleaseOrphanedClaims for the ttl.
+func (rb *redisBackend) RenewClaimLease(ctx context.Context, owner string, ttl time.Duration) error {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "RenewClaimLease, owner: %s, failed to connect to redis: %v", owner, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	_, err = redisConn.Do("SET", claimLeasePrefix+owner, 1, "PX", ttl.Milliseconds())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to renew the claim lease of owner: %s", owner)
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// ConfirmClaims records that the tickets claimed by owner were returned to
+// the caller, so they stay pending when the owner's claims are released.
+func (rb *redisBackend) ConfirmClaims(ctx context.Context, owner string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "ConfirmClaims, owner: %s, failed to connect to redis: %v", owner, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	args := make([]interface{}, 0, len(ids)+1)
+	args = append(args, claimsPrefix+owner)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err = redisConn.Do("HDEL", args...)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to confirm the ticket claims of owner: %s", owner)
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// ReleaseClaims removes the unconfirmed tickets claimed by owner from the
+// pending release, and forgets the owner.  Tickets since released, or claimed
+// by another match, are left alone.  Returns the ids of the released tickets.
+func (rb *redisBackend) ReleaseClaims(ctx context.Context, owner string) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ReleaseClaims, owner: %s, failed to connect to redis: %v", owner, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	return rb.releaseClaims(redisConn, owner, false)
+}
+
+// releaseClaims releases the claims of owner.  If orphaned, the claims are
+// only released while the owner has no lease.
+func (rb *redisBackend) releaseClaims(redisConn redis.Conn, owner string, orphaned bool) ([]string, error) {
+	claimsKey := claimsPrefix + owner
+	leaseKey := claimLeasePrefix + owner
+
+	// Watch the pending release, so that tickets claimed again while the
+	// owner's claims are checked aren't released, and the owner, so that an
+	// owner renewing its lease late keeps its claims.
+	_, err := redisConn.Do("WATCH", proposedTicketIDs, claimsKey, leaseKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error watching claims of owner %s: %v", owner, err)
+	}
+
+	if orphaned {
+		// The owner may have renewed its lease since it was found expired,
+		// before the watch started.
+		alive, err := redis.Bool(redisConn.Do("EXISTS", leaseKey))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking claim lease of owner %s: %v", owner, err)
+		}
+		if alive {
+			_, err = redisConn.Do("UNWATCH")
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "error unwatching claims of owner %s: %v", owner, err)
+			}
+			return nil, nil
+		}
+	}
+
+	claimed, err := redis.StringMap(redisConn.Do("HGETALL", claimsKey))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting claims of owner %s: %v", owner, err)
+	}
+
+	var released []string
+	if len(claimed) > 0 {
+		args := make([]interface{}, 0, len(claimed)+1)
+		args = append(args, proposedTicketClaims)
+		ids := make([]string, 0, len(claimed))
+		for id := range claimed {
+			args = append(args, id)
+			ids = append(ids, id)
+		}
+		current, err := redis.ByteSlices(redisConn.Do("HMGET", args...))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error getting pending ticket claims of owner %s: %v", owner, err)
+		}
+		for i, id := range ids {
+			if current[i] == nil {
+				continue
+			}
+			claim := &pb.PendingTicket{}
+			err = proto.Unmarshal(current[i], claim)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "error unmarshaling pending ticket claim of ticket %s: %v", id, err)
+			}
+			if claim.GetMatchId() == claimed[id] {
+				released = append(released, id)
+			}
+		}
+	}
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting redis multi")
+	}
+	if len(released) > 0 {
+		cmds := make([]interface{}, 0, len(released)+1)
+		cmds = append(cmds, proposedTicketIDs)
+		claims := make([]interface{}, 0, len(released)+1)
+		claims = append(claims, proposedTicketClaims)
+		for _, id := range released {
+			cmds = append(cmds, id)
+			claims = append(claims, id)
+		}
+
+		err = redisConn.Send("ZREM", cmds...)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending claimed tickets removal")
+		}
+		err = redisConn.Send("HDEL", claims...)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending claimed ticket claims removal")
+		}
+		err = rb.sendTicketChange(redisConn, TicketsReleased, ticketIDFields(released)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = redisConn.Send("DEL", claimsKey, leaseKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending claims removal")
+	}
+	err = redisConn.Send("SREM", claimOwners, owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending claim owner removal")
+	}
+
+	reply, err := redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to release the claims of owner: %s", owner)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if reply == nil {
+		return nil, status.Errorf(codes.Aborted, "the pending release changed while the claims of owner %s were released, retry", owner)
+	}
+
+	return released, nil
+}
+
+// ReleaseOrphanedClaims releases the claims of every owner whose lease has
+// expired.  Returns the ids of the released tickets.
+func (rb *redisBackend) ReleaseOrphanedClaims(ctx context.Context) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ReleaseOrphanedClaims, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	owners, err := redis.Strings(redisConn.Do("SMEMBERS", claimOwners))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting claim owners: %v", err)
+	}
+	for _, owner := range owners {
+		err = redisConn.Send("EXISTS", claimLeasePrefix+owner)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending claim lease check")
+		}
+	}
+	err = redisConn.Flush()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking claim leases: %v", err)
+	}
+	var orphaned []string
+	for _, owner := range owners {
+		alive, err := redis.Bool(redisConn.Receive())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking claim lease: %v", err)
+		}
+		if !alive {
+			orphaned = append(orphaned, owner)
+		}
+	}
+
+	var released []string
+	for _, owner := range orphaned {
+		ids, err := rb.releaseClaims(redisConn, owner, true)
+		if err != nil {
+			return released, err
+		}
+		released = append(released, ids...)
+	}
+	return released, nil
+}
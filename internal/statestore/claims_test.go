@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestClaims(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, service.RenewClaimLease(ctx, "alive", time.Minute))
+	requireClaimed(t, ctx, service, "alive", []*pb.Match{
+		{MatchId: "a", Tickets: []*pb.Ticket{{Id: "1"}, {Id: "2"}}},
+	})
+	require.NoError(t, service.ConfirmClaims(ctx, "alive", []string{"1"}))
+
+	// "dead" never took a lease, as if its backend died.
+	requireClaimed(t, ctx, service, "dead", []*pb.Match{
+		{MatchId: "b", Tickets: []*pb.Ticket{{Id: "3"}}},
+		{MatchId: "c", Tickets: []*pb.Ticket{{Id: "4"}}},
+	})
+	// Tickets since claimed by another match stay pending.
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "d", Tickets: []*pb.Ticket{{Id: "4"}}},
+	}))
+
+	released, err := service.ReleaseOrphanedClaims(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"3"}, released)
+	requirePending(t, service, "1", "2", "4")
+
+	// Confirmed tickets stay pending when the owner releases its claims.
+	released, err = service.ReleaseClaims(ctx, "alive")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, released)
+	requirePending(t, service, "1", "4")
+
+	released, err = service.ReleaseOrphanedClaims(ctx)
+	require.NoError(t, err)
+	require.Empty(t, released)
+}
+
+func TestReleaseOrphanedClaimsRenewed(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	rb := newRedis(cfg).(*redisBackend)
+	defer rb.Close()
+	ctx := utilTesting.NewContext(t)
+
+	// The owner's lease expired, so the orphan scan finds it.
+	requireClaimed(t, ctx, rb, "late", []*pb.Match{
+		{MatchId: "a", Tickets: []*pb.Ticket{{Id: "1"}}},
+	})
+
+	// The owner renews its lease after the scan, before the release.
+	require.NoError(t, rb.RenewClaimLease(ctx, "late", time.Minute))
+
+	redisConn, err := rb.getConn(ctx)
+	require.NoError(t, err)
+	defer handleConnectionClose(&redisConn)
+	released, err := rb.releaseClaims(redisConn, "late", true)
+	require.NoError(t, err)
+	require.Empty(t, released)
+	requirePending(t, rb, "1")
+
+	// The owner itself still releases its claims.
+	released, err = rb.releaseClaims(redisConn, "late", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1"}, released)
+	requirePending(t, rb)
+}
+
+// requireClaimed claims the tickets of every match for owner.
+func requireClaimed(t *testing.T, ctx context.Context, store Service, owner string, matches []*pb.Match) {
+	t.Helper()
+	skipped, err := store.ClaimTickets(ctx, owner, matches)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+}
+
+func requirePending(t *testing.T, service Service, ids ...string) {
+	pending, err := service.GetPendingIDSet(utilTesting.NewContext(t))
+	require.NoError(t, err)
+	want := map[string]struct{}{}
+	for _, id := range ids {
+		want[id] = struct{}{}
+	}
+	require.Equal(t, want, pending)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Compressed tickets are stored as a marker byte, a codec byte, and the
+// compressed proto payload.  Field number 0 is invalid in the protobuf wire
+// format, so no marshaled ticket starts with the marker byte.  This allows
+// reading tickets written before compression was enabled, or which were below
+// the size threshold.
+const (
+	compressedMarker byte = 0x00
+	codecSnappy      byte = 0x01
+)
+
+// marshalTicket marshals the ticket, compressing the payload if compression
+// is enabled and the payload is over the configured threshold.
+func marshalTicket(cfg config.View, ticket *pb.Ticket) ([]byte, error) {
+	value, err := proto.Marshal(ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := config.GetStateStore(cfg)
+	codec, err := compressionCodec(settings.CompressionCodec)
+	if err != nil {
+		return nil, err
+	}
+	if codec == 0 || len(value) < settings.CompressionThreshold {
+		return value, nil
+	}
+
+	return append([]byte{compressedMarker, codec}, snappy.Encode(nil, value)...), nil
+}
+
+// unmarshalTicket unmarshals a ticket written by marshalTicket, regardless of
+// the currently configured codec.
+func unmarshalTicket(value []byte, ticket *pb.Ticket) error {
+	if len(value) == 0 || value[0] != compressedMarker {
+		return proto.Unmarshal(value, ticket)
+	}
+
+	if len(value) < 2 {
+		return fmt.Errorf("compressed ticket is missing codec")
+	}
+
+	switch value[1] {
+	case codecSnappy:
+		decoded, err := snappy.Decode(nil, value[2:])
+		if err != nil {
+			return err
+		}
+		return proto.Unmarshal(decoded, ticket)
+	default:
+		return fmt.Errorf("unknown ticket compression codec %d", value[1])
+	}
+}
+
+func compressionCodec(name string) (byte, error) {
+	switch name {
+	case "none":
+		return 0, nil
+	case "snappy":
+		return codecSnappy, nil
+	default:
+		return 0, fmt.Errorf("unsupported %s %q", config.KeyCompressionCodec, name)
+	}
+}
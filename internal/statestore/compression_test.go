@@ -0,0 +1,99 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketCompression(t *testing.T) {
+	fat := &pb.Ticket{
+		Id: "fat",
+		SearchFields: &pb.SearchFields{
+			StringArgs: map[string]string{"payload": strings.Repeat("open-match", 500)},
+		},
+	}
+	small := &pb.Ticket{Id: "small"}
+
+	cfg := viper.New()
+	cfg.Set(config.KeyCompressionCodec, "snappy")
+
+	b, err := marshalTicket(cfg, fat)
+	require.NoError(t, err)
+	require.Equal(t, compressedMarker, b[0])
+	require.Less(t, len(b), proto.Size(fat))
+
+	actual := &pb.Ticket{}
+	require.NoError(t, unmarshalTicket(b, actual))
+	require.True(t, proto.Equal(fat, actual))
+
+	// Below the threshold, tickets are stored as plain protos.
+	b, err = marshalTicket(cfg, small)
+	require.NoError(t, err)
+	plain, err := proto.Marshal(small)
+	require.NoError(t, err)
+	require.Equal(t, plain, b)
+
+	cfg.Set(config.KeyCompressionCodec, "lz4")
+	_, err = marshalTicket(cfg, fat)
+	require.Error(t, err)
+
+	require.Error(t, unmarshalTicket([]byte{compressedMarker, 0x7f, 0x01}, actual))
+}
+
+func TestCompressedTicketLifecycle(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	cfg.(*viper.Viper).Set(config.KeyCompressionCodec, "snappy")
+	cfg.(*viper.Viper).Set(config.KeyCompressionThreshold, 0)
+	service := New(cfg)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	ticket := &pb.Ticket{
+		Id: "compressed",
+		SearchFields: &pb.SearchFields{
+			DoubleArgs: map[string]float64{"level": 42},
+		},
+	}
+	require.NoError(t, service.CreateTicket(ctx, ticket))
+
+	actual, err := service.GetTicket(ctx, ticket.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(ticket, actual))
+
+	tickets, err := service.GetTickets(ctx, []string{ticket.Id})
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	require.True(t, proto.Equal(ticket, tickets[0]))
+
+	_, assigned, err := service.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{{TicketIds: []string{ticket.Id}, Assignment: &pb.Assignment{Connection: "localhost"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, assigned, 1)
+
+	actual, err = service.GetTicket(ctx, ticket.Id)
+	require.NoError(t, err)
+	require.Equal(t, "localhost", actual.GetAssignment().GetConnection())
+}
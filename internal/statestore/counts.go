@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Ticket counts are kept in one hash per minute, keyed by the unix time of the
+// start of the minute, with a field per counter.
+const ticketCountsPrefix = "ticketCounts:"
+
+const (
+	ticketsCreated  = "created"
+	ticketsAssigned = "assigned"
+	ticketsDeleted  = "deleted"
+	ticketsExpired  = "expired"
+)
+
+// ticketEventCounters are the counters incremented by RecordTicketEvent.
+// Assigned tickets are counted by UpdateAssignments instead, which knows
+// whether a ticket was assigned before.
+var ticketEventCounters = map[pb.TicketEvent_Type]string{
+	pb.TicketEvent_CREATED: ticketsCreated,
+	pb.TicketEvent_DELETED: ticketsDeleted,
+}
+
+func ticketCountsKey(minute time.Time) string {
+	return ticketCountsPrefix + strconv.FormatInt(minute.Unix(), 10)
+}
+
+// sendTicketCount adds n to the counter of the minute of t.  The counts of a
+// minute are kept for the retention after the minute ends.
+func sendTicketCount(redisConn redis.Conn, counter string, n int, t time.Time, retention time.Duration) error {
+	key := ticketCountsKey(t.Truncate(time.Minute))
+	err := redisConn.Send("HINCRBY", key, counter, n)
+	if err != nil {
+		return errors.Wrapf(err, "error sending ticket count %s", counter)
+	}
+	err = redisConn.Send("PEXPIRE", key, (retention + time.Minute).Milliseconds())
+	if err != nil {
+		return errors.Wrapf(err, "error sending ticket counts expiry for %s", key)
+	}
+	return nil
+}
+
+// CountExpiredTickets counts tickets deleted for missing their heartbeats, or
+// removed from matchmaking past their deadline.
+// Does nothing unless ticketCountsRetention is configured.
+func (rb *redisBackend) CountExpiredTickets(ctx context.Context, n int) error {
+	retention := config.GetStateStore(rb.cfg).TicketCountsRetention
+	if retention <= 0 || n == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "CountExpiredTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+	err = sendTicketCount(redisConn, ticketsExpired, n, time.Now(), retention)
+	if err != nil {
+		return err
+	}
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrap(err, "failed to count expired tickets")
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// GetTicketCounts returns the ticket counts of the last minutes, the current
+// one included, oldest first.
+func (rb *redisBackend) GetTicketCounts(ctx context.Context, minutes int) ([]*pb.TicketCounts, error) {
+	if minutes <= 0 {
+		return nil, nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetTicketCounts, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	first := time.Now().Truncate(time.Minute).Add(-time.Duration(minutes-1) * time.Minute)
+	for i := 0; i < minutes; i++ {
+		err = redisConn.Send("HGETALL", ticketCountsKey(first.Add(time.Duration(i)*time.Minute)))
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending ticket counts read")
+		}
+	}
+	err = redisConn.Flush()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting ticket counts: %v", err)
+	}
+
+	counts := make([]*pb.TicketCounts, 0, minutes)
+	for i := 0; i < minutes; i++ {
+		values, err := redis.Int64Map(redisConn.Receive())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error getting ticket counts: %v", err)
+		}
+		minute, err := ptypes.TimestampProto(first.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid ticket counts minute: %v", err)
+		}
+		counts = append(counts, &pb.TicketCounts{
+			Minute:   minute,
+			Created:  values[ticketsCreated],
+			Assigned: values[ticketsAssigned],
+			Deleted:  values[ticketsDeleted],
+			Expired:  values[ticketsExpired],
+		})
+	}
+	return counts, nil
+}
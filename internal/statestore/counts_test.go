@@ -0,0 +1,95 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketCounts(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	// Nothing is counted until a retention is configured.
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED}))
+	require.NoError(t, service.CountExpiredTickets(ctx, 1))
+	counts, err := service.GetTicketCounts(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	for _, c := range counts {
+		require.Zero(t, c.GetCreated())
+		require.Zero(t, c.GetExpired())
+	}
+
+	cfg.(*viper.Viper).Set("ticketCountsRetention", time.Hour)
+
+	lastMinute := time.Now().Truncate(time.Minute).Add(-time.Minute)
+	lastMinuteProto, err := ptypes.TimestampProto(lastMinute.Add(time.Second))
+	require.NoError(t, err)
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED, Time: lastMinuteProto}))
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{Type: pb.TicketEvent_PROPOSED}))
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED}))
+	// Reassigning a ticket doesn't count it again.
+	require.NoError(t, service.CreateTicket(ctx, &pb.Ticket{Id: "a"}))
+	for _, connection := range []string{"1", "2"} {
+		_, _, err = service.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+			Assignments: []*pb.AssignmentGroup{{TicketIds: []string{"a"}, Assignment: &pb.Assignment{Connection: connection}}},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"b"}, &pb.TicketEvent{Type: pb.TicketEvent_DELETED}))
+	require.NoError(t, service.CountExpiredTickets(ctx, 3))
+
+	counts, err = service.GetTicketCounts(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, counts, 5)
+
+	total := &pb.TicketCounts{}
+	for i, c := range counts {
+		minute, err := ptypes.Timestamp(c.GetMinute())
+		require.NoError(t, err)
+		if i > 0 {
+			previous, err := ptypes.Timestamp(counts[i-1].GetMinute())
+			require.NoError(t, err)
+			require.Equal(t, time.Minute, minute.Sub(previous))
+		}
+		if minute.Equal(lastMinute) {
+			require.Equal(t, int64(2), c.GetCreated())
+		}
+		total.Created += c.GetCreated()
+		total.Assigned += c.GetAssigned()
+		total.Deleted += c.GetDeleted()
+		total.Expired += c.GetExpired()
+	}
+	require.Equal(t, int64(2), total.Created)
+	require.Equal(t, int64(1), total.Assigned)
+	require.Equal(t, int64(1), total.Deleted)
+	require.Equal(t, int64(3), total.Expired)
+
+	counts, err = service.GetTicketCounts(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, counts)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ticketDeadlines is a sorted set of ticket ids, scored by the unix time in
+// milliseconds of their matchmaking deadline.
+const ticketDeadlines = "ticketDeadlines"
+
+// AddTicketDeadlines records the matchmaking deadlines of tickets by id.
+func (rb *redisBackend) AddTicketDeadlines(ctx context.Context, deadlines map[string]time.Time) error {
+	if len(deadlines) == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "AddTicketDeadlines, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	args := make([]interface{}, 0, 2*len(deadlines)+1)
+	args = append(args, ticketDeadlines)
+	for id, deadline := range deadlines {
+		args = append(args, deadline.UnixNano()/int64(time.Millisecond), id)
+	}
+	_, err = redisConn.Do("ZADD", args...)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to record the deadlines of %d tickets", len(deadlines))
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// ClaimExpiredTickets removes up to limit tickets whose deadline is before
+// the time from the deadlines, and returns their ids.  Each expired ticket is
+// returned to one caller only, when several claim concurrently.
+func (rb *redisBackend) ClaimExpiredTickets(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ClaimExpiredTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ids, err := claimScoredBefore(redisConn, ticketDeadlines, before, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error claiming expired tickets: %v", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+)
+
+func TestClaimExpiredTickets(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	now := time.Now()
+	require.NoError(t, service.AddTicketDeadlines(ctx, nil))
+	require.NoError(t, service.AddTicketDeadlines(ctx, map[string]time.Time{
+		"a": now.Add(-time.Minute),
+		"b": now.Add(-time.Second),
+		"c": now.Add(time.Minute),
+	}))
+
+	ids, err := service.ClaimExpiredTickets(ctx, now, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, ids)
+
+	ids, err = service.ClaimExpiredTickets(ctx, now, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, ids)
+
+	ids, err = service.ClaimExpiredTickets(ctx, now, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	ids, err = service.ClaimExpiredTickets(ctx, now.Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, ids)
+}
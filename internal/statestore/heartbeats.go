@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ticketHeartbeats is a sorted set of ticket ids, scored by the unix time in
+// milliseconds of their last heartbeat.
+const ticketHeartbeats = "ticketHeartbeats"
+
+// TouchTickets records a heartbeat for the tickets.
+func (rb *redisBackend) TouchTickets(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "TouchTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	args := make([]interface{}, 0, 2*len(ids)+1)
+	args = append(args, ticketHeartbeats)
+	for _, id := range ids {
+		args = append(args, now, id)
+	}
+	_, err = redisConn.Do("ZADD", args...)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to record the heartbeats of %d tickets", len(ids))
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// ClaimStaleTickets removes up to limit tickets whose last heartbeat is
+// before the time from the heartbeats, and returns their ids.  Each stale
+// ticket is returned to one caller only, when several claim concurrently.
+func (rb *redisBackend) ClaimStaleTickets(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ClaimStaleTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ids, err := claimScoredBefore(redisConn, ticketHeartbeats, before, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error claiming stale tickets: %v", err)
+	}
+	return ids, nil
+}
+
+// claimScoredBefore removes up to limit members of the sorted set scored, in
+// unix milliseconds, before the time, and returns those removed by this call.
+func claimScoredBefore(redisConn redis.Conn, key string, before time.Time, limit int) ([]string, error) {
+	cutoff := before.UnixNano() / int64(time.Millisecond)
+	ids, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", key, "-inf", fmt.Sprintf("(%d", cutoff), "LIMIT", 0, limit))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", key)
+	}
+
+	// Removing the members one at a time tells which were removed by this
+	// call.
+	for _, id := range ids {
+		err = redisConn.Send("ZREM", key, id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send the removal from %s", key)
+		}
+	}
+	err = redisConn.Flush()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to remove from %s", key)
+	}
+	claimed := make([]string, 0, len(ids))
+	for _, id := range ids {
+		removed, err := redis.Int(redisConn.Receive())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to remove from %s", key)
+		}
+		if removed == 1 {
+			claimed = append(claimed, id)
+		}
+	}
+	return claimed, nil
+}
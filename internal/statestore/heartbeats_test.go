@@ -0,0 +1,88 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+)
+
+func TestClaimStaleTickets(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, service.TouchTickets(ctx, nil))
+	require.NoError(t, service.TouchTickets(ctx, []string{"a", "b", "c"}))
+
+	// Tickets touched since the cutoff aren't stale.
+	ids, err := service.ClaimStaleTickets(ctx, time.Now().Add(-time.Minute), 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	ids, err = service.ClaimStaleTickets(ctx, time.Now().Add(time.Minute), 2)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	// A heartbeat after the claim tracks the ticket again.
+	require.NoError(t, service.TouchTickets(ctx, ids[:1]))
+	ids, err = service.ClaimStaleTickets(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	ids, err = service.ClaimStaleTickets(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func TestClaimStaleTicketsConcurrently(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	touched := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	require.NoError(t, service.TouchTickets(ctx, touched))
+
+	claimed := make(chan string, 4*len(touched))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids, err := service.ClaimStaleTickets(ctx, time.Now().Add(time.Minute), 100)
+			require.NoError(t, err)
+			for _, id := range ids {
+				claimed <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(claimed)
+
+	var all []string
+	for id := range claimed {
+		all = append(all, id)
+	}
+	require.ElementsMatch(t, touched, all)
+}
@@ -16,6 +16,7 @@ package statestore
 
 import (
 	"context"
+	"time"
 
 	"go.opencensus.io/trace"
 	"open-match.dev/open-match/pkg/pb"
@@ -59,6 +60,12 @@ func (is *instrumentedService) IndexTicket(ctx context.Context, ticket *pb.Ticke
 	return is.s.IndexTicket(ctx, ticket)
 }
 
+func (is *instrumentedService) CreateTickets(ctx context.Context, tickets []*pb.Ticket) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.CreateTickets")
+	defer span.End()
+	return is.s.CreateTickets(ctx, tickets)
+}
+
 func (is *instrumentedService) DeindexTicket(ctx context.Context, id string) error {
 	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.DeindexTicket")
 	defer span.End()
@@ -77,6 +84,42 @@ func (is *instrumentedService) GetIndexedIDSet(ctx context.Context) (map[string]
 	return is.s.GetIndexedIDSet(ctx)
 }
 
+func (is *instrumentedService) GetPendingIDSet(ctx context.Context) (map[string]struct{}, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetPendingIDSet")
+	defer span.End()
+	return is.s.GetPendingIDSet(ctx)
+}
+
+func (is *instrumentedService) GetAssignedIDSet(ctx context.Context) (map[string]struct{}, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetAssignedIDSet")
+	defer span.End()
+	return is.s.GetAssignedIDSet(ctx)
+}
+
+func (is *instrumentedService) TouchTickets(ctx context.Context, ids []string) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.TouchTickets")
+	defer span.End()
+	return is.s.TouchTickets(ctx, ids)
+}
+
+func (is *instrumentedService) ClaimStaleTickets(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ClaimStaleTickets")
+	defer span.End()
+	return is.s.ClaimStaleTickets(ctx, before, limit)
+}
+
+func (is *instrumentedService) AddTicketDeadlines(ctx context.Context, deadlines map[string]time.Time) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.AddTicketDeadlines")
+	defer span.End()
+	return is.s.AddTicketDeadlines(ctx, deadlines)
+}
+
+func (is *instrumentedService) ClaimExpiredTickets(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ClaimExpiredTickets")
+	defer span.End()
+	return is.s.ClaimExpiredTickets(ctx, before, limit)
+}
+
 func (is *instrumentedService) UpdateAssignments(ctx context.Context, req *pb.AssignTicketsRequest) (*pb.AssignTicketsResponse, []*pb.Ticket, error) {
 	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.UpdateAssignments")
 	defer span.End()
@@ -89,10 +132,58 @@ func (is *instrumentedService) GetAssignments(ctx context.Context, id string, ca
 	return is.s.GetAssignments(ctx, id, callback)
 }
 
-func (is *instrumentedService) AddTicketsToPendingRelease(ctx context.Context, ids []string) error {
+func (is *instrumentedService) AddTicketsToPendingRelease(ctx context.Context, matches []*pb.Match) error {
 	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.AddTicketsToPendingRelease")
 	defer span.End()
-	return is.s.AddTicketsToPendingRelease(ctx, ids)
+	return is.s.AddTicketsToPendingRelease(ctx, matches)
+}
+
+func (is *instrumentedService) ClaimTickets(ctx context.Context, owner string, matches []*pb.Match) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ClaimTickets")
+	defer span.End()
+	return is.s.ClaimTickets(ctx, owner, matches)
+}
+
+func (is *instrumentedService) RenewClaimLease(ctx context.Context, owner string, ttl time.Duration) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.RenewClaimLease")
+	defer span.End()
+	return is.s.RenewClaimLease(ctx, owner, ttl)
+}
+
+func (is *instrumentedService) ConfirmClaims(ctx context.Context, owner string, ids []string) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ConfirmClaims")
+	defer span.End()
+	return is.s.ConfirmClaims(ctx, owner, ids)
+}
+
+func (is *instrumentedService) ReleaseClaims(ctx context.Context, owner string) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ReleaseClaims")
+	defer span.End()
+	return is.s.ReleaseClaims(ctx, owner)
+}
+
+func (is *instrumentedService) ReleaseOrphanedClaims(ctx context.Context) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ReleaseOrphanedClaims")
+	defer span.End()
+	return is.s.ReleaseOrphanedClaims(ctx)
+}
+
+func (is *instrumentedService) GetMatchRoster(ctx context.Context, matchID string) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetMatchRoster")
+	defer span.End()
+	return is.s.GetMatchRoster(ctx, matchID)
+}
+
+func (is *instrumentedService) ReserveTickets(ctx context.Context, req *pb.ReserveTicketsRequest) (*pb.ReserveTicketsResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ReserveTickets")
+	defer span.End()
+	return is.s.ReserveTickets(ctx, req)
+}
+
+func (is *instrumentedService) GetPendingTickets(ctx context.Context) ([]*pb.PendingTicket, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetPendingTickets")
+	defer span.End()
+	return is.s.GetPendingTickets(ctx)
 }
 
 func (is *instrumentedService) DeleteTicketsFromPendingRelease(ctx context.Context, ids []string) error {
@@ -197,3 +288,81 @@ func (is *instrumentedService) DeleteBackfillCompletely(ctx context.Context, id
 	defer span.End()
 	return is.s.DeleteBackfillCompletely(ctx, id)
 }
+
+// UpdateServerCapacity stores the capacity for its region and fleet, replacing any previous value.
+func (is *instrumentedService) UpdateServerCapacity(ctx context.Context, capacity *pb.ServerCapacity) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.UpdateServerCapacity")
+	defer span.End()
+	return is.s.UpdateServerCapacity(ctx, capacity)
+}
+
+// GetServerCapacity returns the unexpired capacity for the region, or for all regions if region is empty.
+func (is *instrumentedService) GetServerCapacity(ctx context.Context, region string) ([]*pb.ServerCapacity, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetServerCapacity")
+	defer span.End()
+	return is.s.GetServerCapacity(ctx, region)
+}
+
+// PauseProfiles adds the match profiles to the paused profiles.
+func (is *instrumentedService) PauseProfiles(ctx context.Context, names []string) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.PauseProfiles")
+	defer span.End()
+	return is.s.PauseProfiles(ctx, names)
+}
+
+// ResumeProfiles removes the match profiles from the paused profiles.
+func (is *instrumentedService) ResumeProfiles(ctx context.Context, names []string) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ResumeProfiles")
+	defer span.End()
+	return is.s.ResumeProfiles(ctx, names)
+}
+
+// GetPausedProfiles returns the names of the paused match profiles, sorted.
+func (is *instrumentedService) GetPausedProfiles(ctx context.Context) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetPausedProfiles")
+	defer span.End()
+	return is.s.GetPausedProfiles(ctx)
+}
+
+// RecordTicketEvent appends the event to the timeline of each of the tickets, and counts the tickets in the minute of the event.
+func (is *instrumentedService) RecordTicketEvent(ctx context.Context, ids []string, event *pb.TicketEvent) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.RecordTicketEvent")
+	defer span.End()
+	return is.s.RecordTicketEvent(ctx, ids, event)
+}
+
+// GetTicketTimeline returns the events recorded for the ticket, oldest first.
+func (is *instrumentedService) GetTicketTimeline(ctx context.Context, id string) ([]*pb.TicketEvent, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetTicketTimeline")
+	defer span.End()
+	return is.s.GetTicketTimeline(ctx, id)
+}
+
+// CountExpiredTickets counts tickets deleted for missing their heartbeats, or
+// removed from matchmaking past their deadline.
+func (is *instrumentedService) CountExpiredTickets(ctx context.Context, n int) error {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.CountExpiredTickets")
+	defer span.End()
+	return is.s.CountExpiredTickets(ctx, n)
+}
+
+// GetTicketCounts returns the ticket counts of the last minutes, the current one included, oldest first.
+func (is *instrumentedService) GetTicketCounts(ctx context.Context, minutes int) ([]*pb.TicketCounts, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.GetTicketCounts")
+	defer span.End()
+	return is.s.GetTicketCounts(ctx, minutes)
+}
+
+// SnapshotTickets returns the ticket index, and the position of the ticket change stream to follow it from.
+func (is *instrumentedService) SnapshotTickets(ctx context.Context) (*TicketSnapshot, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.SnapshotTickets")
+	defer span.End()
+	return is.s.SnapshotTickets(ctx)
+}
+
+// ReadTicketChanges returns the changes after the position, waiting up to wait for one if there are none.
+func (is *instrumentedService) ReadTicketChanges(ctx context.Context, after string, wait time.Duration) ([]*TicketChange, error) {
+	ctx, span := trace.StartSpan(ctx, "statestore/instrumented.ReadTicketChanges")
+	defer span.End()
+	return is.s.ReadTicketChanges(ctx, after, wait)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const pausedProfiles = "pausedProfiles"
+
+// PauseProfiles adds the match profiles to the paused profiles.
+func (rb *redisBackend) PauseProfiles(ctx context.Context, names []string) error {
+	return rb.updatePausedProfiles(ctx, "PauseProfiles", "SADD", names)
+}
+
+// ResumeProfiles removes the match profiles from the paused profiles.
+func (rb *redisBackend) ResumeProfiles(ctx context.Context, names []string) error {
+	return rb.updatePausedProfiles(ctx, "ResumeProfiles", "SREM", names)
+}
+
+func (rb *redisBackend) updatePausedProfiles(ctx context.Context, method, cmd string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "%s, failed to connect to redis: %v", method, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	args := make([]interface{}, 0, len(names)+1)
+	args = append(args, pausedProfiles)
+	for _, name := range names {
+		args = append(args, name)
+	}
+	_, err = redisConn.Do(cmd, args...)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to update the paused profiles %v", names)
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// GetPausedProfiles returns the names of the paused match profiles, sorted.
+func (rb *redisBackend) GetPausedProfiles(ctx context.Context) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetPausedProfiles, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	names, err := redis.Strings(redisConn.Do("SMEMBERS", pausedProfiles))
+	if err != nil {
+		err = errors.Wrap(err, "failed to get the paused profiles")
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+)
+
+func TestPausedProfiles(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	names, err := service.GetPausedProfiles(ctx)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	require.NoError(t, service.PauseProfiles(ctx, []string{"ranked", "casual"}))
+	require.NoError(t, service.PauseProfiles(ctx, []string{"ranked"}))
+	names, err = service.GetPausedProfiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"casual", "ranked"}, names)
+
+	// Resuming a profile which isn't paused is not an error.
+	require.NoError(t, service.ResumeProfiles(ctx, []string{"ranked", "arena"}))
+	require.NoError(t, service.ResumeProfiles(ctx, nil))
+	names, err = service.GetPausedProfiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"casual"}, names)
+}
@@ -16,6 +16,7 @@ package statestore
 
 import (
 	"context"
+	"time"
 
 	"open-match.dev/open-match/internal/config"
 	"open-match.dev/open-match/internal/telemetry"
@@ -40,30 +41,85 @@ type Service interface {
 	GetTicket(ctx context.Context, id string) (*pb.Ticket, error)
 
 	// DeleteTicket removes the Ticket with the specified id from state storage.
-	// This method succeeds if the Ticket does not exist.
+	// This method returns NotFound if the Ticket does not exist.
 	DeleteTicket(ctx context.Context, id string) error
 
 	// IndexTicket adds the ticket to the index.
 	IndexTicket(ctx context.Context, ticket *pb.Ticket) error
 
+	// CreateTickets creates and indexes the tickets in a single transaction,
+	// as CreateTicket and IndexTicket would for each of them.
+	CreateTickets(ctx context.Context, tickets []*pb.Ticket) error
+
 	// DeindexTicket removes specified ticket from the index. The Ticket continues to exist.
 	DeindexTicket(ctx context.Context, id string) error
 
 	// GetIndexedIDSet returns the ids of all tickets currently indexed.
 	GetIndexedIDSet(ctx context.Context) (map[string]struct{}, error)
 
+	// GetPendingIDSet returns the ids of all tickets currently pending release.
+	GetPendingIDSet(ctx context.Context) (map[string]struct{}, error)
+
+	// GetAssignedIDSet returns the ids of the tickets assigned within
+	// assignedDeleteTimeout.  Some may have been deleted since.
+	GetAssignedIDSet(ctx context.Context) (map[string]struct{}, error)
+
 	// GetTickets returns multiple tickets from storage.
 	// Missing tickets are silently ignored.
 	GetTickets(ctx context.Context, ids []string) ([]*pb.Ticket, error)
 
+	// TouchTickets records a heartbeat for the tickets.
+	TouchTickets(ctx context.Context, ids []string) error
+
+	// ClaimStaleTickets removes up to limit tickets whose last heartbeat is
+	// before the time from the heartbeats, and returns their ids.  Each stale
+	// ticket is returned to one caller only.  Tickets never touched are never
+	// stale.
+	ClaimStaleTickets(ctx context.Context, before time.Time, limit int) ([]string, error)
+
+	// AddTicketDeadlines records the matchmaking deadlines of tickets by id.
+	AddTicketDeadlines(ctx context.Context, deadlines map[string]time.Time) error
+
+	// ClaimExpiredTickets removes up to limit tickets whose deadline is before
+	// the time from the deadlines, and returns their ids.  Each expired ticket
+	// is returned to one caller only.
+	ClaimExpiredTickets(ctx context.Context, before time.Time, limit int) ([]string, error)
+
 	// UpdateAssignments update using the request's specified tickets with assignments.
 	UpdateAssignments(ctx context.Context, req *pb.AssignTicketsRequest) (*pb.AssignTicketsResponse, []*pb.Ticket, error)
 
 	// GetAssignments returns the assignment associated with the input ticket id.
 	GetAssignments(ctx context.Context, id string, callback func(*pb.Assignment) error) error
 
-	// AddTicketsToPendingRelease appends new proposed tickets to the proposed sorted set with current timestamp.
-	AddTicketsToPendingRelease(ctx context.Context, ids []string) error
+	// AddTicketsToPendingRelease appends the tickets of the matches to the proposed sorted set with current timestamp,
+	// and records which match claimed them, and the tickets of each match.  Matches with a reserved ticket are skipped.
+	AddTicketsToPendingRelease(ctx context.Context, matches []*pb.Match) error
+
+	// ClaimTickets is AddTicketsToPendingRelease, which also records the tickets as claimed by owner until they are
+	// confirmed or released.  Returns the ids of the matches skipped because one of their tickets is reserved.
+	ClaimTickets(ctx context.Context, owner string, matches []*pb.Match) ([]string, error)
+
+	// RenewClaimLease keeps the claims of owner from being released by ReleaseOrphanedClaims for the ttl.
+	RenewClaimLease(ctx context.Context, owner string, ttl time.Duration) error
+
+	// ConfirmClaims records that the tickets claimed by owner were returned, so they stay pending.
+	ConfirmClaims(ctx context.Context, owner string, ids []string) error
+
+	// ReleaseClaims removes the unconfirmed tickets claimed by owner from the pending release, and returns their ids.
+	ReleaseClaims(ctx context.Context, owner string) ([]string, error)
+
+	// ReleaseOrphanedClaims releases the claims of every owner whose lease has expired, and returns the ticket ids.
+	ReleaseOrphanedClaims(ctx context.Context) ([]string, error)
+
+	// GetMatchRoster returns the ids of the tickets of a match added to the pending release within matchRosterRetention.
+	GetMatchRoster(ctx context.Context, matchID string) ([]string, error)
+
+	// ReserveTickets moves the active tickets of the request to the pending release until the reservation's ttl passes,
+	// and records the reservation which claimed them.
+	ReserveTickets(ctx context.Context, req *pb.ReserveTicketsRequest) (*pb.ReserveTicketsResponse, error)
+
+	// GetPendingTickets returns the tickets currently pending release, and the matches or reservations which claimed them.
+	GetPendingTickets(ctx context.Context) ([]*pb.PendingTicket, error)
 
 	// DeleteTicketsFromPendingRelease deletes tickets from the proposed sorted set.
 	DeleteTicketsFromPendingRelease(ctx context.Context, ids []string) error
@@ -87,7 +143,7 @@ type Service interface {
 	GetBackfills(ctx context.Context, ids []string) ([]*pb.Backfill, error)
 
 	// DeleteBackfill removes the Backfill with the specified id from state storage.
-	// This method succeeds if the Backfill does not exist.
+	// This method returns NotFound if the Backfill does not exist.
 	DeleteBackfill(ctx context.Context, id string) error
 
 	// DeleteBackfillCompletely performs a set of operations to remove backfill and all related entities.
@@ -117,6 +173,51 @@ type Service interface {
 	// GetIndexedBackfills returns a map containing the IDs and
 	// the Generation number of the backfills currently indexed.
 	GetIndexedBackfills(ctx context.Context) (map[string]int, error)
+
+	// Server Capacity
+
+	// UpdateServerCapacity stores the capacity for its region and fleet, replacing any previous value.
+	UpdateServerCapacity(ctx context.Context, capacity *pb.ServerCapacity) error
+
+	// GetServerCapacity returns the unexpired capacity for the region, or for all regions if region is empty.
+	GetServerCapacity(ctx context.Context, region string) ([]*pb.ServerCapacity, error)
+
+	// Paused Profiles
+
+	// PauseProfiles adds the match profiles to the paused profiles.
+	PauseProfiles(ctx context.Context, names []string) error
+
+	// ResumeProfiles removes the match profiles from the paused profiles.
+	ResumeProfiles(ctx context.Context, names []string) error
+
+	// GetPausedProfiles returns the names of the paused match profiles, sorted.
+	GetPausedProfiles(ctx context.Context) ([]string, error)
+
+	// Ticket Timeline
+
+	// RecordTicketEvent appends the event to the timeline of each of the
+	// tickets, and counts the tickets in the minute of the event.
+	RecordTicketEvent(ctx context.Context, ids []string, event *pb.TicketEvent) error
+
+	// GetTicketTimeline returns the events recorded for the ticket, oldest first.
+	GetTicketTimeline(ctx context.Context, id string) ([]*pb.TicketEvent, error)
+
+	// CountExpiredTickets counts tickets deleted for missing their heartbeats, or
+	// removed from matchmaking past their deadline.
+	CountExpiredTickets(ctx context.Context, n int) error
+
+	// GetTicketCounts returns the ticket counts of the last minutes, the current
+	// one included, oldest first.
+	GetTicketCounts(ctx context.Context, minutes int) ([]*pb.TicketCounts, error)
+
+	// Ticket Change Stream
+
+	// SnapshotTickets returns the ticket index, and the position of the ticket change stream to follow it from.
+	SnapshotTickets(ctx context.Context) (*TicketSnapshot, error)
+
+	// ReadTicketChanges returns the changes after the position, waiting up to wait for one if there are none.
+	// Returns OutOfRange if changes after the position have been trimmed from the stream.
+	ReadTicketChanges(ctx context.Context, after string, wait time.Duration) ([]*TicketChange, error)
 }
 
 // New creates a Service based on the configuration.
@@ -0,0 +1,84 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package statestore
+
+import (
+	"context"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+const matchRosterPrefix = "matchRoster:"
+
+// sendMatchRosters sends the commands recording the ticket ids of the matches,
+// within a transaction.  Does nothing unless matchRosterRetention is
+// configured.
+func (rb *redisBackend) sendMatchRosters(redisConn redis.Conn, matches []*pb.Match) error {
+	retention := config.GetStateStore(rb.cfg).MatchRosterRetention
+	if retention <= 0 {
+		return nil
+	}
+
+	for _, match := range matches {
+		if match.GetMatchId() == "" || len(match.GetTickets()) == 0 {
+			continue
+		}
+		key := matchRosterPrefix + match.GetMatchId()
+		args := make([]interface{}, 0, len(match.GetTickets())+1)
+		args = append(args, key)
+		for _, ticket := range match.GetTickets() {
+			args = append(args, ticket.GetId())
+		}
+
+		err := redisConn.Send("DEL", key)
+		if err != nil {
+			return errors.Wrapf(err, "error sending match roster removal for match %s", match.GetMatchId())
+		}
+		err = redisConn.Send("RPUSH", args...)
+		if err != nil {
+			return errors.Wrapf(err, "error sending match roster for match %s", match.GetMatchId())
+		}
+		err = redisConn.Send("PEXPIRE", key, retention.Milliseconds())
+		if err != nil {
+			return errors.Wrapf(err, "error sending match roster expiry for match %s", match.GetMatchId())
+		}
+	}
+	return nil
+}
+
+// GetMatchRoster returns the ids of the tickets of a match returned by
+// FetchMatches, in the order of the match.  Returns NotFound if the match's
+// roster was never recorded, or has expired.
+func (rb *redisBackend) GetMatchRoster(ctx context.Context, matchID string) ([]string, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetMatchRoster, id: %s, failed to connect to redis: %v", matchID, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ids, err := redis.Strings(redisConn.Do("LRANGE", matchRosterPrefix+matchID, 0, -1))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get the roster for match id: %s", matchID)
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	if len(ids) == 0 {
+		return nil, status.Errorf(codes.NotFound, "roster of match id: %s not found, it may be older than %s", matchID, config.KeyMatchRosterRetention)
+	}
+	return ids, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package statestore
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestMatchRoster(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "a", Tickets: []*pb.Ticket{{Id: "3"}, {Id: "1"}}},
+		{MatchId: "b", Tickets: []*pb.Ticket{{Id: "2"}}},
+	}))
+
+	// Rosters keep the order of the match's tickets.
+	ids, err := service.GetMatchRoster(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"3", "1"}, ids)
+	ids, err = service.GetMatchRoster(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, ids)
+
+	_, err = service.GetMatchRoster(ctx, "unknown")
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// Nothing is recorded when the retention is zero.
+	cfg.(*viper.Viper).Set("matchRosterRetention", 0)
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "c", Tickets: []*pb.Ticket{{Id: "4"}}},
+	}))
+	_, err = service.GetMatchRoster(ctx, "c")
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
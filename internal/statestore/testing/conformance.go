@@ -0,0 +1,490 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// StoreFactory creates an empty statestore.Service configured by cfg, and a
+// function which closes it.  The service must re-read cfg on every call, as
+// the suite changes some settings after the service is created.
+// NewStoreServiceForTesting is a StoreFactory.
+type StoreFactory func(t *testing.T, cfg config.Mutable) (statestore.Service, func())
+
+// RunConformanceTests checks that the services created by newStore behave the
+// way the Open Match services rely on: ordering of results, all or nothing
+// updates, and expiry of pending, assigned and capacity records.  Each check
+// runs as a subtest against a new service.
+//
+// New backends should run it from a test of their own:
+//
+//	func TestConformance(t *testing.T) {
+//		statestoreTesting.RunConformanceTests(t, newMyStoreForTesting)
+//	}
+func RunConformanceTests(t *testing.T, newStore StoreFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable)
+	}{
+		{"TicketLifecycle", conformTicketLifecycle},
+		{"GetTicketsOrder", conformGetTicketsOrder},
+		{"Index", conformIndex},
+		{"PendingRelease", conformPendingRelease},
+		{"PendingReleaseExpiry", conformPendingReleaseExpiry},
+		{"AssignmentsAllOrNothing", conformAssignmentsAllOrNothing},
+		{"Assignments", conformAssignments},
+		{"AssignedExpiry", conformAssignedExpiry},
+		{"Backfill", conformBackfill},
+		{"BackfillIndex", conformBackfillIndex},
+		{"ServerCapacity", conformServerCapacity},
+		{"TicketTimelineOrder", conformTicketTimelineOrder},
+		{"TicketChanges", conformTicketChanges},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := viper.New()
+			s, closer := newStore(t, cfg)
+			defer closer()
+			defer s.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			tt.run(t, ctx, s, cfg)
+		})
+	}
+}
+
+func requireCode(t *testing.T, want codes.Code, err error) {
+	t.Helper()
+	require.Equal(t, want.String(), status.Code(err).String(), "error: %v", err)
+}
+
+func ticketIDs(tickets []*pb.Ticket) []string {
+	ids := []string{}
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.GetId())
+	}
+	return ids
+}
+
+func createTickets(t *testing.T, ctx context.Context, s statestore.Service, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		ticket := &pb.Ticket{
+			Id:           id,
+			SearchFields: &pb.SearchFields{Tags: []string{"conformance"}},
+			CreateTime:   ptypes.TimestampNow(),
+		}
+		require.NoError(t, s.CreateTicket(ctx, ticket))
+		require.NoError(t, s.IndexTicket(ctx, ticket))
+	}
+}
+
+func conformTicketLifecycle(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	_, err := s.GetTicket(ctx, "a")
+	requireCode(t, codes.NotFound, err)
+
+	want := &pb.Ticket{
+		Id: "a",
+		SearchFields: &pb.SearchFields{
+			DoubleArgs: map[string]float64{"level": 10},
+			StringArgs: map[string]string{"mode": "ranked"},
+			Tags:       []string{"beta"},
+		},
+		CreateTime: ptypes.TimestampNow(),
+	}
+	require.NoError(t, s.CreateTicket(ctx, want))
+	got, err := s.GetTicket(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, got), "got %v", got)
+
+	// Creating a ticket with an existing id overwrites it.
+	want.SearchFields.Tags = []string{"gamma"}
+	require.NoError(t, s.CreateTicket(ctx, want))
+	got, err = s.GetTicket(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, got), "got %v", got)
+
+	require.NoError(t, s.DeleteTicket(ctx, "a"))
+	_, err = s.GetTicket(ctx, "a")
+	requireCode(t, codes.NotFound, err)
+
+	err = s.DeleteTicket(ctx, "a")
+	requireCode(t, codes.NotFound, err)
+}
+
+func conformGetTicketsOrder(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "c", "a", "b")
+
+	// Tickets come back in the order they were asked for, without the missing
+	// ones.
+	tickets, err := s.GetTickets(ctx, []string{"b", "missing", "c", "a"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "a"}, ticketIDs(tickets))
+
+	tickets, err = s.GetTickets(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, tickets)
+}
+
+func conformIndex(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a", "b")
+
+	ids, err := s.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, ids)
+
+	// Deindexed tickets continue to exist.
+	require.NoError(t, s.DeindexTicket(ctx, "a"))
+	ids, err = s.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"b": {}}, ids)
+	_, err = s.GetTicket(ctx, "a")
+	require.NoError(t, err)
+
+	// Deindexing twice succeeds.
+	require.NoError(t, s.DeindexTicket(ctx, "a"))
+}
+
+func conformPendingRelease(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a", "b", "c")
+
+	before := time.Now()
+	require.NoError(t, s.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "m1", MatchProfile: "p1", Tickets: []*pb.Ticket{{Id: "a"}, {Id: "b"}}},
+	}))
+
+	// Pending tickets are not returned as indexed, so they aren't proposed
+	// again.
+	ids, err := s.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"c": {}}, ids)
+
+	ids, err = s.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, ids)
+
+	pending, err := s.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	timeout := config.GetStateStore(cfg).PendingReleaseTimeout
+	for _, p := range pending {
+		require.Equal(t, "m1", p.GetMatchId())
+		require.Equal(t, "p1", p.GetMatchProfile())
+		proposed, err := ptypes.Timestamp(p.GetProposedTime())
+		require.NoError(t, err)
+		release, err := ptypes.Timestamp(p.GetReleaseTime())
+		require.NoError(t, err)
+		require.False(t, proposed.Before(before.Truncate(time.Millisecond)), "proposed %v before %v", proposed, before)
+		require.Equal(t, timeout, release.Sub(proposed))
+	}
+
+	require.NoError(t, s.DeleteTicketsFromPendingRelease(ctx, []string{"a"}))
+	ids, err = s.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"b": {}}, ids)
+	pending, err = s.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "b", pending[0].GetTicketId())
+
+	require.NoError(t, s.ReleaseAllTickets(ctx))
+	ids, err = s.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+	ids, err = s.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}, "c": {}}, ids)
+}
+
+func conformPendingReleaseExpiry(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a")
+	require.NoError(t, s.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "m1", MatchProfile: "p1", Tickets: []*pb.Ticket{{Id: "a"}}},
+	}))
+
+	// Pending tickets become active again after pendingReleaseTimeout.
+	time.Sleep(config.GetStateStore(cfg).PendingReleaseTimeout)
+
+	ids, err := s.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+	pending, err := s.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	ids, err = s.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}}, ids)
+}
+
+func conformAssignmentsAllOrNothing(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a", "b")
+
+	invalid := []*pb.AssignTicketsRequest{
+		{Assignments: []*pb.AssignmentGroup{
+			{TicketIds: []string{"a"}, Assignment: &pb.Assignment{Connection: "1"}},
+			{TicketIds: []string{"b", "a"}, Assignment: &pb.Assignment{Connection: "2"}},
+		}},
+		{Assignments: []*pb.AssignmentGroup{
+			{TicketIds: []string{"a"}, Assignment: &pb.Assignment{Connection: "1"}},
+			{TicketIds: []string{"b"}},
+		}},
+	}
+	for _, req := range invalid {
+		_, _, err := s.UpdateAssignments(ctx, req)
+		requireCode(t, codes.InvalidArgument, err)
+	}
+
+	// Nothing was assigned by the invalid requests.
+	tickets, err := s.GetTickets(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	for _, ticket := range tickets {
+		require.Nil(t, ticket.GetAssignment(), "ticket %s", ticket.GetId())
+	}
+	ids, err := s.GetAssignedIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func conformAssignments(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a", "b")
+
+	resp, assigned, err := s.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{TicketIds: []string{"a", "missing"}, Assignment: &pb.Assignment{Connection: "1"}},
+			{TicketIds: []string{"b"}, Assignment: &pb.Assignment{Connection: "2"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ticketIDs(assigned))
+	require.Len(t, resp.GetFailures(), 1)
+	require.Equal(t, "missing", resp.GetFailures()[0].GetTicketId())
+	require.Equal(t, pb.AssignmentFailure_TICKET_NOT_FOUND, resp.GetFailures()[0].GetCause())
+
+	ticket, err := s.GetTicket(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, "2", ticket.GetAssignment().GetConnection())
+	require.NotNil(t, ticket.GetUpdateTime(), "assigning should stamp the update time")
+
+	ids, err := s.GetAssignedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, ids)
+
+	// GetAssignments calls back until the callback returns an error.
+	errStop := errors.New("stop")
+	var got *pb.Assignment
+	err = s.GetAssignments(ctx, "a", func(a *pb.Assignment) error {
+		got = a
+		return errStop
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, "1", got.GetConnection())
+
+	err = s.GetAssignments(ctx, "missing", func(a *pb.Assignment) error {
+		return nil
+	})
+	requireCode(t, codes.NotFound, err)
+}
+
+func conformAssignedExpiry(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	createTickets(t, ctx, s, "a")
+	_, _, err := s.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{TicketIds: []string{"a"}, Assignment: &pb.Assignment{Connection: "1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// Assigned tickets are no longer tracked after assignedDeleteTimeout.
+	time.Sleep(config.GetStateStore(cfg).AssignedDeleteTimeout)
+
+	ids, err := s.GetAssignedIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func conformBackfill(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	_, _, err := s.GetBackfill(ctx, "bf")
+	requireCode(t, codes.NotFound, err)
+
+	want := &pb.Backfill{
+		Id:           "bf",
+		SearchFields: &pb.SearchFields{Tags: []string{"conformance"}},
+		Generation:   1,
+	}
+	require.NoError(t, s.CreateBackfill(ctx, want, []string{"a", "b"}))
+
+	// Creating a backfill with an existing id fails.
+	err = s.CreateBackfill(ctx, want, nil)
+	requireCode(t, codes.AlreadyExists, err)
+
+	got, ids, err := s.GetBackfill(ctx, "bf")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, got), "got %v", got)
+	require.Equal(t, []string{"a", "b"}, ids)
+
+	want.Generation = 2
+	require.NoError(t, s.UpdateBackfill(ctx, want, []string{"c"}))
+	got, ids, err = s.GetBackfill(ctx, "bf")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, got), "got %v", got)
+	require.Equal(t, []string{"c"}, ids)
+
+	// Only existing backfills can be updated.
+	require.Error(t, s.UpdateBackfill(ctx, &pb.Backfill{Id: "missing"}, nil))
+
+	backfills, err := s.GetBackfills(ctx, []string{"missing", "bf"})
+	require.NoError(t, err)
+	require.Len(t, backfills, 1)
+	require.Equal(t, "bf", backfills[0].GetId())
+
+	require.NoError(t, s.DeleteBackfill(ctx, "bf"))
+	_, _, err = s.GetBackfill(ctx, "bf")
+	requireCode(t, codes.NotFound, err)
+
+	err = s.DeleteBackfill(ctx, "bf")
+	requireCode(t, codes.NotFound, err)
+}
+
+func conformBackfillIndex(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	for i, id := range []string{"bf1", "bf2"} {
+		backfill := &pb.Backfill{Id: id, Generation: int64(i + 1)}
+		require.NoError(t, s.CreateBackfill(ctx, backfill, nil))
+		require.NoError(t, s.IndexBackfill(ctx, backfill))
+	}
+
+	indexed, err := s.GetIndexedBackfills(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"bf1": 1, "bf2": 2}, indexed)
+
+	require.NoError(t, s.DeindexBackfill(ctx, "bf1"))
+	indexed, err = s.GetIndexedBackfills(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"bf2": 2}, indexed)
+}
+
+func conformServerCapacity(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	newCapacity := func(region, fleet string, available int32, age time.Duration) *pb.ServerCapacity {
+		ts, err := ptypes.TimestampProto(time.Now().Add(-age))
+		require.NoError(t, err)
+		return &pb.ServerCapacity{Region: region, Fleet: fleet, Available: available, UpdateTime: ts}
+	}
+
+	timeout := config.GetStateStore(cfg).ServerCapacityTimeout
+	west := newCapacity("us-west1", "a", 3, 0)
+	east := newCapacity("us-east1", "a", 5, 0)
+	expired := newCapacity("us-east1", "b", 7, 2*timeout)
+
+	// Later updates for a region and fleet replace earlier ones.
+	for _, c := range []*pb.ServerCapacity{newCapacity("us-west1", "a", 1, 0), west, east, expired} {
+		require.NoError(t, s.UpdateServerCapacity(ctx, c))
+	}
+
+	got, err := s.GetServerCapacity(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, want := range []*pb.ServerCapacity{west, east} {
+		found := false
+		for _, c := range got {
+			found = found || proto.Equal(want, c)
+		}
+		require.True(t, found, "missing %v", want)
+	}
+
+	got, err = s.GetServerCapacity(ctx, "us-east1")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.True(t, proto.Equal(east, got[0]), "got %v", got[0])
+}
+
+func conformTicketTimelineOrder(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	cfg.Set(config.KeyTicketTimelineRetention, time.Minute)
+
+	types := []pb.TicketEvent_Type{
+		pb.TicketEvent_CREATED,
+		pb.TicketEvent_PROPOSED,
+		pb.TicketEvent_RELEASED,
+		pb.TicketEvent_PROPOSED,
+		pb.TicketEvent_MATCHED,
+		pb.TicketEvent_ASSIGNED,
+	}
+	for _, typ := range types {
+		require.NoError(t, s.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{Type: typ}))
+	}
+
+	// Events come back oldest first.
+	for _, id := range []string{"a", "b"} {
+		events, err := s.GetTicketTimeline(ctx, id)
+		require.NoError(t, err)
+		got := []pb.TicketEvent_Type{}
+		for _, e := range events {
+			got = append(got, e.GetType())
+		}
+		require.Equal(t, types, got, "ticket %s", id)
+	}
+
+	events, err := s.GetTicketTimeline(ctx, "missing")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func conformTicketChanges(t *testing.T, ctx context.Context, s statestore.Service, cfg config.Mutable) {
+	cfg.Set(config.KeyTicketChangeStream, true)
+	cfg.Set(config.KeyPendingReleaseTimeout, time.Minute)
+
+	createTickets(t, ctx, s, "a", "b")
+	require.NoError(t, s.AddTicketsToPendingRelease(ctx, []*pb.Match{{MatchId: "m", Tickets: []*pb.Ticket{{Id: "b"}}}}))
+
+	// The snapshot includes indexed tickets which are pending.
+	snapshot, err := s.SnapshotTickets(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, ticketIDs(snapshot.Indexed))
+	require.Len(t, snapshot.Pending, 1)
+	require.Contains(t, snapshot.Pending, "b")
+
+	createTickets(t, ctx, s, "c")
+	require.NoError(t, s.DeindexTicket(ctx, "a"))
+	require.NoError(t, s.DeleteTicketsFromPendingRelease(ctx, []string{"b"}))
+
+	// Changes come back in the order they were made, after the snapshot.
+	changes, err := s.ReadTicketChanges(ctx, snapshot.Position, 0)
+	require.NoError(t, err)
+	got := []string{}
+	for _, c := range changes {
+		got = append(got, c.Type)
+	}
+	require.Equal(t, []string{statestore.TicketIndexed, statestore.TicketsDeindexed, statestore.TicketsReleased}, got)
+	require.Equal(t, "c", changes[0].Ticket.GetId())
+
+	changes, err = s.ReadTicketChanges(ctx, changes[len(changes)-1].Position, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
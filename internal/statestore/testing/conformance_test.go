@@ -0,0 +1,34 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"testing"
+
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/statestore"
+	"open-match.dev/open-match/internal/telemetry"
+)
+
+func TestRedisConformance(t *testing.T) {
+	RunConformanceTests(t, NewStoreServiceForTesting)
+}
+
+func TestInstrumentedRedisConformance(t *testing.T) {
+	RunConformanceTests(t, func(t *testing.T, cfg config.Mutable) (statestore.Service, func()) {
+		cfg.Set(telemetry.ConfigNameEnableMetrics, true)
+		return NewStoreServiceForTesting(t, cfg)
+	})
+}
@@ -45,14 +45,14 @@ func New(t *testing.T, cfg config.Mutable) func() {
 	cfg.Set("redis.pool.maxActive", PoolMaxActive)
 	cfg.Set("redis.pool.idleTimeout", PoolIdleTimeout)
 	cfg.Set("redis.pool.healthCheckTimeout", PoolHealthCheckTimeout)
-	cfg.Set("backfillLockTimeout", "1m")
-	cfg.Set("pendingReleaseTimeout", pendingReleaseTimeout)
+	cfg.Set(config.KeyBackfillLockTimeout, "1m")
+	cfg.Set(config.KeyPendingReleaseTimeout, pendingReleaseTimeout)
 	cfg.Set("assignedDeleteTimeout", assignedDeleteTimeout)
-	cfg.Set("backoff.initialInterval", InitialInterval)
-	cfg.Set("backoff.randFactor", RandFactor)
-	cfg.Set("backoff.multiplier", Multiplier)
-	cfg.Set("backoff.maxInterval", MaxInterval)
-	cfg.Set("backoff.maxElapsedTime", MaxElapsedTime)
+	cfg.Set(config.KeyBackoffInitialInterval, InitialInterval)
+	cfg.Set(config.KeyBackoffRandFactor, RandFactor)
+	cfg.Set(config.KeyBackoffMultiplier, Multiplier)
+	cfg.Set(config.KeyBackoffMaxInterval, MaxInterval)
+	cfg.Set(config.KeyBackoffMaxElapsedTime, MaxElapsedTime)
 
 	return func() {
 		s.Close()
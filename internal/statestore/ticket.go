@@ -16,33 +16,50 @@ package statestore
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/errorinfo"
 	"open-match.dev/open-match/pkg/pb"
 )
 
 const (
 	allTickets        = "allTickets"
 	proposedTicketIDs = "proposed_ticket_ids"
+	// proposedTicketClaims is a hash of the pending tickets to the
+	// marshaled PendingTicket of the match which claimed them.
+	proposedTicketClaims = "proposed_ticket_claims"
+	// assignedTicketIDs is a sorted set of the assigned tickets, scored by the
+	// time their assignment expires.
+	assignedTicketIDs = "assigned_ticket_ids"
+	// reservedTicketIDs is a sorted set of the reserved tickets, scored by the
+	// time their reservation expires.  Matches aren't allowed to claim them
+	// until then, even if they were proposed from a stale query cache.
+	reservedTicketIDs = "reserved_ticket_ids"
+	// maxClaimAttempts is the number of times claiming tickets is tried when
+	// a reservation is made at the same time.
+	maxClaimAttempts = 3
 )
 
 // CreateTicket creates a new Ticket in the state storage. If the id already exists, it will be overwritten.
 func (rb *redisBackend) CreateTicket(ctx context.Context, ticket *pb.Ticket) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "CreateTicket, id: %s, failed to connect to redis: %v", ticket.GetId(), err)
 	}
 	defer handleConnectionClose(&redisConn)
 
-	value, err := proto.Marshal(ticket)
+	value, err := marshalTicket(rb.cfg, ticket)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to marshal the ticket proto, id: %s", ticket.GetId())
 		return status.Errorf(codes.Internal, "%v", err)
@@ -59,7 +76,7 @@ func (rb *redisBackend) CreateTicket(ctx context.Context, ticket *pb.Ticket) err
 
 // GetTicket gets the Ticket with the specified id from state storage. This method fails if the Ticket does not exist.
 func (rb *redisBackend) GetTicket(ctx context.Context, id string) (*pb.Ticket, error) {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetTicket, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -69,8 +86,7 @@ func (rb *redisBackend) GetTicket(ctx context.Context, id string) (*pb.Ticket, e
 	if err != nil {
 		// Return NotFound if redigo did not find the ticket in storage.
 		if err == redis.ErrNil {
-			msg := fmt.Sprintf("Ticket id: %s not found", id)
-			return nil, status.Error(codes.NotFound, msg)
+			return nil, ticketNotFound(id)
 		}
 
 		err = errors.Wrapf(err, "failed to get the ticket from state storage, id: %s", id)
@@ -78,12 +94,11 @@ func (rb *redisBackend) GetTicket(ctx context.Context, id string) (*pb.Ticket, e
 	}
 
 	if value == nil {
-		msg := fmt.Sprintf("Ticket id: %s not found", id)
-		return nil, status.Error(codes.NotFound, msg)
+		return nil, ticketNotFound(id)
 	}
 
 	ticket := &pb.Ticket{}
-	err = proto.Unmarshal(value, ticket)
+	err = unmarshalTicket(value, ticket)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to unmarshal the ticket proto, id: %s", id)
 		return nil, status.Errorf(codes.Internal, "%v", err)
@@ -94,7 +109,7 @@ func (rb *redisBackend) GetTicket(ctx context.Context, id string) (*pb.Ticket, e
 
 // DeleteTicket removes the Ticket with the specified id from state storage.
 func (rb *redisBackend) DeleteTicket(ctx context.Context, id string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "DeleteTicket, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -107,7 +122,7 @@ func (rb *redisBackend) DeleteTicket(ctx context.Context, id string) error {
 	}
 
 	if value == 0 {
-		return status.Errorf(codes.NotFound, "Ticket id: %s not found", id)
+		return ticketNotFound(id)
 	}
 
 	return nil
@@ -115,53 +130,149 @@ func (rb *redisBackend) DeleteTicket(ctx context.Context, id string) error {
 
 // IndexTicket indexes the Ticket id for the configured index fields.
 func (rb *redisBackend) IndexTicket(ctx context.Context, ticket *pb.Ticket) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "IndexTicket, id: %s, failed to connect to redis: %v", ticket.GetId(), err)
 	}
 	defer handleConnectionClose(&redisConn)
 
+	var change []interface{}
+	if config.GetStateStore(rb.cfg).TicketChangeStream {
+		value, err := marshalTicket(rb.cfg, ticket)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to marshal the ticket proto, id: %s", ticket.GetId())
+			return status.Errorf(codes.Internal, "%v", err)
+		}
+		change = []interface{}{"ticket", value}
+	}
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
 	err = redisConn.Send("SADD", allTickets, ticket.Id)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to add ticket to all tickets, id: %s", ticket.Id)
 		return status.Errorf(codes.Internal, "%v", err)
 	}
+	err = rb.sendTicketChange(redisConn, TicketIndexed, change...)
+	if err != nil {
+		return err
+	}
+
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to add ticket to all tickets, id: %s", ticket.Id)
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return nil
+}
+
+// CreateTickets creates the tickets and adds them to the index, in a single
+// transaction.
+func (rb *redisBackend) CreateTickets(ctx context.Context, tickets []*pb.Ticket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "CreateTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	changeStream := config.GetStateStore(rb.cfg).TicketChangeStream
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+
+	sadd := make([]interface{}, 0, len(tickets)+1)
+	sadd = append(sadd, allTickets)
+	for _, ticket := range tickets {
+		value, err := marshalTicket(rb.cfg, ticket)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to marshal the ticket proto, id: %s", ticket.GetId())
+			return status.Errorf(codes.Internal, "%v", err)
+		}
+
+		err = redisConn.Send("SET", ticket.GetId(), value)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to set the value for ticket, id: %s", ticket.GetId())
+			return status.Errorf(codes.Internal, "%v", err)
+		}
+		if changeStream {
+			err = rb.sendTicketChange(redisConn, TicketIndexed, "ticket", value)
+			if err != nil {
+				return err
+			}
+		}
+		sadd = append(sadd, ticket.GetId())
+	}
+
+	err = redisConn.Send("SADD", sadd...)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to add %d tickets to all tickets", len(tickets))
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create %d tickets", len(tickets))
+		return status.Errorf(codes.Internal, "%v", err)
+	}
 
 	return nil
 }
 
 // DeindexTicket removes the indexing for the specified Ticket. Only the indexes are removed but the Ticket continues to exist.
 func (rb *redisBackend) DeindexTicket(ctx context.Context, id string) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "DeindexTicket, id: %s, failed to connect to redis: %v", id, err)
 	}
 	defer handleConnectionClose(&redisConn)
 
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
 	err = redisConn.Send("SREM", allTickets, id)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to remove ticket from all tickets, id: %s", id)
 		return status.Errorf(codes.Internal, "%v", err)
 	}
+	err = rb.sendTicketChange(redisConn, TicketsDeindexed, "id", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to remove ticket from all tickets, id: %s", id)
+		return status.Errorf(codes.Internal, "%v", err)
+	}
 
 	return nil
 }
 
 // GetIndexedIds returns the ids of all tickets currently indexed.
 func (rb *redisBackend) GetIndexedIDSet(ctx context.Context) (map[string]struct{}, error) {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetIndexedIDSet, failed to connect to redis: %v", err)
 	}
 	defer handleConnectionClose(&redisConn)
 
-	ttl := rb.cfg.GetDuration("pendingReleaseTimeout")
+	ttl := config.GetStateStore(rb.cfg).PendingReleaseTimeout
 	curTime := time.Now()
-	endTimeInt := curTime.Add(time.Hour).UnixNano()
 	startTimeInt := curTime.Add(-ttl).UnixNano()
 
 	// Filter out tickets that are fetched but not assigned within ttl time (ms).
-	idsInPendingReleases, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", proposedTicketIDs, startTimeInt, endTimeInt))
+	// Reserved tickets may be scored in the future, when their reservation
+	// outlasts ttl.
+	idsInPendingReleases, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", proposedTicketIDs, startTimeInt, "+inf"))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "error getting pending release %v", err)
 	}
@@ -182,6 +293,49 @@ func (rb *redisBackend) GetIndexedIDSet(ctx context.Context) (map[string]struct{
 	return r, nil
 }
 
+// GetPendingIDSet returns the ids of all tickets currently pending release.
+func (rb *redisBackend) GetPendingIDSet(ctx context.Context) (map[string]struct{}, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetPendingIDSet, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ttl := config.GetStateStore(rb.cfg).PendingReleaseTimeout
+	curTime := time.Now()
+	ids, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", proposedTicketIDs, curTime.Add(-ttl).UnixNano(), "+inf"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting pending release %v", err)
+	}
+
+	r := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		r[id] = struct{}{}
+	}
+	return r, nil
+}
+
+// GetAssignedIDSet returns the ids of all tickets assigned within
+// assignedDeleteTimeout.  Some may have been deleted since.
+func (rb *redisBackend) GetAssignedIDSet(ctx context.Context) (map[string]struct{}, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetAssignedIDSet, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ids, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", assignedTicketIDs, time.Now().UnixNano(), "+inf"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting assigned ticket ids %v", err)
+	}
+
+	r := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		r[id] = struct{}{}
+	}
+	return r, nil
+}
+
 // GetTickets returns multiple tickets from storage.  Missing tickets are
 // silently ignored.
 func (rb *redisBackend) GetTickets(ctx context.Context, ids []string) ([]*pb.Ticket, error) {
@@ -189,7 +343,7 @@ func (rb *redisBackend) GetTickets(ctx context.Context, ids []string) ([]*pb.Tic
 		return nil, nil
 	}
 
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unavailable, "GetTickets, failed to connect to redis: %v", err)
 	}
@@ -212,7 +366,7 @@ func (rb *redisBackend) GetTickets(ctx context.Context, ids []string) ([]*pb.Tic
 		// Tickets may be deleted by the time we read it from redis.
 		if b != nil {
 			t := &pb.Ticket{}
-			err = proto.Unmarshal(b, t)
+			err = unmarshalTicket(b, t)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to unmarshal ticket from redis, key %s", ids[i])
 				return nil, status.Errorf(codes.Internal, "%v", err)
@@ -231,7 +385,7 @@ func (rb *redisBackend) UpdateAssignments(ctx context.Context, req *pb.AssignTic
 		return resp, []*pb.Ticket{}, nil
 	}
 
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return nil, nil, status.Errorf(codes.Unavailable, "UpdateAssignments, failed to connect to redis: %v", err)
 	}
@@ -275,7 +429,7 @@ func (rb *redisBackend) UpdateAssignments(ctx context.Context, req *pb.AssignTic
 			})
 		} else {
 			t := &pb.Ticket{}
-			err = proto.Unmarshal(ticketByte, t)
+			err = unmarshalTicket(ticketByte, t)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to unmarshal ticket from redis %s", ids[i])
 				return nil, nil, status.Errorf(codes.Internal, "%v", err)
@@ -283,17 +437,22 @@ func (rb *redisBackend) UpdateAssignments(ctx context.Context, req *pb.AssignTic
 			tickets = append(tickets, t)
 		}
 	}
-	assignmentTimeout := getAssignedDeleteTimeout(rb.cfg) / time.Millisecond
+	assignmentTimeout := config.GetStateStore(rb.cfg).AssignedDeleteTimeout / time.Millisecond
 	err = redisConn.Send("MULTI")
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "error starting redis multi")
 	}
 
+	// Only the first assignment of a ticket is counted.
+	unassigned := make(map[string]bool, len(tickets))
+	now := ptypes.TimestampNow()
 	for _, ticket := range tickets {
+		unassigned[ticket.Id] = ticket.Assignment == nil
 		ticket.Assignment = idToA[ticket.Id]
+		ticket.UpdateTime = proto.Clone(now).(*timestamp.Timestamp)
 
 		var ticketByte []byte
-		ticketByte, err = proto.Marshal(ticket)
+		ticketByte, err = marshalTicket(rb.cfg, ticket)
 		if err != nil {
 			return nil, nil, status.Errorf(codes.Internal, "failed to marshal ticket %s", ticket.GetId())
 		}
@@ -314,6 +473,7 @@ func (rb *redisBackend) UpdateAssignments(ctx context.Context, req *pb.AssignTic
 	}
 
 	assignedTickets := make([]*pb.Ticket, 0, len(tickets))
+	firstAssigned := 0
 	for i, ticket := range tickets {
 		v, err := redis.String(wasSet[i], nil)
 		if err == redis.ErrNil {
@@ -330,14 +490,68 @@ func (rb *redisBackend) UpdateAssignments(ctx context.Context, req *pb.AssignTic
 			return nil, nil, status.Errorf(codes.Internal, "unexpected response from redis: %s", v)
 		}
 		assignedTickets = append(assignedTickets, ticket)
+		if unassigned[ticket.Id] {
+			firstAssigned++
+		}
+	}
+
+	// The assignments are already stored, failing to track them only leaves
+	// them out of audit queries and ticket counts.
+	if err = trackAssignedTickets(redisConn, assignedTickets, firstAssigned, config.GetStateStore(rb.cfg)); err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"tickets": len(assignedTickets),
+		}).Error("failed to track assigned tickets")
 	}
 
 	return resp, assignedTickets, nil
 }
 
+// trackAssignedTickets adds the tickets to the assigned ticket set until their
+// assignment expires, and drops the expired ones.  The firstAssigned tickets,
+// which had no assignment before, are counted.
+func trackAssignedTickets(redisConn redis.Conn, tickets []*pb.Ticket, firstAssigned int, settings config.StateStore) error {
+	err := redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+
+	now := time.Now()
+	err = redisConn.Send("ZREMRANGEBYSCORE", assignedTicketIDs, "-inf", now.UnixNano())
+	if err != nil {
+		return errors.Wrap(err, "error sending expired assigned tickets removal")
+	}
+
+	if len(tickets) > 0 {
+		expiry := now.Add(settings.AssignedDeleteTimeout).UnixNano()
+		cmds := make([]interface{}, 0, 2*len(tickets)+1)
+		cmds = append(cmds, assignedTicketIDs)
+		for _, ticket := range tickets {
+			cmds = append(cmds, expiry, ticket.Id)
+		}
+		err = redisConn.Send("ZADD", cmds...)
+		if err != nil {
+			return errors.Wrap(err, "error sending assigned tickets add")
+		}
+	}
+	if settings.TicketCountsRetention > 0 && firstAssigned > 0 {
+		err = sendTicketCount(redisConn, ticketsAssigned, firstAssigned, now, settings.TicketCountsRetention)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrap(err, "failed to track assigned tickets")
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
 // GetAssignments returns the assignment associated with the input ticket id
 func (rb *redisBackend) GetAssignments(ctx context.Context, id string, callback func(*pb.Assignment) error) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "GetAssignments, id: %s, failed to connect to redis: %v", id, err)
 	}
@@ -365,32 +579,394 @@ func (rb *redisBackend) GetAssignments(ctx context.Context, id string, callback
 	return nil
 }
 
-// AddTicketsToPendingRelease appends new proposed tickets to the proposed sorted set with current timestamp
-func (rb *redisBackend) AddTicketsToPendingRelease(ctx context.Context, ids []string) error {
+// AddTicketsToPendingRelease appends the tickets of the matches to the proposed sorted set with current timestamp,
+// and records which match claimed them, and the tickets of each match.  Matches with a reserved ticket are skipped.
+func (rb *redisBackend) AddTicketsToPendingRelease(ctx context.Context, matches []*pb.Match) error {
+	_, err := rb.claimTickets(ctx, "AddTicketsToPendingRelease", "", matches)
+	return err
+}
+
+// ClaimTickets is AddTicketsToPendingRelease, which also records the tickets
+// as claimed by owner until they are confirmed or released.  An empty owner
+// records nothing more.  Returns the ids of the matches which were skipped,
+// because one of their tickets is reserved.
+func (rb *redisBackend) ClaimTickets(ctx context.Context, owner string, matches []*pb.Match) ([]string, error) {
+	return rb.claimTickets(ctx, "ClaimTickets", owner, matches)
+}
+
+func (rb *redisBackend) claimTickets(ctx context.Context, method, owner string, matches []*pb.Match) ([]string, error) {
+	var ids []string
+	for _, match := range matches {
+		for _, ticket := range match.GetTickets() {
+			ids = append(ids, ticket.GetId())
+		}
+	}
 	if len(ids) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
-		return status.Errorf(codes.Unavailable, "AddTicketsToPendingRelease, failed to connect to redis: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "%s, failed to connect to redis: %v", method, err)
 	}
 	defer handleConnectionClose(&redisConn)
 
+	for attempt := 1; ; attempt++ {
+		skipped, ok, err := rb.tryClaimTickets(redisConn, owner, matches, ids)
+		if err != nil || ok {
+			return skipped, err
+		}
+		if attempt == maxClaimAttempts {
+			return nil, status.Errorf(codes.Aborted, "%s, tickets were reserved while they were claimed, retry the claim", method)
+		}
+	}
+}
+
+// tryClaimTickets claims the tickets of the matches which have no reserved
+// tickets.  It returns false, and claims nothing, if a reservation was made
+// while the tickets were checked.
+func (rb *redisBackend) tryClaimTickets(redisConn redis.Conn, owner string, matches []*pb.Match, ids []string) ([]string, bool, error) {
+	// Watch the reservations, so that a ticket reserved while it is checked
+	// isn't claimed.  Only reservations change it, so claims don't contend.
+	_, err := redisConn.Do("WATCH", reservedTicketIDs)
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "error watching reserved tickets %v", err)
+	}
+	reserved, err := reservedTickets(redisConn, ids)
+	if err != nil {
+		return nil, false, err
+	}
+
 	currentTime := time.Now().UnixNano()
-	cmds := make([]interface{}, 0, 2*len(ids)+1)
-	cmds = append(cmds, proposedTicketIDs)
-	for _, id := range ids {
-		cmds = append(cmds, currentTime, id)
+	cmds := []interface{}{proposedTicketIDs}
+	claims := []interface{}{proposedTicketClaims}
+	change := []interface{}{"time", currentTime}
+	var skipped []string
+	claimed := make([]*pb.Match, 0, len(matches))
+	for _, match := range matches {
+		if matchReserved(match, reserved) {
+			skipped = append(skipped, match.GetMatchId())
+			continue
+		}
+		claim, err := proto.Marshal(&pb.PendingTicket{
+			MatchId:      match.GetMatchId(),
+			MatchProfile: match.GetMatchProfile(),
+		})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to marshal the pending ticket claim, match id: %s", match.GetMatchId())
+			return nil, false, status.Errorf(codes.Internal, "%v", err)
+		}
+		for _, ticket := range match.GetTickets() {
+			cmds = append(cmds, currentTime, ticket.GetId())
+			claims = append(claims, ticket.GetId(), claim)
+			change = append(change, "id", ticket.GetId())
+		}
+		claimed = append(claimed, match)
+	}
+	if len(cmds) == 1 {
+		_, err = redisConn.Do("UNWATCH")
+		if err != nil {
+			return nil, false, status.Errorf(codes.Internal, "error unwatching reserved tickets %v", err)
+		}
+		return skipped, true, nil
 	}
 
-	_, err = redisConn.Do("ZADD", cmds...)
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error starting redis multi")
+	}
+	err = redisConn.Send("ZADD", cmds...)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error sending proposed tickets add")
+	}
+	err = redisConn.Send("HSET", claims...)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error sending proposed ticket claims")
+	}
+	err = sendOwnerClaims(redisConn, owner, claimed)
+	if err != nil {
+		return nil, false, err
+	}
+	err = rb.sendMatchRosters(redisConn, claimed)
+	if err != nil {
+		return nil, false, err
+	}
+	err = rb.sendTicketChange(redisConn, TicketsPending, change...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reply, err := redisConn.Do("EXEC")
 	if err != nil {
 		err = errors.Wrap(err, "failed to append proposed tickets to pending release")
-		return status.Error(codes.Internal, err.Error())
+		return nil, false, status.Error(codes.Internal, err.Error())
+	}
+	if reply == nil {
+		return nil, false, nil
 	}
+	return skipped, true, nil
+}
 
-	return nil
+// reservedTickets returns which of the ids have an unexpired reservation.
+func reservedTickets(redisConn redis.Conn, ids []string) (map[string]bool, error) {
+	for _, id := range ids {
+		err := redisConn.Send("ZSCORE", reservedTicketIDs, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending ticket reservation check")
+		}
+	}
+	err := redisConn.Flush()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking ticket reservations %v", err)
+	}
+
+	now := float64(time.Now().UnixNano())
+	reserved := map[string]bool{}
+	for _, id := range ids {
+		expiry, err := redis.Float64(redisConn.Receive())
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking ticket reservation %v", err)
+		}
+		if expiry > now {
+			reserved[id] = true
+		}
+	}
+	return reserved, nil
+}
+
+func matchReserved(match *pb.Match, reserved map[string]bool) bool {
+	for _, ticket := range match.GetTickets() {
+		if reserved[ticket.GetId()] {
+			return true
+		}
+	}
+	return false
+}
+
+// ReserveTickets moves the active tickets of the request to the pending release
+// until the reservation's ttl passes, and records the reservation which claimed
+// them.  Tickets which are missing, not indexed or already pending are
+// returned as failures.
+func (rb *redisBackend) ReserveTickets(ctx context.Context, req *pb.ReserveTicketsRequest) (*pb.ReserveTicketsResponse, error) {
+	ttl, err := ptypes.Duration(req.GetTtl())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid reservation ttl: %v", err)
+	}
+	resp := &pb.ReserveTicketsResponse{}
+	if len(req.GetTicketIds()) == 0 {
+		return resp, nil
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "ReserveTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	// Watch the pending release, the reservations, the index and the
+	// tickets, so that the reservation fails rather than claims tickets which
+	// a match proposes, or which are reserved, deindexed or deleted, while
+	// they are checked.
+	watch := []interface{}{proposedTicketIDs, reservedTicketIDs, allTickets}
+	for _, id := range req.GetTicketIds() {
+		watch = append(watch, id)
+	}
+	_, err = redisConn.Do("WATCH", watch...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error watching pending release %v", err)
+	}
+
+	for _, id := range req.GetTicketIds() {
+		err = redisConn.Send("EXISTS", id)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending ticket exists check")
+		}
+		err = redisConn.Send("SISMEMBER", allTickets, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending ticket indexed check")
+		}
+		err = redisConn.Send("ZSCORE", proposedTicketIDs, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "error sending ticket pending check")
+		}
+	}
+	err = redisConn.Flush()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking tickets to reserve %v", err)
+	}
+
+	// Pending tickets are released pendingReleaseTimeout after their score, so
+	// score reserved tickets to be released once the ttl passes instead.
+	now := time.Now()
+	pendingReleaseTimeout := config.GetStateStore(rb.cfg).PendingReleaseTimeout
+	score := now.Add(ttl - pendingReleaseTimeout).UnixNano()
+	proposedTime, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	claim, err := proto.Marshal(&pb.PendingTicket{
+		ReservationId: req.GetReservationId(),
+		ProposedTime:  proposedTime,
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal the pending ticket claim, reservation id: %s", req.GetReservationId())
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	expiry := now.Add(ttl).UnixNano()
+	cmds := []interface{}{proposedTicketIDs}
+	reservations := []interface{}{reservedTicketIDs}
+	claims := []interface{}{proposedTicketClaims}
+	change := []interface{}{"time", score}
+	for _, id := range req.GetTicketIds() {
+		exists, err := redis.Bool(redisConn.Receive())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking ticket exists %v", err)
+		}
+		indexed, err := redis.Bool(redisConn.Receive())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking ticket indexed %v", err)
+		}
+		proposed, err := redis.Float64(redisConn.Receive())
+		if err != nil && err != redis.ErrNil {
+			return nil, status.Errorf(codes.Internal, "error checking ticket pending %v", err)
+		}
+		pending := err == nil && proposed >= float64(now.Add(-pendingReleaseTimeout).UnixNano())
+
+		switch {
+		case !exists:
+			resp.Failures = append(resp.Failures, &pb.ReservationFailure{
+				TicketId: id,
+				Cause:    pb.ReservationFailure_TICKET_NOT_FOUND,
+			})
+		case !indexed || pending:
+			resp.Failures = append(resp.Failures, &pb.ReservationFailure{
+				TicketId: id,
+				Cause:    pb.ReservationFailure_TICKET_NOT_ACTIVE,
+			})
+		default:
+			resp.TicketIds = append(resp.TicketIds, id)
+			cmds = append(cmds, score, id)
+			reservations = append(reservations, expiry, id)
+			claims = append(claims, id, claim)
+			change = append(change, "id", id)
+		}
+	}
+	if len(resp.TicketIds) == 0 {
+		_, err = redisConn.Do("UNWATCH")
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error unwatching pending release %v", err)
+		}
+		return resp, nil
+	}
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting redis multi")
+	}
+	err = redisConn.Send("ZADD", cmds...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending reserved tickets add")
+	}
+	err = redisConn.Send("HSET", claims...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending reserved ticket claims")
+	}
+	err = redisConn.Send("ZREMRANGEBYSCORE", reservedTicketIDs, "-inf", now.UnixNano())
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending expired reservations removal")
+	}
+	err = redisConn.Send("ZADD", reservations...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending reservations add")
+	}
+	err = rb.sendTicketChange(redisConn, TicketsPending, change...)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrap(err, "failed to add reserved tickets to pending release")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if reply == nil {
+		return nil, status.Error(codes.Aborted, "the pending release changed while the tickets were reserved, retry the reservation")
+	}
+
+	return resp, nil
+}
+
+// GetPendingTickets returns the tickets currently pending release, and the
+// matches or reservations which claimed them, ordered by the time they are
+// released.
+func (rb *redisBackend) GetPendingTickets(ctx context.Context) ([]*pb.PendingTicket, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetPendingTickets, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	ttl := config.GetStateStore(rb.cfg).PendingReleaseTimeout
+	curTime := time.Now()
+	values, err := redis.Strings(redisConn.Do("ZRANGEBYSCORE", proposedTicketIDs, curTime.Add(-ttl).UnixNano(), "+inf", "WITHSCORES"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting pending release %v", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	pending := make([]*pb.PendingTicket, 0, len(values)/2)
+	args := []interface{}{proposedTicketClaims}
+	for i := 0; i+1 < len(values); i += 2 {
+		proposed, err := strconv.ParseInt(values[i+1], 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error while parsing proposed time into number: %v", err)
+		}
+		proposedTime := time.Unix(0, proposed)
+
+		p := &pb.PendingTicket{TicketId: values[i]}
+		p.ProposedTime, err = ptypes.TimestampProto(proposedTime)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		p.ReleaseTime, err = ptypes.TimestampProto(proposedTime.Add(ttl))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		pending = append(pending, p)
+		args = append(args, values[i])
+	}
+
+	claims, err := redis.ByteSlices(redisConn.Do("HMGET", args...))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting pending ticket claims %v", err)
+	}
+	for i, claim := range claims {
+		// Tickets proposed before claims were recorded have none.
+		if claim == nil {
+			continue
+		}
+		c := &pb.PendingTicket{}
+		if err = proto.Unmarshal(claim, c); err != nil {
+			err = errors.Wrapf(err, "failed to unmarshal the pending ticket claim, id: %s", pending[i].TicketId)
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		pending[i].MatchId = c.MatchId
+		pending[i].MatchProfile = c.MatchProfile
+		pending[i].ReservationId = c.ReservationId
+		// Reserved tickets are scored by when they are released, rather than
+		// when they were reserved.
+		if c.ProposedTime != nil {
+			pending[i].ProposedTime = c.ProposedTime
+		}
+	}
+
+	return pending, nil
 }
 
 // DeleteTicketsFromPendingRelease deletes tickets from the proposed sorted set
@@ -399,7 +975,7 @@ func (rb *redisBackend) DeleteTicketsFromPendingRelease(ctx context.Context, ids
 		return nil
 	}
 
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "DeleteTicketsFromPendingRelease, failed to connect to redis: %v", err)
 	}
@@ -407,11 +983,35 @@ func (rb *redisBackend) DeleteTicketsFromPendingRelease(ctx context.Context, ids
 
 	cmds := make([]interface{}, 0, len(ids)+1)
 	cmds = append(cmds, proposedTicketIDs)
+	claims := make([]interface{}, 0, len(ids)+1)
+	claims = append(claims, proposedTicketClaims)
 	for _, id := range ids {
 		cmds = append(cmds, id)
+		claims = append(claims, id)
+	}
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+	err = redisConn.Send("ZREM", cmds...)
+	if err != nil {
+		return errors.Wrap(err, "error sending proposed tickets removal")
+	}
+	err = redisConn.Send("ZREM", append([]interface{}{reservedTicketIDs}, cmds[1:]...)...)
+	if err != nil {
+		return errors.Wrap(err, "error sending ticket reservations removal")
+	}
+	err = redisConn.Send("HDEL", claims...)
+	if err != nil {
+		return errors.Wrap(err, "error sending proposed ticket claims removal")
+	}
+	err = rb.sendTicketChange(redisConn, TicketsReleased, ticketIDFields(ids)...)
+	if err != nil {
+		return err
 	}
 
-	_, err = redisConn.Do("ZREM", cmds...)
+	_, err = redisConn.Do("EXEC")
 	if err != nil {
 		err = errors.Wrap(err, "failed to delete proposed tickets from pending release")
 		return status.Error(codes.Internal, err.Error())
@@ -421,44 +1021,40 @@ func (rb *redisBackend) DeleteTicketsFromPendingRelease(ctx context.Context, ids
 }
 
 func (rb *redisBackend) ReleaseAllTickets(ctx context.Context) error {
-	redisConn, err := rb.redisPool.GetContext(ctx)
+	redisConn, err := rb.getConn(ctx)
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "ReleaseAllTickets, failed to connect to redis: %v", err)
 	}
 	defer handleConnectionClose(&redisConn)
 
-	_, err = redisConn.Do("DEL", proposedTicketIDs)
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+	err = redisConn.Send("DEL", proposedTicketIDs, proposedTicketClaims, reservedTicketIDs)
+	if err != nil {
+		return errors.Wrap(err, "error sending pending release removal")
+	}
+	err = rb.sendTicketChange(redisConn, AllTicketsReleased)
+	if err != nil {
+		return err
+	}
+
+	_, err = redisConn.Do("EXEC")
 	return err
 }
 
 func (rb *redisBackend) newConstantBackoffStrategy() backoff.BackOff {
-	backoffStrat := backoff.NewConstantBackOff(rb.cfg.GetDuration("backoff.initialInterval"))
+	backoffStrat := backoff.NewConstantBackOff(config.GetBackoff(rb.cfg).InitialInterval)
 	return backoff.BackOff(backoffStrat)
 }
 
 // TODO: add cache the backoff object
 // nolint: unused
 func (rb *redisBackend) newExponentialBackoffStrategy() backoff.BackOff {
-	backoffStrat := backoff.NewExponentialBackOff()
-	backoffStrat.InitialInterval = rb.cfg.GetDuration("backoff.initialInterval")
-	backoffStrat.RandomizationFactor = rb.cfg.GetFloat64("backoff.randFactor")
-	backoffStrat.Multiplier = rb.cfg.GetFloat64("backoff.multiplier")
-	backoffStrat.MaxInterval = rb.cfg.GetDuration("backoff.maxInterval")
-	backoffStrat.MaxElapsedTime = rb.cfg.GetDuration("backoff.maxElapsedTime")
-	return backoff.BackOff(backoffStrat)
+	return backoff.BackOff(config.GetBackoff(rb.cfg).NewExponentialBackOff())
 }
 
-func getAssignedDeleteTimeout(cfg config.View) time.Duration {
-	const (
-		name = "assignedDeleteTimeout"
-		// Default timeout to delete tickets after assignment. This value
-		// will be used if assignedDeleteTimeout is not configured.
-		defaultAssignedDeleteTimeout time.Duration = 10 * time.Minute
-	)
-
-	if !cfg.IsSet(name) {
-		return defaultAssignedDeleteTimeout
-	}
-
-	return cfg.GetDuration(name)
+func ticketNotFound(id string) error {
+	return errorinfo.Errorf(codes.NotFound, errorinfo.ReasonTicketNotFound, map[string]string{errorinfo.MetadataTicketID: id}, "Ticket id: %s not found", id)
 }
@@ -25,6 +25,7 @@ import (
 
 	"github.com/Bose/minisentinel"
 	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/gomodule/redigo/redis"
 	"github.com/rs/xid"
 	"github.com/spf13/viper"
@@ -631,6 +632,43 @@ func TestIndexTicket(t *testing.T) {
 	require.Contains(t, status.Convert(err).Message(), "IndexTicket, id: 12345, failed to connect to redis:")
 }
 
+func TestCreateTickets(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+
+	ctx := utilTesting.NewContext(t)
+
+	require.NoError(t, service.CreateTickets(ctx, nil))
+	require.NoError(t, service.CreateTickets(ctx, []*pb.Ticket{
+		{Id: "mockTicketID-0", SearchFields: &pb.SearchFields{Tags: []string{"a"}}},
+		{Id: "mockTicketID-1"},
+	}))
+
+	ticket, err := service.GetTicket(ctx, "mockTicketID-0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, ticket.GetSearchFields().GetTags())
+	_, err = service.GetTicket(ctx, "mockTicketID-1")
+	require.NoError(t, err)
+
+	ids, err := service.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Contains(t, ids, "mockTicketID-0")
+	require.Contains(t, ids, "mockTicketID-1")
+
+	// pass an expired context, err expected
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service = New(cfg)
+	err = service.CreateTickets(ctx, []*pb.Ticket{{Id: "12345"}})
+	require.Error(t, err)
+	require.Equal(t, codes.Unavailable.String(), status.Convert(err).Code().String())
+	require.Contains(t, status.Convert(err).Message(), "CreateTickets, failed to connect to redis:")
+}
+
 func TestDeindexTicket(t *testing.T) {
 	cfg, closer := createRedis(t, false, "")
 	defer closer()
@@ -713,6 +751,65 @@ func TestGetIndexedIDSet(t *testing.T) {
 	require.Contains(t, status.Convert(err).Message(), "GetIndexedIDSet, failed to connect to redis:")
 }
 
+func TestGetPendingIDSet(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	_, ids := generateTickets(ctx, t, service, 2)
+
+	pending, err := service.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, pendingMatches(ids[:1])))
+	pending, err = service.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{ids[0]: {}}, pending)
+
+	// Sleep until the pending release expired.
+	time.Sleep(cfg.GetDuration("pendingReleaseTimeout"))
+	pending, err = service.GetPendingIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestGetAssignedIDSet(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	cfg.(*viper.Viper).Set("assignedDeleteTimeout", 200*time.Millisecond)
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	_, ids := generateTickets(ctx, t, service, 2)
+
+	_, assigned, err := service.UpdateAssignments(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{
+				TicketIds:  []string{ids[0], "unknown"},
+				Assignment: &pb.Assignment{Connection: "1.2.3.4:5678"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, assigned, 1)
+
+	set, err := service.GetAssignedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{ids[0]: {}}, set)
+
+	// Sleep until the assignment expired.
+	time.Sleep(200 * time.Millisecond)
+	set, err = service.GetAssignedIDSet(ctx)
+	require.NoError(t, err)
+	require.Empty(t, set)
+}
+
 func TestGetTickets(t *testing.T) {
 	cfg, closer := createRedis(t, false, "")
 	defer closer()
@@ -869,25 +966,192 @@ func TestAddTicketsToPendingRelease(t *testing.T) {
 	verifyTickets(service, tickets)
 
 	// Add 1st ticket to pending release state
-	require.NoError(t, service.AddTicketsToPendingRelease(ctx, ids[:1]))
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, pendingMatches(ids[:1])))
 
 	// Verify 1 ticket is indexed
 	verifyTickets(service, tickets[1:2])
 
-	// Pass an empty ids slice
-	empty := []string{}
+	// Pass an empty matches slice
+	empty := []*pb.Match{}
 	require.NoError(t, service.AddTicketsToPendingRelease(ctx, empty))
 
 	// Pass an expired context, err expected
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 	service = New(cfg)
-	err := service.AddTicketsToPendingRelease(ctx, ids)
+	err := service.AddTicketsToPendingRelease(ctx, pendingMatches(ids))
 	require.Error(t, err)
 	require.Equal(t, codes.Unavailable.String(), status.Convert(err).Code().String())
 	require.Contains(t, status.Convert(err).Message(), "AddTicketsToPendingRelease, failed to connect to redis:")
 }
 
+func TestGetPendingTickets(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	tickets, ids := generateTickets(ctx, t, service, 3)
+
+	pending, err := service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "a", MatchProfile: "profile-1", Tickets: tickets[:2]},
+	}))
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, []*pb.Match{
+		{MatchId: "b", MatchProfile: "profile-2", Tickets: tickets[2:]},
+	}))
+
+	pending, err = service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	claims := map[string]string{}
+	for _, p := range pending {
+		claims[p.TicketId] = p.MatchId + "/" + p.MatchProfile
+		proposed, err := ptypes.Timestamp(p.ProposedTime)
+		require.NoError(t, err)
+		release, err := ptypes.Timestamp(p.ReleaseTime)
+		require.NoError(t, err)
+		require.Equal(t, cfg.GetDuration("pendingReleaseTimeout"), release.Sub(proposed))
+	}
+	require.Equal(t, map[string]string{
+		ids[0]: "a/profile-1",
+		ids[1]: "a/profile-1",
+		ids[2]: "b/profile-2",
+	}, claims)
+
+	// Released tickets are no longer pending.
+	require.NoError(t, service.DeleteTicketsFromPendingRelease(ctx, ids[:1]))
+	pending, err = service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	require.NoError(t, service.ReleaseAllTickets(ctx))
+	pending, err = service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestReserveTickets(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	_, ids := generateTickets(ctx, t, service, 5)
+	require.NoError(t, service.AddTicketsToPendingRelease(ctx, pendingMatches(ids[:1])))
+	require.NoError(t, service.DeindexTicket(ctx, ids[1]))
+
+	resp, err := service.ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds:     []string{ids[0], ids[1], ids[2], ids[3], "missing"},
+		Ttl:           ptypes.DurationProto(time.Hour),
+		ReservationId: "bracket-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[2], ids[3]}, resp.TicketIds)
+	require.Equal(t, []*pb.ReservationFailure{
+		{TicketId: ids[0], Cause: pb.ReservationFailure_TICKET_NOT_ACTIVE},
+		{TicketId: ids[1], Cause: pb.ReservationFailure_TICKET_NOT_ACTIVE},
+		{TicketId: "missing", Cause: pb.ReservationFailure_TICKET_NOT_FOUND},
+	}, resp.Failures)
+
+	// Reserved tickets outlast pendingReleaseTimeout.
+	time.Sleep(cfg.GetDuration("pendingReleaseTimeout"))
+	indexed, err := service.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{ids[0]: {}, ids[4]: {}}, indexed)
+
+	pending, err := service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	for _, p := range pending {
+		require.Equal(t, "bracket-1", p.ReservationId)
+		require.Empty(t, p.MatchId)
+		proposed, err := ptypes.Timestamp(p.ProposedTime)
+		require.NoError(t, err)
+		release, err := ptypes.Timestamp(p.ReleaseTime)
+		require.NoError(t, err)
+		require.InDelta(t, time.Hour, release.Sub(proposed), float64(time.Millisecond))
+	}
+
+	// Matches proposed from a stale query cache don't claim reserved tickets.
+	skipped, err := service.ClaimTickets(ctx, "backend", []*pb.Match{
+		{MatchId: "stale", Tickets: []*pb.Ticket{{Id: ids[0]}, {Id: ids[2]}}},
+		{MatchId: "fresh", Tickets: []*pb.Ticket{{Id: ids[0]}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"stale"}, skipped)
+	pending, err = service.GetPendingTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	for _, p := range pending {
+		if p.TicketId == ids[0] {
+			require.Equal(t, "fresh", p.MatchId)
+		} else {
+			require.Equal(t, "bracket-1", p.ReservationId)
+		}
+	}
+
+	// Reserved tickets can't be reserved again until released.
+	resp, err = service.ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds: ids[2:3],
+		Ttl:       ptypes.DurationProto(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.TicketIds)
+	require.Len(t, resp.Failures, 1)
+
+	require.NoError(t, service.DeleteTicketsFromPendingRelease(ctx, ids[2:3]))
+	indexed, err = service.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Contains(t, indexed, ids[2])
+	skipped, err = service.ClaimTickets(ctx, "backend", []*pb.Match{
+		{MatchId: "released", Tickets: []*pb.Ticket{{Id: ids[2]}}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+
+	// Reservations expire after their ttl.
+	resp, err = service.ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds: ids[4:],
+		Ttl:       ptypes.DurationProto(10 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ids[4:], resp.TicketIds)
+	indexed, err = service.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, indexed, ids[4])
+	time.Sleep(20 * time.Millisecond)
+	indexed, err = service.GetIndexedIDSet(ctx)
+	require.NoError(t, err)
+	require.Contains(t, indexed, ids[4])
+
+	// Pass an expired context, err expected
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service = New(cfg)
+	_, err = service.ReserveTickets(ctx, &pb.ReserveTicketsRequest{TicketIds: ids, Ttl: ptypes.DurationProto(time.Hour)})
+	require.Error(t, err)
+	require.Equal(t, codes.Unavailable.String(), status.Convert(err).Code().String())
+	require.Contains(t, status.Convert(err).Message(), "ReserveTickets, failed to connect to redis:")
+}
+
+// pendingMatches returns a match of the tickets with the ids, to add them to
+// the pending release.
+func pendingMatches(ids []string) []*pb.Match {
+	match := &pb.Match{MatchId: "pending"}
+	for _, id := range ids {
+		match.Tickets = append(match.Tickets, &pb.Ticket{Id: id})
+	}
+	return []*pb.Match{match}
+}
+
 func testConnect(t *testing.T, withSentinel bool, withPassword string) {
 	cfg, closer := createRedis(t, withSentinel, withPassword)
 	defer closer()
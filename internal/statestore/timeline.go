@@ -0,0 +1,140 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+const ticketTimelinePrefix = "ticketTimeline:"
+
+// LogTicketEvent records the event with RecordTicketEvent.  Timelines and
+// counts are only used for debugging and dashboards, so failures are logged
+// rather than returned.
+func LogTicketEvent(ctx context.Context, s Service, ids []string, event *pb.TicketEvent) {
+	err := s.RecordTicketEvent(ctx, ids, event)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"type":       event.GetType(),
+			"ticket_ids": ids,
+		}).Warning("failed to record ticket event")
+	}
+}
+
+// RecordTicketEvent appends the event to the timeline of each of the tickets,
+// and counts the tickets in the minute of the event.  The event's time is set
+// to now if unset.  Does nothing unless ticketTimelineRetention or
+// ticketCountsRetention is configured.
+func (rb *redisBackend) RecordTicketEvent(ctx context.Context, ids []string, event *pb.TicketEvent) error {
+	settings := config.GetStateStore(rb.cfg)
+	retention := settings.TicketTimelineRetention
+	countsRetention := settings.TicketCountsRetention
+	counter, counted := ticketEventCounters[event.GetType()]
+	if countsRetention <= 0 {
+		counted = false
+	}
+	if (retention <= 0 && !counted) || len(ids) == 0 {
+		return nil
+	}
+	timelineIDs := ids
+	if retention <= 0 {
+		timelineIDs = nil
+	}
+
+	if event.Time == nil {
+		event.Time = ptypes.TimestampNow()
+	}
+	eventTime, err := ptypes.Timestamp(event.Time)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ticket event time: %v", err)
+	}
+	value, err := proto.Marshal(event)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal the ticket event proto, type: %s", event.GetType())
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "RecordTicketEvent, failed to connect to redis: %v", err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	err = redisConn.Send("MULTI")
+	if err != nil {
+		return errors.Wrap(err, "error starting redis multi")
+	}
+	for _, id := range timelineIDs {
+		err = redisConn.Send("RPUSH", ticketTimelinePrefix+id, value)
+		if err != nil {
+			return errors.Wrapf(err, "error sending ticket event for ticket %s", id)
+		}
+		err = redisConn.Send("PEXPIRE", ticketTimelinePrefix+id, retention.Milliseconds())
+		if err != nil {
+			return errors.Wrapf(err, "error sending ticket timeline expiry for ticket %s", id)
+		}
+	}
+	if counted {
+		err = sendTicketCount(redisConn, counter, len(ids), eventTime, countsRetention)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to record ticket event, type: %s", event.GetType())
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	return nil
+}
+
+// GetTicketTimeline returns the events recorded for the ticket, oldest first.
+func (rb *redisBackend) GetTicketTimeline(ctx context.Context, id string) ([]*pb.TicketEvent, error) {
+	redisConn, err := rb.getConn(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetTicketTimeline, id: %s, failed to connect to redis: %v", id, err)
+	}
+	defer handleConnectionClose(&redisConn)
+
+	values, err := redis.ByteSlices(redisConn.Do("LRANGE", ticketTimelinePrefix+id, 0, -1))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get the timeline for ticket id: %s", id)
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	events := make([]*pb.TicketEvent, 0, len(values))
+	for _, value := range values {
+		event := &pb.TicketEvent{}
+		err = proto.Unmarshal(value, event)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to unmarshal the ticket event proto, id: %s", id)
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
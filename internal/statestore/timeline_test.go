@@ -0,0 +1,71 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	utilTesting "open-match.dev/open-match/internal/util/testing"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestTicketTimeline(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	service := New(cfg)
+	require.NotNil(t, service)
+	defer service.Close()
+	ctx := utilTesting.NewContext(t)
+
+	// Nothing is recorded until a retention is configured.
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED}))
+	events, err := service.GetTicketTimeline(ctx, "a")
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	cfg.(*viper.Viper).Set("ticketTimelineRetention", time.Minute)
+
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED}))
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"b"}, &pb.TicketEvent{Type: pb.TicketEvent_CREATED}))
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a", "b"}, &pb.TicketEvent{
+		Type:         pb.TicketEvent_PROPOSED,
+		MatchId:      "m",
+		MatchProfile: "p",
+	}))
+	require.NoError(t, service.RecordTicketEvent(ctx, []string{"a"}, &pb.TicketEvent{Type: pb.TicketEvent_ASSIGNED}))
+
+	events, err = service.GetTicketTimeline(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, pb.TicketEvent_CREATED, events[0].GetType())
+	require.Equal(t, pb.TicketEvent_PROPOSED, events[1].GetType())
+	require.Equal(t, "m", events[1].GetMatchId())
+	require.Equal(t, "p", events[1].GetMatchProfile())
+	require.Equal(t, pb.TicketEvent_ASSIGNED, events[2].GetType())
+	for _, e := range events {
+		require.NotNil(t, e.GetTime())
+	}
+
+	events, err = service.GetTicketTimeline(ctx, "b")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	events, err = service.GetTicketTimeline(ctx, "unknown")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
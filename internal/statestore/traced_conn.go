@@ -0,0 +1,179 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+	"open-match.dev/open-match/internal/config"
+)
+
+// getConn gets a connection from the pool whose commands are traced as
+// children of the span of ctx, and logged when slower than
+// slowCommandThreshold.
+func (rb *redisBackend) getConn(ctx context.Context) (redis.Conn, error) {
+	conn, err := rb.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{
+		Conn: conn,
+		ctx:  ctx,
+		slow: config.GetStateStore(rb.cfg).SlowCommandThreshold,
+	}, nil
+}
+
+// tracedConn records a span for each command, or for each pipeline of sent
+// commands.  A pipeline's span starts when it is flushed, and ends when its
+// last reply is received, or with the Do which flushes it.
+type tracedConn struct {
+	redis.Conn
+	ctx  context.Context
+	slow time.Duration
+
+	// sent are the names of the commands sent and not yet flushed.
+	sent []string
+
+	// The pipeline flushed and waiting for replies, if any.
+	flushed  []string
+	received int
+	start    time.Time
+	span     *trace.Span
+}
+
+func (c *tracedConn) Send(cmd string, args ...interface{}) error {
+	c.sent = append(c.sent, cmd)
+	return c.Conn.Send(cmd, args...)
+}
+
+func (c *tracedConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	commands := c.doCommands(cmd)
+	start := time.Now()
+	_, span := trace.StartSpan(c.ctx, spanName(commands))
+	reply, err := c.Conn.Do(cmd, args...)
+	c.end(span, start, commands, err, true)
+	return reply, err
+}
+
+// DoWithTimeout is used for blocking commands, which wait up to the timeout
+// by design, so they are traced but never logged as slow.
+func (c *tracedConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	commands := c.doCommands(cmd)
+	start := time.Now()
+	_, span := trace.StartSpan(c.ctx, spanName(commands))
+	reply, err := redis.DoWithTimeout(c.Conn, timeout, cmd, args...)
+	c.end(span, start, commands, err, false)
+	return reply, err
+}
+
+func (c *tracedConn) Flush() error {
+	if len(c.sent) == 0 {
+		return c.Conn.Flush()
+	}
+	if c.span == nil {
+		c.start = time.Now()
+		_, c.span = trace.StartSpan(c.ctx, "statestore/redis.pipeline")
+	}
+	c.flushed = append(c.flushed, c.sent...)
+	c.sent = nil
+
+	err := c.Conn.Flush()
+	if err != nil {
+		c.endPipeline(err)
+	}
+	return err
+}
+
+func (c *tracedConn) Receive() (interface{}, error) {
+	reply, err := c.Conn.Receive()
+	c.receivedReply(err)
+	return reply, err
+}
+
+func (c *tracedConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	reply, err := redis.ReceiveWithTimeout(c.Conn, timeout)
+	c.receivedReply(err)
+	return reply, err
+}
+
+func (c *tracedConn) receivedReply(err error) {
+	if c.span == nil {
+		return
+	}
+	c.received++
+	if c.received >= len(c.flushed) {
+		c.endPipeline(err)
+	}
+}
+
+func (c *tracedConn) endPipeline(err error) {
+	c.end(c.span, c.start, c.flushed, err, true)
+	c.flushed = nil
+	c.received = 0
+	c.span = nil
+}
+
+// doCommands returns the names of the commands a Do of cmd sends, including
+// those sent and not yet flushed.  Do("") only flushes them.
+func (c *tracedConn) doCommands(cmd string) []string {
+	commands := c.sent
+	if cmd != "" {
+		commands = append(commands, cmd)
+	}
+	c.sent = nil
+	// Do also reads the replies of a pipeline flushed before.
+	if c.span != nil {
+		commands = append(c.flushed, commands...)
+		c.span.End()
+		c.flushed = nil
+		c.received = 0
+		c.span = nil
+	}
+	return commands
+}
+
+func (c *tracedConn) end(span *trace.Span, start time.Time, commands []string, err error, logSlow bool) {
+	elapsed := time.Since(start)
+	if len(commands) > 1 {
+		span.AddAttributes(trace.StringAttribute("redis.commands", strings.Join(commands, " ")))
+	}
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	span.End()
+
+	if !logSlow || c.slow <= 0 || elapsed < c.slow {
+		return
+	}
+	redisLogger.WithFields(logrus.Fields{
+		"commands": commands,
+		"duration": elapsed.String(),
+		"traceId":  span.SpanContext().TraceID.String(),
+	}).Warning("slow redis command")
+}
+
+// spanName names the span of a single command after it, and of several
+// commands after the last, which is usually the EXEC of a transaction.
+func spanName(commands []string) string {
+	if len(commands) == 0 {
+		return "statestore/redis.pipeline"
+	}
+	return "statestore/redis." + commands[len(commands)-1]
+}
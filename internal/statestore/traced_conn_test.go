@@ -0,0 +1,100 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statestore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/trace"
+	"open-match.dev/open-match/internal/config"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func TestTracedConn(t *testing.T) {
+	cfg, closer := createRedis(t, false, "")
+	defer closer()
+	cfg.(*viper.Viper).Set(config.KeySlowCommandThreshold, time.Nanosecond)
+	rb := newRedis(cfg).(*redisBackend)
+	defer rb.Close()
+
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	hooks := redisLogger.Logger.ReplaceHooks(make(logrus.LevelHooks))
+	defer redisLogger.Logger.ReplaceHooks(hooks)
+	hook := logrusTest.NewLocal(redisLogger.Logger)
+
+	ctx, parent := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	conn, err := rb.getConn(ctx)
+	require.NoError(t, err)
+
+	_, err = conn.Do("SET", "a", 1)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Send("MULTI"))
+	require.NoError(t, conn.Send("INCR", "a"))
+	_, err = conn.Do("EXEC")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Send("GET", "a"))
+	require.NoError(t, conn.Send("EXISTS", "a"))
+	require.NoError(t, conn.Flush())
+	_, err = conn.Receive()
+	require.NoError(t, err)
+	_, err = conn.Receive()
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Close())
+	parent.End()
+
+	var names []string
+	var commands []interface{}
+	recorder.mu.Lock()
+	for _, s := range recorder.spans {
+		if s.ParentSpanID == parent.SpanContext().SpanID {
+			names = append(names, s.Name)
+			commands = append(commands, s.Attributes["redis.commands"])
+		}
+	}
+	recorder.mu.Unlock()
+	require.Equal(t, []string{"statestore/redis.SET", "statestore/redis.EXEC", "statestore/redis.pipeline"}, names)
+	require.Equal(t, []interface{}{nil, "MULTI INCR EXEC", "GET EXISTS"}, commands)
+
+	var slow int
+	for _, e := range hook.AllEntries() {
+		if e.Message == "slow redis command" {
+			require.Equal(t, parent.SpanContext().TraceID.String(), e.Data["traceId"])
+			slow++
+		}
+	}
+	require.Equal(t, 3, slow)
+}
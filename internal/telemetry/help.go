@@ -35,6 +35,8 @@ const (
 * <a href="/debug/pprof/profile">/debug/pprof/profile</a> - PProf
 * <a href="/debug/pprof/symbol">/debug/pprof/symbol</a> - PProf
 * <a href="/debug/pprof/trace">/debug/pprof/trace</a> - Execution Trace
+* <a href="/dependencyz">/dependencyz</a> - Dependency Health, add ?watch=1s to stream it
+* <a href="/decisionz">/decisionz</a> - Recent Pipeline Decisions, add ?limit=10 to show fewer
 * <a href="/metrics">/metrics</a> - Raw Metrics, use prometheus or grafana instead.
 
 <i>For /debug/pprof/ links see, https://golang.org/pkg/net/http/pprof/ for details.</i>
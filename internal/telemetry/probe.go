@@ -16,8 +16,10 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync/atomic"
 )
 
@@ -30,6 +32,18 @@ const (
 	healthStateUnhealthy  = int32(2)
 )
 
+// StartingError fails readiness while the service is still waiting for its
+// dependencies at startup.  It is expected during rollouts, so unlike other
+// probe failures it isn't logged as a warning.
+type StartingError struct {
+	// Names of the dependencies which haven't been reached yet.
+	Dependencies []string
+}
+
+func (e *StartingError) Error() string {
+	return "waiting for dependencies: " + strings.Join(e.Dependencies, ", ")
+}
+
 type statefulProbe struct {
 	healthState *int32
 	probes      []func(context.Context) error
@@ -43,6 +57,11 @@ func (sp *statefulProbe) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		// If so then scan all the probes.
 		for _, probe := range sp.probes {
 			err := probe(req.Context())
+			var startingErr *StartingError
+			if errors.As(err, &startingErr) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
 			if err != nil {
 				old := atomic.SwapInt32(sp.healthState, healthStateUnhealthy)
 				if old == healthStateUnhealthy {
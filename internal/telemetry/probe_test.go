@@ -55,6 +55,21 @@ func TestHealthCheck(t *testing.T) {
 	}
 }
 
+func TestStartingHealthCheck(t *testing.T) {
+	require := require.New(t)
+	hc := NewHealthCheck([]func(context.Context) error{func(context.Context) error {
+		return &StartingError{Dependencies: []string{"redis", "synchronizer"}}
+	}})
+	hcFunc := func(w http.ResponseWriter, r *http.Request) {
+		hc.ServeHTTP(w, r)
+	}
+
+	require.HTTPError(hcFunc, http.MethodGet, "/", url.Values{"readiness": []string{"true"}})
+	require.HTTPBodyContains(hcFunc, http.MethodGet, "/", url.Values{"readiness": []string{"true"}}, "waiting for dependencies: redis, synchronizer")
+	// Waiting for dependencies doesn't count as being unhealthy.
+	require.Equal(healthStateFirstProbe, atomic.LoadInt32(hc.(*statefulProbe).healthState))
+}
+
 func assertHealthCheck(t *testing.T, hc http.Handler, errorString string) {
 	require := require.New(t)
 	sp, ok := hc.(*statefulProbe)
@@ -28,7 +28,10 @@ import (
 	mmfService "open-match.dev/open-match/internal/testing/mmf"
 )
 
-func start(t *testing.T, eval evaluator.Evaluator, mmf mmfService.MatchFunction) (config.View, func(time.Duration)) {
+func start(t *testing.T, eval evaluator.RejectingEvaluator, mmf mmfService.MatchFunction, configure func(config.Mutable)) (config.View, func(time.Duration)) {
+	if configure != nil {
+		t.Fatal("The configuration of the cluster can't be changed by a test")
+	}
 	clusterLock.Lock()
 	t.Cleanup(func() {
 		clusterLock.Unlock()
@@ -64,6 +67,6 @@ func start(t *testing.T, eval evaluator.Evaluator, mmf mmfService.MatchFunction)
 }
 
 var clusterLock sync.Mutex
-var clusterEval evaluator.Evaluator
+var clusterEval evaluator.RejectingEvaluator
 var clusterMMF mmfService.MatchFunction
 var clusterStarted bool
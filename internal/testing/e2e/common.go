@@ -36,6 +36,12 @@ var (
 )
 
 func newOM(t *testing.T) *om {
+	return newOMWith(t, nil)
+}
+
+// newOMWith is newOM, with configure, if not nil, changing the configuration
+// before Open Match starts.  Only the in memory tests can change it.
+func newOMWith(t *testing.T, configure func(config.Mutable)) *om {
 	om := &om{
 		t: t,
 	}
@@ -53,7 +59,7 @@ func newOM(t *testing.T) *om {
 		}
 	})
 
-	om.cfg, om.AdvanceTTLTime = start(t, om.evaluate, om.runMMF)
+	om.cfg, om.AdvanceTTLTime = start(t, om.evaluate, om.runMMF, configure)
 	om.fe = pb.NewFrontendServiceClient(apptest.GRPCClient(t, om.cfg, "api.frontend"))
 	om.be = pb.NewBackendServiceClient(apptest.GRPCClient(t, om.cfg, "api.backend"))
 	om.query = pb.NewQueryServiceClient(apptest.GRPCClient(t, om.cfg, "api.query"))
@@ -77,7 +83,7 @@ type om struct {
 	mmfCalled  bool
 	evalCalled bool
 	mmf        mmfService.MatchFunction
-	eval       evaluator.Evaluator
+	eval       evaluator.RejectingEvaluator
 }
 
 func (om *om) SetMMF(mmf mmfService.MatchFunction) {
@@ -106,6 +112,12 @@ func (om *om) runMMF(ctx context.Context, profile *pb.MatchProfile, out chan<- *
 }
 
 func (om *om) SetEvaluator(eval evaluator.Evaluator) {
+	om.SetRejectingEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string, _ chan<- *pb.MatchRejection) error {
+		return eval(ctx, in, out)
+	})
+}
+
+func (om *om) SetRejectingEvaluator(eval evaluator.RejectingEvaluator) {
 	om.fLock.Lock()
 	defer om.fLock.Unlock()
 
@@ -116,7 +128,7 @@ func (om *om) SetEvaluator(eval evaluator.Evaluator) {
 	om.t.Fatal("Evaluator function set multiple times")
 }
 
-func (om *om) evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+func (om *om) evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- string, rejected chan<- *pb.MatchRejection) error {
 	om.fLock.Lock()
 	om.running.Add(1)
 	defer om.running.Done()
@@ -127,7 +139,7 @@ func (om *om) evaluate(ctx context.Context, in <-chan *pb.Match, out chan<- stri
 	if eval == nil {
 		return errors.New("Evaluator called without being set")
 	}
-	return eval(ctx, in, out)
+	return eval(ctx, in, out, rejected)
 }
 
 func (om *om) Frontend() pb.FrontendServiceClient {
@@ -174,6 +186,14 @@ pendingReleaseTimeout: 1s
 assignedDeleteTimeout: 200ms
 queryPageSize: 10
 backfillLockTimeout: 1m
+serverCapacityTimeout: 1m
+ticketTimelineRetention: 1m
+ticketCountsRetention: 10m
+profileMaxTicketsPerMatch: ["small-profile=1"]
+queryAuditClients: ["*"]
+ticketTimelineClients: ["*"]
+pendingTicketsClients: ["*"]
+profilePauseClients: ["*"]
 
 logging:
   level: debug
@@ -16,6 +16,7 @@ package e2e
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sync"
 	"testing"
@@ -26,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/errorinfo"
 	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
@@ -219,6 +221,47 @@ func TestSynchronizerMatchCollision(t *testing.T) {
 	require.Nil(t, resp)
 }
 
+// TestMMFEmptyResponse covers match functions streaming a response with no
+// proposal, which is dropped.
+func TestMMFEmptyResponse(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	m := &pb.Match{
+		MatchId: "1",
+	}
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- nil
+		out <- m
+		return nil
+	})
+
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for p := range in {
+			require.NotNil(t, p)
+			out <- p.MatchId
+		}
+		return nil
+	})
+
+	for _, config := range []*pb.FunctionConfig{om.MMFConfigGRPC(), om.MMFConfigHTTP()} {
+		stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+			Config:  config,
+			Profile: &pb.MatchProfile{},
+		})
+		require.Nil(t, err)
+
+		resp, err := stream.Recv()
+		require.Nil(t, err)
+		require.True(t, proto.Equal(m, resp.Match))
+
+		resp, err = stream.Recv()
+		require.Equal(t, io.EOF, err)
+		require.Nil(t, resp)
+	}
+}
+
 // TestEvaluatorReturnInvalidId covers the evaluator returning an ID which does
 // not correspond to any match passed to it.
 func TestEvaluatorReturnInvalidId(t *testing.T) {
@@ -293,6 +336,216 @@ func TestEvaluatorReturnDuplicateMatchId(t *testing.T) {
 	require.Contains(t, err.Error(), "evaluator returned same match_id twice: \"1\"")
 }
 
+// TestEvaluatorRejections covers the evaluator explaining a rejected match,
+// which is only returned to fetch match callers which ask for it.
+func TestEvaluatorRejections(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	t1, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	t2, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId: "1",
+			Tickets: []*pb.Ticket{t1, t2},
+		}
+		out <- &pb.Match{
+			MatchId: "2",
+			Tickets: []*pb.Ticket{t2},
+		}
+		return nil
+	})
+
+	rejection := &pb.MatchRejection{
+		MatchId:            "2",
+		CollidingTicketIds: []string{t2.Id},
+		WinningMatchId:     "1",
+	}
+
+	om.SetRejectingEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string, rejected chan<- *pb.MatchRejection) error {
+		for m := range in {
+			if m.MatchId == "1" {
+				out <- m.MatchId
+			} else {
+				rejected <- rejection
+			}
+		}
+		return nil
+	})
+
+	for _, includeRejections := range []bool{true, false} {
+		stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+			Config:            om.MMFConfigGRPC(),
+			Profile:           &pb.MatchProfile{},
+			IncludeRejections: includeRejections,
+		})
+		require.Nil(t, err)
+
+		var matches []*pb.Match
+		var rejections []*pb.MatchRejection
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.Nil(t, err)
+			if resp.Rejection != nil {
+				require.Nil(t, resp.Match)
+				rejections = append(rejections, resp.Rejection)
+			} else {
+				matches = append(matches, resp.Match)
+			}
+		}
+
+		require.Len(t, matches, 1)
+		require.Equal(t, "1", matches[0].MatchId)
+
+		if includeRejections {
+			require.Len(t, rejections, 1)
+			require.True(t, proto.Equal(rejection, rejections[0]), rejections[0])
+		} else {
+			require.Empty(t, rejections)
+		}
+	}
+}
+
+// TestOversizedMatchRejected covers proposals with more tickets than the
+// maximum per match of their profile being rejected before evaluation.
+func TestOversizedMatchRejected(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	var tickets []*pb.Ticket
+	for i := 0; i < 3; i++ {
+		ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+		require.Nil(t, err)
+		tickets = append(tickets, ticket)
+	}
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId:      "1",
+			MatchProfile: profile.GetName(),
+			Tickets:      tickets[:1],
+		}
+		// The limit is that of the profile of the call, whatever profile
+		// the match function sets.
+		out <- &pb.Match{
+			MatchId:      "2",
+			MatchProfile: "unlimited-profile",
+			Tickets:      tickets[1:],
+		}
+		return nil
+	})
+
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for m := range in {
+			require.Equal(t, "1", m.MatchId)
+			out <- m.MatchId
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:            om.MMFConfigGRPC(),
+		Profile:           &pb.MatchProfile{Name: "small-profile"},
+		IncludeRejections: true,
+	})
+	require.Nil(t, err)
+
+	var matches []*pb.Match
+	var rejections []*pb.MatchRejection
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		if resp.Rejection != nil {
+			rejections = append(rejections, resp.Rejection)
+		} else {
+			matches = append(matches, resp.Match)
+		}
+	}
+
+	require.Len(t, matches, 1)
+	require.Equal(t, "1", matches[0].MatchId)
+	require.Len(t, rejections, 1)
+	require.True(t, proto.Equal(&pb.MatchRejection{MatchId: "2", MaxTickets: 1}, rejections[0]), rejections[0])
+}
+
+// TestOversizedMatchRejectedEvaluationExempt covers the maximum tickets per
+// match also applying to evaluation exempt profiles.
+func TestOversizedMatchRejectedEvaluationExempt(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	var tickets []*pb.Ticket
+	for i := 0; i < 3; i++ {
+		ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+		require.Nil(t, err)
+		tickets = append(tickets, ticket)
+	}
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId: "1",
+			Tickets: tickets[:1],
+		}
+		out <- &pb.Match{
+			MatchId:      "2",
+			MatchProfile: "unlimited-profile",
+			Tickets:      tickets[1:],
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:            om.MMFConfigGRPC(),
+		Profile:           &pb.MatchProfile{Name: "small-profile", EvaluationExempt: true},
+		IncludeRejections: true,
+	})
+	require.Nil(t, err)
+
+	var matches []*pb.Match
+	var rejections []*pb.MatchRejection
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		if resp.Rejection != nil {
+			rejections = append(rejections, resp.Rejection)
+		} else {
+			matches = append(matches, resp.Match)
+		}
+	}
+
+	require.Len(t, matches, 1)
+	require.Equal(t, "1", matches[0].MatchId)
+	require.Len(t, rejections, 1)
+	require.True(t, proto.Equal(&pb.MatchRejection{MatchId: "2", MaxTickets: 1}, rejections[0]), rejections[0])
+
+	// The tickets of the rejected match weren't claimed.
+	query, err := om.Query().QueryTicketIds(ctx, &pb.QueryTicketIdsRequest{Pool: &pb.Pool{}})
+	require.Nil(t, err)
+	var active []string
+	for {
+		resp, err := query.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		active = append(active, resp.GetIds()...)
+	}
+	require.ElementsMatch(t, []string{tickets[1].GetId(), tickets[2].GetId()}, active)
+}
+
 // TestMatchWithNoTickets covers that it is valid to create a match with no
 // tickets specified.  This is a questionable use case, but it works currently
 // so it probably shouldn't be changed without significant justification.
@@ -337,6 +590,145 @@ func TestMatchWithNoTickets(t *testing.T) {
 	require.Nil(t, resp)
 }
 
+// TestProfileLimits covers the backend dropping proposals which exceed the
+// profile's max_matches or max_tickets before they reach the evaluator.
+func TestProfileLimits(t *testing.T) {
+	tickets := func(ids ...string) []*pb.Ticket {
+		var ts []*pb.Ticket
+		for _, id := range ids {
+			ts = append(ts, &pb.Ticket{Id: id})
+		}
+		return ts
+	}
+
+	proposals := []*pb.Match{
+		{MatchId: "1", Tickets: tickets("a", "b")},
+		{MatchId: "2", Tickets: tickets("c", "d")},
+		{MatchId: "3", Tickets: tickets("e")},
+	}
+
+	tests := []struct {
+		description string
+		profile     *pb.MatchProfile
+		wantMatches []string
+	}{
+		{
+			description: "no limits",
+			profile:     &pb.MatchProfile{},
+			wantMatches: []string{"1", "2", "3"},
+		},
+		{
+			description: "max matches",
+			profile:     &pb.MatchProfile{MaxMatches: 2},
+			wantMatches: []string{"1", "2"},
+		},
+		{
+			description: "max tickets skips proposals which don't fit",
+			profile:     &pb.MatchProfile{MaxTickets: 3},
+			wantMatches: []string{"1", "3"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			om := newOM(t)
+
+			om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+				for _, p := range proposals {
+					out <- p
+				}
+				return nil
+			})
+
+			om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+				for p := range in {
+					out <- p.MatchId
+				}
+				return nil
+			})
+
+			stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+				Config:  om.MMFConfigGRPC(),
+				Profile: test.profile,
+			})
+			require.Nil(t, err)
+
+			var got []string
+			for {
+				resp, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				require.Nil(t, err)
+				got = append(got, resp.Match.MatchId)
+			}
+			require.ElementsMatch(t, test.wantMatches, got)
+		})
+	}
+}
+
+// TestEvaluationExemptProfile covers an evaluation exempt profile's matches
+// being returned without calling the evaluator, and their tickets still being
+// moved to pending.
+func TestEvaluationExemptProfile(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	t1, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+	t2, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	m := &pb.Match{
+		MatchId: "1",
+		Tickets: []*pb.Ticket{t1, t2},
+	}
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- m
+		return nil
+	})
+
+	// No evaluator is set, so the fetch would fail if it were called.
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{EvaluationExempt: true},
+	})
+	require.Nil(t, err)
+
+	resp, err := stream.Recv()
+	require.Nil(t, err)
+	require.True(t, proto.Equal(m, resp.Match))
+
+	resp, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+	require.Nil(t, resp)
+
+	query, err := om.Query().QueryTickets(ctx, &pb.QueryTicketsRequest{Pool: &pb.Pool{}})
+	require.Nil(t, err)
+	_, err = query.Recv()
+	require.Equal(t, io.EOF, err)
+}
+
+// TestNegativeProfileLimits covers rejecting negative profile limits.
+func TestNegativeProfileLimits(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{MaxMatches: -1},
+	})
+	require.Nil(t, err)
+
+	resp, err := stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+	require.Nil(t, resp)
+}
+
 // TestEvaluatorError covers an evaluator returning an error message, and
 // ensuring that the error message is returned to the fetch matches call.
 func TestEvaluatorError(t *testing.T) {
@@ -389,6 +781,11 @@ func TestMMFError(t *testing.T) {
 
 	require.Contains(t, err.Error(), "my custom error")
 	require.Nil(t, resp)
+
+	info, ok := errorinfo.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, errorinfo.ReasonMatchFunctionFailed, info.Reason)
+	require.Equal(t, fmt.Sprintf("%s:%d", om.MMFConfigGRPC().Host, om.MMFConfigGRPC().Port), info.Metadata[errorinfo.MetadataMatchFunctionHost])
 }
 
 // TestNoMatches covers that returning no matches is acceptable.
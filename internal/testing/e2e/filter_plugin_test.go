@@ -0,0 +1,69 @@
+// +build !e2ecluster,!race,cgo
+
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// TestQueryFilterPlugin builds the requiredtag plugin in testdata, and checks
+// the query service loads it and filters pools with it.  Plugins must be built
+// the same way as the binary loading them, so this test doesn't run with the
+// race detector, or without cgo.
+func TestQueryFilterPlugin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requiredtag.so")
+	build := exec.Command("go", "build", "-buildmode=plugin", "-o", path, ".")
+	build.Dir = filepath.Join("testdata", "requiredtag")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, "failed to build plugin: %s", out)
+
+	om := newOMWith(t, func(cfg config.Mutable) {
+		cfg.Set(config.KeyQueryFilterPlugins, []string{path})
+	})
+	ctx := context.Background()
+
+	var vipID string
+	for _, tag := range []string{"vip", "casual"} {
+		resp, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{
+			SearchFields: &pb.SearchFields{Tags: []string{tag}},
+		}})
+		require.NoError(t, err)
+		if tag == "vip" {
+			vipID = resp.GetId()
+		}
+	}
+
+	vip, err := ptypes.MarshalAny(&wrappers.StringValue{Value: "vip"})
+	require.NoError(t, err)
+	stream, err := om.Query().QueryTicketIds(ctx, &pb.QueryTicketIdsRequest{Pool: &pb.Pool{
+		Extensions: map[string]*any.Any{"requiredTag": vip},
+	}})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []string{vipID}, resp.GetIds())
+}
@@ -34,7 +34,7 @@ import (
 	mmfService "open-match.dev/open-match/internal/testing/mmf"
 )
 
-func start(t *testing.T, eval evaluator.Evaluator, mmf mmfService.MatchFunction) (config.View, func(time.Duration)) {
+func start(t *testing.T, eval evaluator.RejectingEvaluator, mmf mmfService.MatchFunction, configure func(config.Mutable)) (config.View, func(time.Duration)) {
 	mredis := miniredis.NewMiniRedis()
 	err := mredis.StartAddr("localhost:0")
 	if err != nil {
@@ -73,6 +73,12 @@ func start(t *testing.T, eval evaluator.Evaluator, mmf mmfService.MatchFunction)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if configure != nil {
+		configure(cfg)
+	}
+	if err = config.Validate(cfg); err != nil {
+		t.Fatal(err)
+	}
 
 	cfg.Set("redis.sentinelHostname", msentinal.Host())
 	cfg.Set("redis.sentinelPort", msentinal.Port())
@@ -87,6 +93,6 @@ func start(t *testing.T, eval evaluator.Evaluator, mmf mmfService.MatchFunction)
 	cfg.Set("logging.level", *testOnlyLoggingLevel)
 	cfg.Set(telemetry.ConfigNameEnableMetrics, *testOnlyEnableMetrics)
 
-	apptest.TestApp(t, cfg, listeners, minimatch.BindService, mmfService.BindServiceFor(mmf), evaluator.BindServiceFor(eval))
+	apptest.TestApp(t, cfg, listeners, minimatch.BindService, mmfService.BindServiceFor(mmf), evaluator.BindRejectingServiceFor(eval))
 	return cfg, mredis.FastForward
 }
@@ -0,0 +1,76 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/errorinfo"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestPauseProfiles(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	_, err := om.Backend().PauseProfiles(ctx, &pb.PauseProfilesRequest{Profiles: []string{"ranked", "casual"}})
+	require.NoError(t, err)
+
+	list, err := om.Backend().ListPausedProfiles(ctx, &pb.ListPausedProfilesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"casual", "ranked"}, list.Profiles)
+	require.Empty(t, list.ConfiguredProfiles)
+
+	// Tickets are still accepted while matchmaking is paused.
+	_, err = om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.NoError(t, err)
+
+	// No match function is set, so calling it would fail FetchMatches.
+	var trailer metadata.MD
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{Name: "ranked"},
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.Nil(t, resp)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, []string{errorinfo.ReasonPaused}, trailer.Get(errorinfo.TrailerReason))
+
+	_, err = om.Backend().ResumeProfiles(ctx, &pb.ResumeProfilesRequest{Profiles: []string{"ranked"}})
+	require.NoError(t, err)
+	list, err = om.Backend().ListPausedProfiles(ctx, &pb.ListPausedProfilesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"casual"}, list.Profiles)
+}
+
+func TestPauseProfilesValidation(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	_, err := om.Backend().PauseProfiles(ctx, &pb.PauseProfilesRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = om.Backend().PauseProfiles(ctx, &pb.PauseProfilesRequest{Profiles: []string{"ranked", ""}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = om.Backend().ResumeProfiles(ctx, &pb.ResumeProfilesRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
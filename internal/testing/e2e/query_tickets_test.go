@@ -17,8 +17,11 @@ package e2e
 import (
 	"context"
 	"io"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -118,6 +121,195 @@ func TestPaging(t *testing.T) {
 	require.Nil(t, resp)
 }
 
+func TestQueryInactiveTickets(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	tickets := []*pb.Ticket{}
+	for i := 0; i < 3; i++ {
+		ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+		require.Nil(t, err)
+		tickets = append(tickets, ticket)
+	}
+	active, pending, assigned := tickets[0], tickets[1], tickets[2]
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId:      "1",
+			MatchProfile: profile.GetName(),
+			Tickets:      []*pb.Ticket{pending},
+		}
+		return nil
+	})
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for m := range in {
+			out <- m.MatchId
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{Name: "test-profile"},
+	})
+	require.Nil(t, err)
+	for {
+		_, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+	}
+
+	_, err = om.Backend().AssignTickets(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{
+				TicketIds:  []string{assigned.Id},
+				Assignment: &pb.Assignment{Connection: "a"},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	queryStream, err := om.Query().QueryTickets(ctx, &pb.QueryTicketsRequest{Pool: &pb.Pool{}, IncludeInactive: true})
+	require.Nil(t, err)
+
+	states := map[string]pb.QueryTicketsResponse_State{}
+	for {
+		resp, err := queryStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		require.Equal(t, len(resp.Tickets), len(resp.States))
+		for i, ticket := range resp.Tickets {
+			states[ticket.Id] = resp.States[i]
+		}
+	}
+
+	require.Equal(t, map[string]pb.QueryTicketsResponse_State{
+		active.Id:   pb.QueryTicketsResponse_ACTIVE,
+		pending.Id:  pb.QueryTicketsResponse_PENDING,
+		assigned.Id: pb.QueryTicketsResponse_ASSIGNED,
+	}, states)
+}
+
+func TestExportTickets(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	want := []string{}
+	for i := 0; i < 25; i++ {
+		ticket := &pb.Ticket{}
+		if i%5 != 0 {
+			ticket.SearchFields = &pb.SearchFields{Tags: []string{"export"}}
+		}
+		resp, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: ticket})
+		require.Nil(t, err)
+		if i%5 != 0 {
+			want = append(want, resp.Id)
+		}
+	}
+	sort.Strings(want)
+
+	stream, err := om.Query().ExportTickets(ctx, &pb.ExportTicketsRequest{
+		Pool: &pb.Pool{TagPresentFilters: []*pb.TagPresentFilter{{Tag: "export"}}},
+	})
+	require.Nil(t, err)
+
+	got := []string{}
+	batches := 0
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		require.LessOrEqual(t, len(resp.Tickets), 10)
+		require.Equal(t, len(resp.Tickets), len(resp.States))
+		for i, ticket := range resp.Tickets {
+			require.Equal(t, pb.QueryTicketsResponse_ACTIVE, resp.States[i])
+			got = append(got, ticket.Id)
+		}
+		batches++
+	}
+
+	require.Equal(t, want, got)
+	require.Greater(t, batches, 1)
+}
+
+func TestMaxAgeReportExpired(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	stale, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+	staleCreated := time.Now()
+	time.Sleep(2 * time.Second)
+	fresh, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	// The stale ticket is older than the max age however slow the query is,
+	// and the fresh ticket is within it unless the query takes 2s.
+	maxAge := time.Since(staleCreated)
+	stream, err := om.Query().QueryTickets(ctx, &pb.QueryTicketsRequest{Pool: &pb.Pool{
+		MaxAge:        ptypes.DurationProto(maxAge),
+		ReportExpired: true,
+	}})
+	require.Nil(t, err)
+
+	var tickets []string
+	var expired []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+
+		for _, ticket := range resp.Tickets {
+			tickets = append(tickets, ticket.Id)
+		}
+		expired = append(expired, resp.ExpiredIds...)
+	}
+
+	require.Equal(t, []string{fresh.Id}, tickets)
+	require.Equal(t, []string{stale.Id}, expired)
+}
+
+func TestPartitions(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	a, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{Partition: "studio-a"}})
+	require.Nil(t, err)
+	require.Equal(t, "studio-a", a.Partition)
+	b, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	query := func(partition string) []string {
+		stream, err := om.Query().QueryTickets(ctx, &pb.QueryTicketsRequest{Pool: &pb.Pool{Partition: partition}})
+		require.Nil(t, err)
+
+		var ids []string
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.Nil(t, err)
+			for _, ticket := range resp.Tickets {
+				ids = append(ids, ticket.Id)
+			}
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{a.Id}, query("studio-a"))
+	require.Equal(t, []string{b.Id}, query(""))
+	require.Empty(t, query("studio-b"))
+}
+
 func TestTicketFound(t *testing.T) {
 	for _, tc := range testcases.IncludedTestCases() {
 		tc := tc
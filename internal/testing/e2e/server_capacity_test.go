@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/matchfunction"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestServerCapacity(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	// An unused registry reports no capacity, so match production isn't throttled.
+	_, ok, err := matchfunction.QueryAvailableCapacity(ctx, om.Query(), "")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	for _, c := range []*pb.ServerCapacity{
+		{Region: "us-west1", Fleet: "a", Available: 2},
+		{Region: "us-west1", Fleet: "b", Available: 3},
+		{Region: "us-east1", Fleet: "a", Available: 4},
+		{Region: "us-west1", Fleet: "a", Available: 1},
+	} {
+		_, err = om.Backend().UpdateServerCapacity(ctx, &pb.UpdateServerCapacityRequest{Capacity: c})
+		require.NoError(t, err)
+	}
+
+	available, ok, err := matchfunction.QueryAvailableCapacity(ctx, om.Query(), "us-west1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 4, available)
+
+	resp, err := om.Backend().GetServerCapacity(ctx, &pb.GetServerCapacityRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Capacities, 3)
+	for _, c := range resp.Capacities {
+		require.NotNil(t, c.UpdateTime)
+	}
+}
+
+func TestUpdateServerCapacityValidation(t *testing.T) {
+	ctx := context.Background()
+	om := newOM(t)
+
+	for _, req := range []*pb.UpdateServerCapacityRequest{
+		{},
+		{Capacity: &pb.ServerCapacity{Region: "us-west1"}},
+		{Capacity: &pb.ServerCapacity{Fleet: "a", Available: -1}},
+	} {
+		_, err := om.Backend().UpdateServerCapacity(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+	}
+}
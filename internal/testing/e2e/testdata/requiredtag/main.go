@@ -0,0 +1,50 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command requiredtag is a query filter plugin for the e2e tests.  Its
+// "requiredTag" filter passes the tickets which have the tag the pool gives as
+// a StringValue.
+package main
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"open-match.dev/open-match/pkg/customfilter"
+)
+
+type requiredTag string
+
+func (f requiredTag) In(entity customfilter.Entity) bool {
+	for _, tag := range entity.GetSearchFields().GetTags() {
+		if tag == string(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	customfilter.Register("requiredTag", func(config *any.Any) (customfilter.Filter, error) {
+		tag := &wrappers.StringValue{}
+		if err := ptypes.UnmarshalAny(config, tag); err != nil {
+			return nil, fmt.Errorf("requiredTag needs a StringValue: %w", err)
+		}
+		return requiredTag(tag.GetValue()), nil
+	})
+}
+
+func main() {}
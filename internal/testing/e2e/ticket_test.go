@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/pkg/errorinfo"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -211,6 +212,18 @@ func TestTicketDelete(t *testing.T) {
 	require.Nil(t, resp)
 	require.Equal(t, "Ticket id: "+t1.Id+" not found", status.Convert(err).Message())
 	require.Equal(t, codes.NotFound, status.Convert(err).Code())
+
+	info, ok := errorinfo.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, errorinfo.ReasonTicketNotFound, info.Reason)
+	require.Equal(t, t1.Id, info.Metadata[errorinfo.MetadataTicketID])
+
+	// Errors without a specific reason are still annotated.
+	_, err = om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{})
+	info, ok = errorinfo.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, errorinfo.Domain, info.Domain)
+	require.Equal(t, "INVALID_ARGUMENT", info.Reason)
 }
 
 // TestEmptyReleaseTicketsRequest covers that it is valid to not have any ticket
@@ -667,3 +680,304 @@ func TestWatchAssignments(t *testing.T) {
 		require.Equal(t, "a", a.Connection)
 	}
 }
+
+// TestTicketTimeline covers the events recorded over a ticket's lifecycle.
+func TestTicketTimeline(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId:      "1",
+			MatchProfile: profile.GetName(),
+			Tickets:      []*pb.Ticket{ticket},
+		}
+		return nil
+	})
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for m := range in {
+			out <- m.MatchId
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{Name: "test-profile"},
+	})
+	require.Nil(t, err)
+
+	resp, err := stream.Recv()
+	require.Nil(t, err)
+	require.Equal(t, "1", resp.GetMatch().GetMatchId())
+
+	resp, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+	require.Nil(t, resp)
+
+	_, err = om.Backend().AssignTickets(ctx, &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{
+				TicketIds:  []string{ticket.Id},
+				Assignment: &pb.Assignment{Connection: "a"},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = om.Frontend().DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: ticket.Id})
+	require.Nil(t, err)
+
+	// The deletion is recorded once the ticket is removed from state storage.
+	var timeline *pb.GetTicketTimelineResponse
+	require.Eventually(t, func() bool {
+		timeline, err = om.Backend().GetTicketTimeline(ctx, &pb.GetTicketTimelineRequest{TicketId: ticket.Id})
+		require.Nil(t, err)
+		return len(timeline.Events) == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	types := []pb.TicketEvent_Type{}
+	for _, e := range timeline.Events {
+		types = append(types, e.Type)
+		require.NotNil(t, e.Time)
+		if e.Type == pb.TicketEvent_PROPOSED || e.Type == pb.TicketEvent_MATCHED {
+			require.Equal(t, "1", e.MatchId)
+			require.Equal(t, "test-profile", e.MatchProfile)
+		}
+	}
+	require.Equal(t, []pb.TicketEvent_Type{
+		pb.TicketEvent_CREATED,
+		pb.TicketEvent_PROPOSED,
+		pb.TicketEvent_MATCHED,
+		pb.TicketEvent_ASSIGNED,
+		pb.TicketEvent_DELETED,
+	}, types)
+
+	_, err = om.Backend().GetTicketTimeline(ctx, &pb.GetTicketTimelineRequest{})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+}
+
+func TestGetTicketCounts(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+		require.Nil(t, err)
+
+		if i == 0 {
+			_, err = om.Backend().AssignTickets(ctx, &pb.AssignTicketsRequest{
+				Assignments: []*pb.AssignmentGroup{
+					{
+						TicketIds:  []string{ticket.Id},
+						Assignment: &pb.Assignment{Connection: "a"},
+					},
+				},
+			})
+			require.Nil(t, err)
+		} else {
+			_, err = om.Frontend().DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: ticket.Id})
+			require.Nil(t, err)
+		}
+	}
+
+	// The minutes of ticketCountsRetention, and the current one.
+	resp, err := om.Backend().GetTicketCounts(ctx, &pb.GetTicketCountsRequest{})
+	require.Nil(t, err)
+	require.Len(t, resp.Counts, 11)
+
+	// The deletion is counted once the ticket is removed from state storage.
+	total := &pb.TicketCounts{}
+	require.Eventually(t, func() bool {
+		resp, err = om.Backend().GetTicketCounts(ctx, &pb.GetTicketCountsRequest{Minutes: 2})
+		require.Nil(t, err)
+		require.Len(t, resp.Counts, 2)
+		total = &pb.TicketCounts{}
+		for _, c := range resp.Counts {
+			require.NotNil(t, c.Minute)
+			total.Created += c.Created
+			total.Assigned += c.Assigned
+			total.Deleted += c.Deleted
+			total.Expired += c.Expired
+		}
+		return total.Deleted == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int64(2), total.Created)
+	require.Equal(t, int64(1), total.Assigned)
+	require.Equal(t, int64(1), total.Deleted)
+	require.Equal(t, int64(0), total.Expired)
+
+	_, err = om.Backend().GetTicketCounts(ctx, &pb.GetTicketCountsRequest{Minutes: -1})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+}
+
+func TestListPendingTickets(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId:      "1",
+			MatchProfile: profile.GetName(),
+			Tickets:      []*pb.Ticket{ticket},
+		}
+		return nil
+	})
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for m := range in {
+			out <- m.MatchId
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{Name: "test-profile"},
+	})
+	require.Nil(t, err)
+	for {
+		_, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+	}
+
+	resp, err := om.Backend().ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.Nil(t, err)
+	require.Len(t, resp.Tickets, 1)
+	require.Equal(t, ticket.Id, resp.Tickets[0].TicketId)
+	require.Equal(t, "1", resp.Tickets[0].MatchId)
+	require.Equal(t, "test-profile", resp.Tickets[0].MatchProfile)
+	require.NotNil(t, resp.Tickets[0].ReleaseTime)
+
+	resp, err = om.Backend().ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{MatchProfile: "other-profile"})
+	require.Nil(t, err)
+	require.Empty(t, resp.Tickets)
+
+	_, err = om.Backend().ReleaseTickets(ctx, &pb.ReleaseTicketsRequest{TicketIds: []string{ticket.Id}})
+	require.Nil(t, err)
+
+	resp, err = om.Backend().ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.Nil(t, err)
+	require.Empty(t, resp.Tickets)
+}
+
+func TestReserveTickets(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	ticket, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	resp, err := om.Backend().ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds:     []string{ticket.Id, "missing"},
+		Ttl:           ptypes.DurationProto(5 * time.Minute),
+		ReservationId: "bracket-1",
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{ticket.Id}, resp.TicketIds)
+	require.Len(t, resp.Failures, 1)
+	require.Equal(t, pb.ReservationFailure_TICKET_NOT_FOUND, resp.Failures[0].Cause)
+
+	pending, err := om.Backend().ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.Nil(t, err)
+	require.Len(t, pending.Tickets, 1)
+	require.Equal(t, ticket.Id, pending.Tickets[0].TicketId)
+	require.Equal(t, "bracket-1", pending.Tickets[0].ReservationId)
+
+	timeline, err := om.Backend().GetTicketTimeline(ctx, &pb.GetTicketTimelineRequest{TicketId: ticket.Id})
+	require.Nil(t, err)
+	require.Equal(t, pb.TicketEvent_RESERVED, timeline.Events[len(timeline.Events)-1].Type)
+
+	_, err = om.Backend().ReleaseTickets(ctx, &pb.ReleaseTicketsRequest{TicketIds: []string{ticket.Id}})
+	require.Nil(t, err)
+	pending, err = om.Backend().ListPendingTickets(ctx, &pb.ListPendingTicketsRequest{})
+	require.Nil(t, err)
+	require.Empty(t, pending.Tickets)
+
+	_, err = om.Backend().ReserveTickets(ctx, &pb.ReserveTicketsRequest{TicketIds: []string{ticket.Id}})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+	_, err = om.Backend().ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds: []string{ticket.Id},
+		Ttl:       ptypes.DurationProto(-time.Second),
+	})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+	// Longer than maxReservationTtl.
+	_, err = om.Backend().ReserveTickets(ctx, &pb.ReserveTicketsRequest{
+		TicketIds: []string{ticket.Id},
+		Ttl:       ptypes.DurationProto(time.Hour),
+	})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+}
+
+func TestAssignMatch(t *testing.T) {
+	om := newOM(t)
+	ctx := context.Background()
+
+	t1, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+	t2, err := om.Frontend().CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: &pb.Ticket{}})
+	require.Nil(t, err)
+
+	om.SetMMF(func(ctx context.Context, profile *pb.MatchProfile, out chan<- *pb.Match) error {
+		out <- &pb.Match{
+			MatchId:      "1",
+			MatchProfile: profile.GetName(),
+			Tickets:      []*pb.Ticket{t1, t2},
+		}
+		return nil
+	})
+	om.SetEvaluator(func(ctx context.Context, in <-chan *pb.Match, out chan<- string) error {
+		for m := range in {
+			out <- m.MatchId
+		}
+		return nil
+	})
+
+	stream, err := om.Backend().FetchMatches(ctx, &pb.FetchMatchesRequest{
+		Config:  om.MMFConfigGRPC(),
+		Profile: &pb.MatchProfile{Name: "test-profile"},
+	})
+	require.Nil(t, err)
+	for {
+		_, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+	}
+
+	// Deleted tickets fail assignment.
+	_, err = om.Frontend().DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: t2.Id})
+	require.Nil(t, err)
+
+	resp, err := om.Backend().AssignMatch(ctx, &pb.AssignMatchRequest{
+		MatchId:    "1",
+		Assignment: &pb.Assignment{Connection: "a"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{t1.Id}, resp.TicketIds)
+	require.Len(t, resp.Failures, 1)
+	require.Equal(t, t2.Id, resp.Failures[0].TicketId)
+	require.Equal(t, pb.AssignmentFailure_TICKET_NOT_FOUND, resp.Failures[0].Cause)
+
+	get, err := om.Frontend().GetTicket(ctx, &pb.GetTicketRequest{TicketId: t1.Id})
+	require.Nil(t, err)
+	require.Equal(t, "a", get.Assignment.Connection)
+
+	_, err = om.Backend().AssignMatch(ctx, &pb.AssignMatchRequest{
+		MatchId:    "unknown",
+		Assignment: &pb.Assignment{Connection: "a"},
+	})
+	require.Equal(t, codes.NotFound.String(), status.Convert(err).Code().String())
+
+	_, err = om.Backend().AssignMatch(ctx, &pb.AssignMatchRequest{MatchId: "1"})
+	require.Equal(t, codes.InvalidArgument.String(), status.Convert(err).Code().String())
+}
@@ -80,3 +80,8 @@ func (s *FakeFrontend) GetBackfill(ctx context.Context, req *pb.GetBackfillReque
 func (s *FakeFrontend) UpdateBackfill(ctx context.Context, req *pb.UpdateBackfillRequest) (*pb.Backfill, error) {
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
+
+// HeartbeatTicket records that the client of the ticket is still present.
+func (s *FakeFrontend) HeartbeatTicket(ctx context.Context, req *pb.HeartbeatTicketRequest) (*empty.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package worker runs the background jobs of Open Match services: periodic
+// jobs, and one off jobs queued by request handlers.  Jobs share retries with
+// backoff and jitter, metrics, and a single shutdown on Close.
+package worker
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"open-match.dev/open-match/internal/config"
+	"open-match.dev/open-match/internal/telemetry"
+)
+
+const (
+	// Enqueue blocks while this many jobs are waiting for a worker.
+	queueSize = 1024
+	// Periodic jobs wait their interval plus or minus this fraction of it, so
+	// replicas started together don't run in lockstep.
+	intervalJitter = 0.1
+)
+
+var (
+	logger = logrus.WithFields(logrus.Fields{
+		"app":       "openmatch",
+		"component": "worker",
+	})
+
+	jobKey    = tag.MustNewKey("job")
+	resultKey = tag.MustNewKey("result")
+
+	jobRuns    = telemetry.Counter("open-match.dev/worker/job_runs", "background job runs", jobKey, resultKey)
+	jobLatency = telemetry.HistogramWithBounds("open-match.dev/worker/job_latency", "Latency of background job runs", stats.UnitMilliseconds,
+		[]float64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 60000}, jobKey)
+)
+
+// Pool runs background jobs until it is closed.
+type Pool struct {
+	cfg    config.View
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan job
+	wg     sync.WaitGroup
+}
+
+type job struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// NewPool starts a pool.  Retries use the backoff settings of cfg.
+func NewPool(cfg config.View) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		queue:  make(chan job, queueSize),
+	}
+
+	concurrency := config.GetRuntime(cfg).WorkerConcurrency
+	p.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer p.wg.Done()
+			for {
+				select {
+				case <-p.ctx.Done():
+					return
+				case j := <-p.queue:
+					p.retry(j)
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// Enqueue runs fn in the background.  Failures are retried with exponential
+// backoff until fn succeeds, returns a backoff.Permanent error, or the backoff
+// settings' maxElapsedTime passes.  Jobs still queued when the pool is closed
+// are dropped.
+func (p *Pool) Enqueue(name string, fn func(context.Context) error) {
+	if p.ctx.Err() != nil {
+		logger.WithFields(logrus.Fields{
+			"job": name,
+		}).Warning("Worker pool is closed, dropping job.")
+		return
+	}
+
+	select {
+	case p.queue <- job{name: name, fn: fn}:
+	case <-p.ctx.Done():
+	}
+}
+
+// Every runs fn now, and then every interval until the pool is closed.
+// interval is called before each wait, so configuration changes apply without
+// a restart.  Failed runs are logged, and not retried until the next interval.
+func (p *Pool) Every(name string, interval func() time.Duration, fn func(context.Context) error) {
+	j := job{name: name, fn: fn}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for p.ctx.Err() == nil {
+			err := p.run(j)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"job":   name,
+					"error": err.Error(),
+				}).Warning("Periodic background job failed.")
+			}
+
+			select {
+			case <-p.ctx.Done():
+			case <-time.After(jitter(interval())):
+			}
+		}
+	}()
+}
+
+// Close stops all jobs, and waits for those running to return.
+func (p *Pool) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *Pool) retry(j job) {
+	err := backoff.Retry(func() error {
+		return p.run(j)
+	}, backoff.WithContext(config.GetBackoff(p.cfg).NewExponentialBackOff(), p.ctx))
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"job":   j.name,
+			"error": err.Error(),
+		}).Error("Background job failed.")
+	}
+}
+
+func (p *Pool) run(j job) error {
+	start := time.Now()
+	err := j.fn(p.ctx)
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	telemetry.RecordUnitMeasurement(p.ctx, jobRuns, tag.Upsert(jobKey, j.name), tag.Upsert(resultKey, result))
+	telemetry.RecordNUnitMeasurement(p.ctx, jobLatency, time.Since(start).Milliseconds(), tag.Upsert(jobKey, j.name))
+	return err
+}
+
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration((rand.Float64()*2-1)*intervalJitter*float64(d))
+}
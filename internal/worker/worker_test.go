@@ -0,0 +1,111 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(t *testing.T) *Pool {
+	cfg := viper.New()
+	cfg.Set("backoff.initialInterval", time.Millisecond)
+	cfg.Set("backoff.maxInterval", 10*time.Millisecond)
+	cfg.Set("backoff.maxElapsedTime", time.Second)
+	p := NewPool(cfg)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestEnqueueRetries(t *testing.T) {
+	p := newTestPool(t)
+
+	var calls int32
+	done := make(chan struct{})
+	p.Enqueue("test", func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("not yet")
+		}
+		close(done)
+		return nil
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not succeed")
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestEnqueuePermanentError(t *testing.T) {
+	p := newTestPool(t)
+
+	var calls int32
+	p.Enqueue("test", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return backoff.Permanent(errors.New("give up"))
+	})
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestEvery(t *testing.T) {
+	p := newTestPool(t)
+
+	var calls int32
+	p.Every("test", func() time.Duration { return time.Millisecond }, func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("retried at the next interval")
+	})
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) >= 3
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestClose(t *testing.T) {
+	p := NewPool(viper.New())
+
+	started := make(chan struct{})
+	var stopped int32
+	p.Every("test", func() time.Duration { return time.Hour }, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		atomic.StoreInt32(&stopped, 1)
+		return nil
+	})
+	<-started
+
+	p.Close()
+	require.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+
+	// Jobs queued after Close are dropped without blocking.
+	p.Enqueue("test", func(ctx context.Context) error {
+		t.Error("job ran after Close")
+		return nil
+	})
+}
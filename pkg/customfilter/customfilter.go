@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package customfilter adds filter types to the query service, for criteria
+// the built-in Pool filters can't express, such as geo polygons or bitmask
+// compatibility, without forking it.  Filter types are built as Go plugins
+// listed in the queryFilterPlugins config, whose init functions call
+// Register.  Pools use a filter by setting its name as a key of their
+// extensions, with the filter's configuration as the value.
+package customfilter
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Entity is a Ticket or Backfill being filtered.
+type Entity interface {
+	GetId() string
+	GetSearchFields() *pb.SearchFields
+	GetExtensions() map[string]*any.Any
+}
+
+// Filter decides which entities belong in a pool.  It must be safe for
+// concurrent use.
+type Filter interface {
+	// In returns true if the entity passes the filter.
+	In(entity Entity) bool
+}
+
+// Factory creates a Filter from the configuration a pool gives it.  Invalid
+// configurations should return an error, which makes the pool invalid.
+type Factory func(config *any.Any) (Filter, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a filter type available to pools.  It panics if the name is
+// already registered, and is meant to be called from init functions.
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("customfilter: filter %q registered twice", name))
+	}
+	factories[name] = f
+}
+
+// Names returns the names of the registered filter types, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns a Filter of the named type.
+func New(name string, config *any.Any) (Filter, error) {
+	mu.RLock()
+	f, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown custom filter %q, registered filters are %v", name, Names())
+	}
+	return f(config)
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customfilter
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// maskFilter passes entities whose "mask" double arg shares a bit with the
+// pool's mask.
+type maskFilter int64
+
+func (f maskFilter) In(entity Entity) bool {
+	return int64(entity.GetSearchFields().GetDoubleArgs()["mask"])&int64(f) != 0
+}
+
+func newMaskFilter(config *any.Any) (Filter, error) {
+	mask := &wrappers.Int64Value{}
+	if err := ptypes.UnmarshalAny(config, mask); err != nil {
+		return nil, err
+	}
+	return maskFilter(mask.GetValue()), nil
+}
+
+func TestRegister(t *testing.T) {
+	config, err := ptypes.MarshalAny(&wrappers.Int64Value{Value: 0x6})
+	require.NoError(t, err)
+
+	_, err = New("mask", config)
+	require.Error(t, err)
+
+	Register("mask", newMaskFilter)
+	require.Equal(t, []string{"mask"}, Names())
+
+	f, err := New("mask", config)
+	require.NoError(t, err)
+	require.True(t, f.In(&pb.Ticket{SearchFields: &pb.SearchFields{DoubleArgs: map[string]float64{"mask": 0x2}}}))
+	require.False(t, f.In(&pb.Ticket{SearchFields: &pb.SearchFields{DoubleArgs: map[string]float64{"mask": 0x1}}}))
+	require.False(t, f.In(&pb.Backfill{}))
+
+	// Invalid configurations are errors.
+	_, err = New("mask", &any.Any{TypeUrl: "type.googleapis.com/google.protobuf.StringValue"})
+	require.Error(t, err)
+
+	require.Panics(t, func() {
+		Register("mask", newMaskFilter)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package director provides helper methods to simplify authoring a director.
+package director
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// AssignmentTemplate builds Assignments whose connection string is rendered
+// from a text/template, eg "{{.Host}}:{{.Port}}?token={{.Token}}".  The
+// template is filled from whatever the game server allocator returns, so the
+// same director code works across platforms which only differ in how a
+// connection string is formed.
+type AssignmentTemplate struct {
+	connection *template.Template
+}
+
+// NewAssignmentTemplate parses connection as a text/template.  Referencing a
+// key which is missing from the allocator output is an error when the
+// template is executed, rather than silently rendering "<no value>".
+func NewAssignmentTemplate(connection string) (*AssignmentTemplate, error) {
+	t, err := template.New("connection").Option("missingkey=error").Parse(connection)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing assignment connection template: %w", err)
+	}
+	return &AssignmentTemplate{connection: t}, nil
+}
+
+// Assignment renders the connection template with the given allocator output.
+func (at *AssignmentTemplate) Assignment(allocation interface{}) (*pb.Assignment, error) {
+	var sb strings.Builder
+	if err := at.connection.Execute(&sb, allocation); err != nil {
+		return nil, fmt.Errorf("error executing assignment connection template: %w", err)
+	}
+	return &pb.Assignment{Connection: sb.String()}, nil
+}
+
+// AssignmentGroup renders the connection template with the given allocator
+// output and returns an AssignmentGroup assigning it to every ticket in match.
+func (at *AssignmentTemplate) AssignmentGroup(match *pb.Match, allocation interface{}) (*pb.AssignmentGroup, error) {
+	a, err := at.Assignment(allocation)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(match.GetTickets()))
+	for _, t := range match.GetTickets() {
+		ids = append(ids, t.GetId())
+	}
+
+	return &pb.AssignmentGroup{
+		TicketIds:  ids,
+		Assignment: a,
+	}, nil
+}
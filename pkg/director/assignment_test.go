@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package director
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+type allocation struct {
+	Host  string
+	Port  int
+	Token string
+}
+
+func TestAssignmentTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		template   string
+		allocation interface{}
+		want       string
+	}{
+		{
+			"struct",
+			"{{.Host}}:{{.Port}}?token={{.Token}}",
+			allocation{Host: "10.0.0.1", Port: 7777, Token: "abc"},
+			"10.0.0.1:7777?token=abc",
+		},
+		{
+			"map",
+			"{{.host}}:{{.port}}",
+			map[string]interface{}{"host": "10.0.0.1", "port": 7777},
+			"10.0.0.1:7777",
+		},
+		{
+			"constant",
+			"10.0.0.1:7777",
+			nil,
+			"10.0.0.1:7777",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			at, err := NewAssignmentTemplate(tc.template)
+			require.NoError(t, err)
+
+			a, err := at.Assignment(tc.allocation)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, a.GetConnection())
+		})
+	}
+}
+
+func TestAssignmentTemplateErrors(t *testing.T) {
+	_, err := NewAssignmentTemplate("{{.Host")
+	require.Error(t, err)
+
+	at, err := NewAssignmentTemplate("{{.host}}:{{.port}}")
+	require.NoError(t, err)
+
+	a, err := at.Assignment(map[string]interface{}{"host": "10.0.0.1"})
+	require.Error(t, err)
+	require.Nil(t, a)
+
+	at, err = NewAssignmentTemplate("{{.Hostname}}")
+	require.NoError(t, err)
+
+	a, err = at.Assignment(allocation{Host: "10.0.0.1"})
+	require.Error(t, err)
+	require.Nil(t, a)
+}
+
+func TestAssignmentGroup(t *testing.T) {
+	at, err := NewAssignmentTemplate("{{.Host}}:{{.Port}}")
+	require.NoError(t, err)
+
+	match := &pb.Match{
+		Tickets: []*pb.Ticket{{Id: "1"}, {Id: "2"}},
+	}
+	ag, err := at.AssignmentGroup(match, allocation{Host: "10.0.0.1", Port: 7777})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2"}, ag.GetTicketIds())
+	require.Equal(t, "10.0.0.1:7777", ag.GetAssignment().GetConnection())
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errorinfo defines the google.rpc.ErrorInfo details carried by errors
+// returned from Open Match's APIs.  Clients can branch on an error's reason,
+// and read the ids involved from its metadata, rather than parsing messages.
+//
+// Every error returned by an Open Match service carries an ErrorInfo in the
+// Domain.  Errors without a more specific reason use the name of their status
+// code, eg "INVALID_ARGUMENT".
+package errorinfo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the ErrorInfo domain of all errors returned by Open Match.
+const Domain = "open-match.dev"
+
+// Reasons for errors which clients commonly need to handle.
+const (
+	// ReasonTicketNotFound means the ticket does not exist, it may have been
+	// deleted after being assigned.
+	ReasonTicketNotFound = "TICKET_NOT_FOUND"
+	// ReasonBackfillNotFound means the backfill does not exist.
+	ReasonBackfillNotFound = "BACKFILL_NOT_FOUND"
+	// ReasonInvalidPool means a pool's filters are not valid.
+	ReasonInvalidPool = "INVALID_POOL"
+	// ReasonMatchFunctionFailed means the match function could not be called,
+	// or returned an error.
+	ReasonMatchFunctionFailed = "MATCH_FUNCTION_FAILED"
+	// ReasonBlackout means a pool or match profile is closed to matchmaking
+	// by a configured blackout window, rather than empty or unavailable.
+	// Retry once the window ends.
+	ReasonBlackout = "BLACKOUT"
+	// ReasonPaused means matchmaking is paused for a match profile by an
+	// operator.  It is not an error: FetchMatches returns no matches, and
+	// carries the reason in its TrailerReason trailer.
+	ReasonPaused = "PAUSED"
+)
+
+// TrailerReason is the gRPC trailer carrying the reason a call which
+// succeeded did none of its work, such as ReasonPaused.
+const TrailerReason = "open-match-reason"
+
+// Metadata keys of the ids involved in an error.
+const (
+	MetadataTicketID          = "ticket_id"
+	MetadataBackfillID        = "backfill_id"
+	MetadataPoolName          = "pool_name"
+	MetadataProfileName       = "profile_name"
+	MetadataMatchFunctionHost = "mmf_host"
+	// MetadataBlackoutEnd is the RFC 3339 time a blackout window ends.
+	MetadataBlackoutEnd = "blackout_end"
+)
+
+// New returns an error with the code and message, carrying an ErrorInfo with
+// the reason and metadata.
+func New(c codes.Code, reason string, metadata map[string]string, msg string) error {
+	s, err := status.New(c, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		// Only possible if ErrorInfo can't be marshaled.
+		return status.Error(c, msg)
+	}
+	return s.Err()
+}
+
+// Errorf returns New(c, reason, metadata, fmt.Sprintf(format, a...)).
+func Errorf(c codes.Code, reason string, metadata map[string]string, format string, a ...interface{}) error {
+	return New(c, reason, metadata, fmt.Sprintf(format, a...))
+}
+
+// FromError returns the ErrorInfo carried by err, if any.
+func FromError(err error) (*errdetails.ErrorInfo, bool) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// WithDefault returns err with an ErrorInfo whose reason is derived from its
+// status code, unless err is nil or already carries an ErrorInfo.  Services
+// call it on every error they return.
+func WithDefault(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := FromError(err); ok {
+		return err
+	}
+
+	s := status.Convert(err)
+	if s.Code() == codes.OK {
+		return err
+	}
+
+	ds, dErr := s.WithDetails(&errdetails.ErrorInfo{
+		Reason: codeReason(s.Code()),
+		Domain: Domain,
+	})
+	if dErr != nil {
+		return err
+	}
+	return ds.Err()
+}
+
+// codeReason converts a code's name to the UPPER_SNAKE_CASE used for reasons,
+// eg "DeadlineExceeded" to "DEADLINE_EXCEEDED".
+func codeReason(c codes.Code) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, r := range c.String() {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return b.String()
+}
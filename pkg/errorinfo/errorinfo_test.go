@@ -0,0 +1,65 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorinfo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNew(t *testing.T) {
+	err := Errorf(codes.NotFound, ReasonTicketNotFound, map[string]string{MetadataTicketID: "1"}, "Ticket id: %s not found", "1")
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, "Ticket id: 1 not found", status.Convert(err).Message())
+
+	info, ok := FromError(err)
+	require.True(t, ok)
+	require.Equal(t, ReasonTicketNotFound, info.Reason)
+	require.Equal(t, Domain, info.Domain)
+	require.Equal(t, map[string]string{MetadataTicketID: "1"}, info.Metadata)
+}
+
+func TestWithDefault(t *testing.T) {
+	require.Nil(t, WithDefault(nil))
+
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{status.Error(codes.InvalidArgument, ".pool is required"), codes.InvalidArgument, "INVALID_ARGUMENT"},
+		{status.Error(codes.DeadlineExceeded, "too slow"), codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+		{errors.New("plain"), codes.Unknown, "UNKNOWN"},
+		{New(codes.NotFound, ReasonBackfillNotFound, nil, "gone"), codes.NotFound, ReasonBackfillNotFound},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.reason, func(t *testing.T) {
+			err := WithDefault(test.err)
+			require.Equal(t, test.code, status.Code(err))
+			require.Equal(t, status.Convert(test.err).Message(), status.Convert(err).Message())
+
+			info, ok := FromError(err)
+			require.True(t, ok)
+			require.Equal(t, test.reason, info.Reason)
+			require.Equal(t, Domain, info.Domain)
+		})
+	}
+}
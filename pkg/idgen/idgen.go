@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package idgen generates the ids Open Match gives to tickets and backfills.
+// The scheme is chosen by the idScheme config, among the built-in schemes and
+// any registered with Register.  Match functions may use the same package to
+// give their matches ids of the same scheme.
+package idgen
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	// XID ids are 20 character base32 strings, sorted by creation second.
+	XID = "xid"
+	// UUIDv7 ids are RFC 4122 formatted version 7 UUIDs, sorted by creation
+	// millisecond.
+	UUIDv7 = "uuidv7"
+	// Snowflake ids are 16 hex digits holding the creation millisecond, the
+	// node and a sequence number, prefixed by "<region>-" if a region is set.
+	// They sort by creation millisecond within a region.
+	Snowflake = "snowflake"
+)
+
+// Generator creates unique ids.  It must be safe for concurrent use.
+type Generator interface {
+	NewID() string
+}
+
+// Options configure a Generator.  Schemes ignore the options they don't use.
+type Options struct {
+	// Region is prefixed to Snowflake ids, for routing requests to the region
+	// which created them.
+	Region string
+	// Node tells apart the processes creating ids, and must be unique among
+	// them.  Snowflake uses its lowest 10 bits.
+	Node int64
+}
+
+// Factory creates a Generator for a scheme.
+type Factory func(opts Options) (Generator, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		XID:       newXID,
+		UUIDv7:    newUUIDv7,
+		Snowflake: newSnowflake,
+	}
+)
+
+// Register makes a scheme available to New.  It panics if the scheme is
+// already registered, and is meant to be called from init functions.
+func Register(scheme string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := factories[scheme]; ok {
+		panic(fmt.Sprintf("idgen: scheme %q registered twice", scheme))
+	}
+	factories[scheme] = f
+}
+
+// Schemes returns the names of the registered schemes, sorted.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	schemes := make([]string, 0, len(factories))
+	for scheme := range factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// New returns a Generator of the scheme.
+func New(scheme string, opts Options) (Generator, error) {
+	mu.RLock()
+	f, ok := factories[scheme]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown id scheme %q, registered schemes are %v", scheme, Schemes())
+	}
+	return f(opts)
+}
+
+// NodeFromHostname derives a node from the hostname, which tells apart the
+// pods of a deployment.  The hash is reduced to the 10 bits of a snowflake
+// node, so two pods may get the same node: with 10 replicas the odds are about
+// 1 in 25, and with 40 replicas about 1 in 2.  Pods sharing a node can create
+// the same snowflake id within the same millisecond, so deployments which run
+// several replicas creating snowflake ids should set nodes explicitly.
+func NodeFromHostname() int64 {
+	hostname, _ := os.Hostname()
+	h := fnv.New32a()
+	h.Write([]byte(hostname))
+	return int64(h.Sum32() & snowflakeNodeMask)
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idgen
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemes(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		opts    Options
+		pattern string
+	}{
+		{XID, Options{}, `^[0-9a-v]{20}$`},
+		{UUIDv7, Options{}, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{Snowflake, Options{Node: 5}, `^[0-9a-f]{16}$`},
+		{Snowflake, Options{Region: "us-west1", Node: 5}, `^us-west1-[0-9a-f]{16}$`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.scheme+tt.opts.Region, func(t *testing.T) {
+			t.Parallel()
+			g, err := New(tt.scheme, tt.opts)
+			require.NoError(t, err)
+
+			// Ids created in later milliseconds sort after earlier ones.
+			var ids []string
+			seen := map[string]bool{}
+			for i := 0; i < 1000; i++ {
+				id := g.NewID()
+				require.Regexp(t, regexp.MustCompile(tt.pattern), id)
+				require.False(t, seen[id], "duplicate id %s", id)
+				seen[id] = true
+				ids = append(ids, id)
+			}
+			time.Sleep(1100 * time.Millisecond)
+			later := g.NewID()
+			for _, id := range ids {
+				require.Less(t, id, later)
+			}
+		})
+	}
+}
+
+func TestSnowflakeConcurrent(t *testing.T) {
+	g, err := New(Snowflake, Options{Node: snowflakeNodeMask})
+	require.NoError(t, err)
+
+	const goroutines, perGoroutine = 8, 2000
+	ids := make([][]string, goroutines)
+	var wg sync.WaitGroup
+	for i := range ids {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				ids[i] = append(ids[i], g.NewID())
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, batch := range ids {
+		// Each goroutine sees its ids increase.
+		require.True(t, sort.StringsAreSorted(batch))
+		for _, id := range batch {
+			require.False(t, seen[id], "duplicate id %s", id)
+			seen[id] = true
+		}
+	}
+}
+
+func TestSnowflakeNode(t *testing.T) {
+	_, err := New(Snowflake, Options{Node: snowflakeNodeMask + 1})
+	require.Error(t, err)
+	_, err = New(Snowflake, Options{Node: -1})
+	require.Error(t, err)
+
+	node := NodeFromHostname()
+	require.True(t, node >= 0 && node <= snowflakeNodeMask, "node %d", node)
+}
+
+type fixedGenerator string
+
+func (g fixedGenerator) NewID() string {
+	return string(g)
+}
+
+func TestRegister(t *testing.T) {
+	_, err := New("fixed", Options{})
+	require.Error(t, err)
+
+	Register("fixed", func(opts Options) (Generator, error) {
+		return fixedGenerator(opts.Region + "-1"), nil
+	})
+	require.Contains(t, Schemes(), "fixed")
+
+	g, err := New("fixed", Options{Region: "eu"})
+	require.NoError(t, err)
+	require.Equal(t, "eu-1", g.NewID())
+
+	require.Panics(t, func() {
+		Register(XID, newXID)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+type xidGenerator struct{}
+
+func newXID(Options) (Generator, error) {
+	return xidGenerator{}, nil
+}
+
+func (xidGenerator) NewID() string {
+	return xid.New().String()
+}
+
+type uuidv7Generator struct{}
+
+func newUUIDv7(Options) (Generator, error) {
+	return uuidv7Generator{}, nil
+}
+
+// NewID returns a UUID with the unix millisecond in its first 48 bits, and 74
+// random bits.
+func (uuidv7Generator) NewID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(fmt.Sprintf("idgen: failed to read random bytes: %v", err))
+	}
+
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> (40 - 8*uint(i)))
+	}
+	u[6] = u[6]&0x0f | 0x70 // Version 7.
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant.
+
+	var s [36]byte
+	hex.Encode(s[0:8], u[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], u[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], u[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], u[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], u[10:])
+	return string(s[:])
+}
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeNodeMask     = 1<<snowflakeNodeBits - 1
+	snowflakeSequenceMask = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch is the start of the 41 bit millisecond clock, which lasts
+// until 2089.
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type snowflakeGenerator struct {
+	prefix string
+	node   int64
+
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+}
+
+func newSnowflake(opts Options) (Generator, error) {
+	if opts.Node < 0 || opts.Node > snowflakeNodeMask {
+		return nil, fmt.Errorf("snowflake node must be between 0 and %d, got %d", snowflakeNodeMask, opts.Node)
+	}
+	g := &snowflakeGenerator{node: opts.Node}
+	if opts.Region != "" {
+		g.prefix = opts.Region + "-"
+	}
+	return g, nil
+}
+
+func (g *snowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	now := g.millis()
+	// Keep ids increasing if the clock goes backwards.
+	if now < g.last {
+		now = g.last
+	}
+	if now == g.last {
+		g.sequence = (g.sequence + 1) & snowflakeSequenceMask
+		if g.sequence == 0 {
+			// The sequence ran out for this millisecond, wait for the next.
+			for now <= g.last {
+				time.Sleep(time.Millisecond / 10)
+				now = g.millis()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.last = now
+	id := now<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+	g.mu.Unlock()
+
+	return fmt.Sprintf("%s%016x", g.prefix, id)
+}
+
+func (g *snowflakeGenerator) millis() int64 {
+	return int64(time.Since(snowflakeEpoch) / time.Millisecond)
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchfunction
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/protobuf/ptypes"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// BoostKey is the ticket extension key holding a pb.TicketBoost.
+const BoostKey = "boost"
+
+// GetBoost returns the ticket's boost, or 0 if the ticket does not have one.
+func GetBoost(ticket *pb.Ticket) (float64, error) {
+	a, ok := ticket.GetExtensions()[BoostKey]
+	if !ok {
+		return 0, nil
+	}
+
+	var b pb.TicketBoost
+	err := ptypes.UnmarshalAny(a, &b)
+	if err != nil {
+		return 0, fmt.Errorf("error unpacking %s extension: %w", BoostKey, err)
+	}
+
+	if math.IsNaN(b.GetBoost()) || math.IsInf(b.GetBoost(), 0) {
+		return 0, fmt.Errorf("invalid %s extension: boost must be finite, got %v", BoostKey, b.GetBoost())
+	}
+
+	return b.GetBoost(), nil
+}
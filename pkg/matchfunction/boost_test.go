@@ -0,0 +1,91 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchfunction
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func newBoostTicket(t *testing.T, boost float64) *pb.Ticket {
+	a, err := ptypes.MarshalAny(&pb.TicketBoost{Boost: boost})
+	require.NoError(t, err)
+	return &pb.Ticket{
+		Extensions: map[string]*any.Any{BoostKey: a},
+	}
+}
+
+func TestGetBoost(t *testing.T) {
+	wrongType, err := ptypes.MarshalAny(&wrappers.Int32Value{Value: 1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		ticket      *pb.Ticket
+		wantBoost   float64
+		wantErr     bool
+	}{
+		{
+			description: "no extensions",
+			ticket:      &pb.Ticket{},
+		},
+		{
+			description: "boost set",
+			ticket:      newBoostTicket(t, 2.5),
+			wantBoost:   2.5,
+		},
+		{
+			description: "negative boost",
+			ticket:      newBoostTicket(t, -1),
+			wantBoost:   -1,
+		},
+		{
+			description: "infinite boost",
+			ticket:      newBoostTicket(t, math.Inf(1)),
+			wantErr:     true,
+		},
+		{
+			description: "NaN boost",
+			ticket:      newBoostTicket(t, math.NaN()),
+			wantErr:     true,
+		},
+		{
+			description: "wrong extension type",
+			ticket: &pb.Ticket{
+				Extensions: map[string]*any.Any{BoostKey: wrongType},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			boost, err := GetBoost(test.ticket)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantBoost, boost)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchfunction
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// MatchmakingDeadlineKey is the ticket extension key holding a pb.MatchmakingDeadline.
+const MatchmakingDeadlineKey = "matchmaking_deadline"
+
+// GetMatchmakingDeadline returns the time by which the ticket must be matched.
+// The returned bool is false if the ticket does not have a deadline.
+func GetMatchmakingDeadline(ticket *pb.Ticket) (time.Time, bool, error) {
+	a, ok := ticket.GetExtensions()[MatchmakingDeadlineKey]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	var d pb.MatchmakingDeadline
+	err := ptypes.UnmarshalAny(a, &d)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("error unpacking %s extension: %w", MatchmakingDeadlineKey, err)
+	}
+
+	deadline, err := ptypes.Timestamp(d.GetDeadline())
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s extension: %w", MatchmakingDeadlineKey, err)
+	}
+
+	return deadline, true, nil
+}
+
+// SortByMatchmakingDeadline orders tickets so those closest to their
+// matchmaking deadline come first.  Tickets without a valid deadline keep their
+// relative order after all tickets that have one.
+func SortByMatchmakingDeadline(tickets []*pb.Ticket) {
+	type entry struct {
+		ticket      *pb.Ticket
+		deadline    time.Time
+		hasDeadline bool
+	}
+
+	entries := make([]entry, len(tickets))
+	for i, t := range tickets {
+		deadline, ok, err := GetMatchmakingDeadline(t)
+		entries[i] = entry{ticket: t, deadline: deadline, hasDeadline: ok && err == nil}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].hasDeadline != entries[j].hasDeadline {
+			return entries[i].hasDeadline
+		}
+		return entries[i].deadline.Before(entries[j].deadline)
+	})
+
+	for i, e := range entries {
+		tickets[i] = e.ticket
+	}
+}
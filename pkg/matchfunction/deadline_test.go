@@ -0,0 +1,101 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchfunction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func newDeadlineTicket(t *testing.T, id string, deadline time.Time) *pb.Ticket {
+	ts, err := ptypes.TimestampProto(deadline)
+	require.NoError(t, err)
+	a, err := ptypes.MarshalAny(&pb.MatchmakingDeadline{Deadline: ts})
+	require.NoError(t, err)
+	return &pb.Ticket{
+		Id:         id,
+		Extensions: map[string]*any.Any{MatchmakingDeadlineKey: a},
+	}
+}
+
+func TestGetMatchmakingDeadline(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+	wrongType, err := ptypes.MarshalAny(&wrappers.Int32Value{Value: 1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		description  string
+		ticket       *pb.Ticket
+		wantDeadline time.Time
+		wantOk       bool
+		wantErr      bool
+	}{
+		{
+			description: "no extensions",
+			ticket:      &pb.Ticket{},
+		},
+		{
+			description:  "deadline set",
+			ticket:       newDeadlineTicket(t, "a", now),
+			wantDeadline: now,
+			wantOk:       true,
+		},
+		{
+			description: "wrong extension type",
+			ticket: &pb.Ticket{
+				Extensions: map[string]*any.Any{MatchmakingDeadlineKey: wrongType},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			deadline, ok, err := GetMatchmakingDeadline(test.ticket)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantOk, ok)
+			require.True(t, test.wantDeadline.Equal(deadline))
+		})
+	}
+}
+
+func TestSortByMatchmakingDeadline(t *testing.T) {
+	now := time.Now()
+	tickets := []*pb.Ticket{
+		{Id: "none-1"},
+		newDeadlineTicket(t, "late", now.Add(time.Minute)),
+		{Id: "none-2"},
+		newDeadlineTicket(t, "soon", now.Add(time.Second)),
+	}
+
+	SortByMatchmakingDeadline(tickets)
+
+	var got []string
+	for _, ticket := range tickets {
+		got = append(got, ticket.GetId())
+	}
+	require.Equal(t, []string{"soon", "late", "none-1", "none-2"}, got)
+}
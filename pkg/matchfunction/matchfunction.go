@@ -87,6 +87,22 @@ func QueryPools(ctx context.Context, queryClient pb.QueryServiceClient, pools []
 	return poolMap, nil
 }
 
+// QueryAvailableCapacity queries queryService and returns the number of matches the game servers
+// in region can currently host, or in every region if region is empty.  The returned bool is
+// false if no capacity has been published, in which case match production should not be throttled.
+func QueryAvailableCapacity(ctx context.Context, queryClient pb.QueryServiceClient, region string, opts ...grpc.CallOption) (int, bool, error) {
+	resp, err := queryClient.QueryServerCapacity(ctx, &pb.QueryServerCapacityRequest{Region: region}, opts...)
+	if err != nil {
+		return 0, false, fmt.Errorf("error calling queryService.QueryServerCapacity: %w", err)
+	}
+
+	available := 0
+	for _, c := range resp.GetCapacities() {
+		available += int(c.GetAvailable())
+	}
+	return available, len(resp.GetCapacities()) > 0, nil
+}
+
 // QueryBackfillPool queries queryService and returns the backfills that belong to the specified pool.
 func QueryBackfillPool(ctx context.Context, queryClient pb.QueryServiceClient, pool *pb.Pool, opts ...grpc.CallOption) ([]*pb.Backfill, error) {
 	query, err := queryClient.QueryBackfills(ctx, &pb.QueryBackfillsRequest{Pool: pool}, opts...)
@@ -40,6 +40,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type QueryTicketsResponse_State int32
+
+const (
+	// The ticket can be returned by queries and proposed in matches.
+	QueryTicketsResponse_ACTIVE QueryTicketsResponse_State = 0
+	// The ticket was proposed in a match, and is awaiting assignment or
+	// release.
+	QueryTicketsResponse_PENDING QueryTicketsResponse_State = 1
+	// The ticket was assigned, and will be deleted after `assignedDeleteTimeout`.
+	QueryTicketsResponse_ASSIGNED QueryTicketsResponse_State = 2
+)
+
+// Enum value maps for QueryTicketsResponse_State.
+var (
+	QueryTicketsResponse_State_name = map[int32]string{
+		0: "ACTIVE",
+		1: "PENDING",
+		2: "ASSIGNED",
+	}
+	QueryTicketsResponse_State_value = map[string]int32{
+		"ACTIVE":   0,
+		"PENDING":  1,
+		"ASSIGNED": 2,
+	}
+)
+
+func (x QueryTicketsResponse_State) Enum() *QueryTicketsResponse_State {
+	p := new(QueryTicketsResponse_State)
+	*p = x
+	return p
+}
+
+func (x QueryTicketsResponse_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueryTicketsResponse_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_query_proto_enumTypes[0].Descriptor()
+}
+
+func (QueryTicketsResponse_State) Type() protoreflect.EnumType {
+	return &file_api_query_proto_enumTypes[0]
+}
+
+func (x QueryTicketsResponse_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueryTicketsResponse_State.Descriptor instead.
+func (QueryTicketsResponse_State) EnumDescriptor() ([]byte, []int) {
+	return file_api_query_proto_rawDescGZIP(), []int{1, 0}
+}
+
 type QueryTicketsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -47,6 +100,12 @@ type QueryTicketsRequest struct {
 
 	// The Pool representing the set of Filters to be queried.
 	Pool *Pool `protobuf:"bytes,1,opt,name=pool,proto3" json:"pool,omitempty"`
+	// Also return tickets which are pending (proposed in a match, and not yet
+	// assigned or released) or assigned, along with their state, for
+	// reconciliation jobs which audit game server rosters against matchmaking
+	// records.  Only allowed for the clients listed in the `queryAuditClients`
+	// config, and not supported with paginated queries.
+	IncludeInactive bool `protobuf:"varint,2,opt,name=include_inactive,json=includeInactive,proto3" json:"include_inactive,omitempty"`
 }
 
 func (x *QueryTicketsRequest) Reset() {
@@ -88,6 +147,13 @@ func (x *QueryTicketsRequest) GetPool() *Pool {
 	return nil
 }
 
+func (x *QueryTicketsRequest) GetIncludeInactive() bool {
+	if x != nil {
+		return x.IncludeInactive
+	}
+	return false
+}
+
 type QueryTicketsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -95,6 +161,12 @@ type QueryTicketsResponse struct {
 
 	// Tickets that meet all the filtering criteria requested by the pool.
 	Tickets []*Ticket `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	// The states of the tickets, in the same order.  Only set when the request
+	// sets include_inactive.
+	States []QueryTicketsResponse_State `protobuf:"varint,2,rep,packed,name=states,proto3,enum=openmatch.QueryTicketsResponse_State" json:"states,omitempty"`
+	// Ids of the tickets which meet every filter of the pool except its max_age.
+	// Only set when the pool sets report_expired, and sent after the tickets.
+	ExpiredIds []string `protobuf:"bytes,3,rep,name=expired_ids,json=expiredIds,proto3" json:"expired_ids,omitempty"`
 }
 
 func (x *QueryTicketsResponse) Reset() {
@@ -136,6 +208,135 @@ func (x *QueryTicketsResponse) GetTickets() []*Ticket {
 	return nil
 }
 
+func (x *QueryTicketsResponse) GetStates() []QueryTicketsResponse_State {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *QueryTicketsResponse) GetExpiredIds() []string {
+	if x != nil {
+		return x.ExpiredIds
+	}
+	return nil
+}
+
+type ExportTicketsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Pool representing the set of Filters the exported tickets must meet.
+	// Every ticket is exported if unset.
+	Pool *Pool `protobuf:"bytes,1,opt,name=pool,proto3" json:"pool,omitempty"`
+	// Also export tickets which are pending or assigned.
+	IncludeInactive bool `protobuf:"varint,2,opt,name=include_inactive,json=includeInactive,proto3" json:"include_inactive,omitempty"`
+}
+
+func (x *ExportTicketsRequest) Reset() {
+	*x = ExportTicketsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_query_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportTicketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTicketsRequest) ProtoMessage() {}
+
+func (x *ExportTicketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_query_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTicketsRequest.ProtoReflect.Descriptor instead.
+func (*ExportTicketsRequest) Descriptor() ([]byte, []int) {
+	return file_api_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExportTicketsRequest) GetPool() *Pool {
+	if x != nil {
+		return x.Pool
+	}
+	return nil
+}
+
+func (x *ExportTicketsRequest) GetIncludeInactive() bool {
+	if x != nil {
+		return x.IncludeInactive
+	}
+	return false
+}
+
+type ExportTicketsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A batch of the exported tickets.
+	Tickets []*Ticket `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	// The states of the tickets, in the same order.
+	States []QueryTicketsResponse_State `protobuf:"varint,2,rep,packed,name=states,proto3,enum=openmatch.QueryTicketsResponse_State" json:"states,omitempty"`
+}
+
+func (x *ExportTicketsResponse) Reset() {
+	*x = ExportTicketsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_query_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportTicketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTicketsResponse) ProtoMessage() {}
+
+func (x *ExportTicketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_query_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTicketsResponse.ProtoReflect.Descriptor instead.
+func (*ExportTicketsResponse) Descriptor() ([]byte, []int) {
+	return file_api_query_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExportTicketsResponse) GetTickets() []*Ticket {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+func (x *ExportTicketsResponse) GetStates() []QueryTicketsResponse_State {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
 type QueryTicketIdsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -148,7 +349,7 @@ type QueryTicketIdsRequest struct {
 func (x *QueryTicketIdsRequest) Reset() {
 	*x = QueryTicketIdsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_query_proto_msgTypes[2]
+		mi := &file_api_query_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -161,7 +362,7 @@ func (x *QueryTicketIdsRequest) String() string {
 func (*QueryTicketIdsRequest) ProtoMessage() {}
 
 func (x *QueryTicketIdsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_query_proto_msgTypes[2]
+	mi := &file_api_query_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -174,7 +375,7 @@ func (x *QueryTicketIdsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryTicketIdsRequest.ProtoReflect.Descriptor instead.
 func (*QueryTicketIdsRequest) Descriptor() ([]byte, []int) {
-	return file_api_query_proto_rawDescGZIP(), []int{2}
+	return file_api_query_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *QueryTicketIdsRequest) GetPool() *Pool {
@@ -196,7 +397,7 @@ type QueryTicketIdsResponse struct {
 func (x *QueryTicketIdsResponse) Reset() {
 	*x = QueryTicketIdsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_query_proto_msgTypes[3]
+		mi := &file_api_query_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -209,7 +410,7 @@ func (x *QueryTicketIdsResponse) String() string {
 func (*QueryTicketIdsResponse) ProtoMessage() {}
 
 func (x *QueryTicketIdsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_query_proto_msgTypes[3]
+	mi := &file_api_query_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -222,7 +423,7 @@ func (x *QueryTicketIdsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryTicketIdsResponse.ProtoReflect.Descriptor instead.
 func (*QueryTicketIdsResponse) Descriptor() ([]byte, []int) {
-	return file_api_query_proto_rawDescGZIP(), []int{3}
+	return file_api_query_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *QueryTicketIdsResponse) GetIds() []string {
@@ -246,7 +447,7 @@ type QueryBackfillsRequest struct {
 func (x *QueryBackfillsRequest) Reset() {
 	*x = QueryBackfillsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_query_proto_msgTypes[4]
+		mi := &file_api_query_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -259,7 +460,7 @@ func (x *QueryBackfillsRequest) String() string {
 func (*QueryBackfillsRequest) ProtoMessage() {}
 
 func (x *QueryBackfillsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_query_proto_msgTypes[4]
+	mi := &file_api_query_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -272,7 +473,7 @@ func (x *QueryBackfillsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryBackfillsRequest.ProtoReflect.Descriptor instead.
 func (*QueryBackfillsRequest) Descriptor() ([]byte, []int) {
-	return file_api_query_proto_rawDescGZIP(), []int{4}
+	return file_api_query_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *QueryBackfillsRequest) GetPool() *Pool {
@@ -296,7 +497,7 @@ type QueryBackfillsResponse struct {
 func (x *QueryBackfillsResponse) Reset() {
 	*x = QueryBackfillsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_query_proto_msgTypes[5]
+		mi := &file_api_query_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -309,7 +510,7 @@ func (x *QueryBackfillsResponse) String() string {
 func (*QueryBackfillsResponse) ProtoMessage() {}
 
 func (x *QueryBackfillsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_query_proto_msgTypes[5]
+	mi := &file_api_query_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -322,7 +523,7 @@ func (x *QueryBackfillsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryBackfillsResponse.ProtoReflect.Descriptor instead.
 func (*QueryBackfillsResponse) Descriptor() ([]byte, []int) {
-	return file_api_query_proto_rawDescGZIP(), []int{5}
+	return file_api_query_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *QueryBackfillsResponse) GetBackfills() []*Backfill {
@@ -332,6 +533,102 @@ func (x *QueryBackfillsResponse) GetBackfills() []*Backfill {
 	return nil
 }
 
+type QueryServerCapacityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Region to return capacity for.  Capacity for every region is returned if empty.
+	Region string `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+}
+
+func (x *QueryServerCapacityRequest) Reset() {
+	*x = QueryServerCapacityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_query_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryServerCapacityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryServerCapacityRequest) ProtoMessage() {}
+
+func (x *QueryServerCapacityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_query_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryServerCapacityRequest.ProtoReflect.Descriptor instead.
+func (*QueryServerCapacityRequest) Descriptor() ([]byte, []int) {
+	return file_api_query_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *QueryServerCapacityRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+type QueryServerCapacityResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Capacities currently published for the requested region.
+	Capacities []*ServerCapacity `protobuf:"bytes,1,rep,name=capacities,proto3" json:"capacities,omitempty"`
+}
+
+func (x *QueryServerCapacityResponse) Reset() {
+	*x = QueryServerCapacityResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_query_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryServerCapacityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryServerCapacityResponse) ProtoMessage() {}
+
+func (x *QueryServerCapacityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_query_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryServerCapacityResponse.ProtoReflect.Descriptor instead.
+func (*QueryServerCapacityResponse) Descriptor() ([]byte, []int) {
+	return file_api_query_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QueryServerCapacityResponse) GetCapacities() []*ServerCapacity {
+	if x != nil {
+		return x.Capacities
+	}
+	return nil
+}
+
 var File_api_query_proto protoreflect.FileDescriptor
 
 var file_api_query_proto_rawDesc = []byte{
@@ -342,57 +639,110 @@ var file_api_query_proto_rawDesc = []byte{
 	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x6f, 0x70, 0x65, 0x6e, 0x61,
 	0x70, 0x69, 0x76, 0x32, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x61, 0x6e, 0x6e,
-	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3a,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x65,
 	0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e,
-	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x22, 0x43, 0x0a, 0x14, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e,
-	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x22,
-	0x3c, 0x0a, 0x15, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x70, 0x6f, 0x6f, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74,
-	0x63, 0x68, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x22, 0x2a, 0x0a,
-	0x16, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22, 0x3c, 0x0a, 0x15, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x69, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x49, 0x6e, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x22, 0xd3, 0x01, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54,
+	0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b,
+	0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x54, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x3d, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x6f, 0x70,
+	0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63,
+	0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x49, 0x64, 0x73, 0x22, 0x2e, 0x0a, 0x05, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x00,
+	0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0c, 0x0a,
+	0x08, 0x41, 0x53, 0x53, 0x49, 0x47, 0x4e, 0x45, 0x44, 0x10, 0x02, 0x22, 0x66, 0x0a, 0x14, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x50, 0x6f,
+	0x6f, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6e, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x5f, 0x69, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x49, 0x6e, 0x61, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x22, 0x83, 0x01, 0x0a, 0x15, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a,
+	0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x3d, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x6f, 0x70, 0x65,
+	0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0x3c, 0x0a, 0x15, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
 	0x32, 0x0f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x50, 0x6f, 0x6f,
-	0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x22, 0x4b, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x72, 0x79,
-	0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x31, 0x0a, 0x09, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68,
-	0x2e, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x52, 0x09, 0x62, 0x61, 0x63, 0x6b, 0x66,
-	0x69, 0x6c, 0x6c, 0x73, 0x32, 0x9a, 0x03, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x7c, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69,
-	0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1e, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63,
-	0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63,
-	0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x29, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23, 0x22, 0x1e,
-	0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01,
-	0x2a, 0x30, 0x01, 0x12, 0x84, 0x01, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63,
-	0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x12, 0x20, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74,
-	0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d,
-	0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74,
-	0x49, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x25, 0x22, 0x20, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x69, 0x64, 0x73, 0x3a,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01, 0x2a, 0x30, 0x01, 0x12, 0x84, 0x01, 0x0a, 0x0e, 0x51,
-	0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x12, 0x20, 0x2e,
-	0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42,
-	0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x21, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x22, 0x20, 0x2f, 0x76, 0x31, 0x2f,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x62, 0x61, 0x63,
-	0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01, 0x2a, 0x30,
-	0x01, 0x42, 0x98, 0x03, 0x5a, 0x20, 0x6f, 0x70, 0x65, 0x6e, 0x2d, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x22, 0x2a, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03,
+	0x69, 0x64, 0x73, 0x22, 0x3c, 0x0a, 0x15, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b,
+	0x66, 0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x04,
+	0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6f, 0x70, 0x65,
+	0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6f,
+	0x6c, 0x22, 0x4b, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69,
+	0x6c, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x09, 0x62,
+	0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x66,
+	0x69, 0x6c, 0x6c, 0x52, 0x09, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x22, 0x34,
+	0x0a, 0x1a, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x67, 0x69, 0x6f, 0x6e, 0x22, 0x58, 0x0a, 0x1b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x69, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69,
+	0x74, 0x79, 0x52, 0x0a, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x69, 0x65, 0x73, 0x32, 0xb0,
+	0x05, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x7c, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12,
+	0x1e, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x29, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23, 0x22, 0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01, 0x2a, 0x30, 0x01, 0x12, 0x84, 0x01,
+	0x0a, 0x0e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73,
+	0x12, 0x20, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x22, 0x20, 0x2f,
+	0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
+	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x69, 0x64, 0x73, 0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a,
+	0x01, 0x2a, 0x30, 0x01, 0x12, 0x84, 0x01, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61,
+	0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73, 0x12, 0x20, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c,
+	0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6f, 0x70, 0x65, 0x6e,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x66,
+	0x69, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x25, 0x22, 0x20, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x73,
+	0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01, 0x2a, 0x30, 0x01, 0x12, 0x80, 0x01, 0x0a, 0x0d,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1f, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x2a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x24, 0x22, 0x1f, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x3a, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x3a, 0x01, 0x2a, 0x30, 0x01, 0x12, 0x90,
+	0x01, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x61,
+	0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12, 0x25, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74,
+	0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x61,
+	0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x24, 0x22, 0x1f, 0x2f,
+	0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
+	0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01,
+	0x2a, 0x42, 0x98, 0x03, 0x5a, 0x20, 0x6f, 0x70, 0x65, 0x6e, 0x2d, 0x6d, 0x61, 0x74, 0x63, 0x68,
 	0x2e, 0x64, 0x65, 0x76, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x2d, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2f,
 	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0xaa, 0x02, 0x09, 0x4f, 0x70, 0x65, 0x6e, 0x4d, 0x61, 0x74,
 	0x63, 0x68, 0x92, 0x41, 0xe6, 0x02, 0x12, 0xbf, 0x01, 0x0a, 0x15, 0x4d, 0x4d, 0x20, 0x4c, 0x6f,
@@ -433,35 +783,51 @@ func file_api_query_proto_rawDescGZIP() []byte {
 	return file_api_query_proto_rawDescData
 }
 
-var file_api_query_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_query_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_api_query_proto_goTypes = []interface{}{
-	(*QueryTicketsRequest)(nil),    // 0: openmatch.QueryTicketsRequest
-	(*QueryTicketsResponse)(nil),   // 1: openmatch.QueryTicketsResponse
-	(*QueryTicketIdsRequest)(nil),  // 2: openmatch.QueryTicketIdsRequest
-	(*QueryTicketIdsResponse)(nil), // 3: openmatch.QueryTicketIdsResponse
-	(*QueryBackfillsRequest)(nil),  // 4: openmatch.QueryBackfillsRequest
-	(*QueryBackfillsResponse)(nil), // 5: openmatch.QueryBackfillsResponse
-	(*Pool)(nil),                   // 6: openmatch.Pool
-	(*Ticket)(nil),                 // 7: openmatch.Ticket
-	(*Backfill)(nil),               // 8: openmatch.Backfill
+	(QueryTicketsResponse_State)(0),     // 0: openmatch.QueryTicketsResponse.State
+	(*QueryTicketsRequest)(nil),         // 1: openmatch.QueryTicketsRequest
+	(*QueryTicketsResponse)(nil),        // 2: openmatch.QueryTicketsResponse
+	(*ExportTicketsRequest)(nil),        // 3: openmatch.ExportTicketsRequest
+	(*ExportTicketsResponse)(nil),       // 4: openmatch.ExportTicketsResponse
+	(*QueryTicketIdsRequest)(nil),       // 5: openmatch.QueryTicketIdsRequest
+	(*QueryTicketIdsResponse)(nil),      // 6: openmatch.QueryTicketIdsResponse
+	(*QueryBackfillsRequest)(nil),       // 7: openmatch.QueryBackfillsRequest
+	(*QueryBackfillsResponse)(nil),      // 8: openmatch.QueryBackfillsResponse
+	(*QueryServerCapacityRequest)(nil),  // 9: openmatch.QueryServerCapacityRequest
+	(*QueryServerCapacityResponse)(nil), // 10: openmatch.QueryServerCapacityResponse
+	(*Pool)(nil),                        // 11: openmatch.Pool
+	(*Ticket)(nil),                      // 12: openmatch.Ticket
+	(*Backfill)(nil),                    // 13: openmatch.Backfill
+	(*ServerCapacity)(nil),              // 14: openmatch.ServerCapacity
 }
 var file_api_query_proto_depIdxs = []int32{
-	6, // 0: openmatch.QueryTicketsRequest.pool:type_name -> openmatch.Pool
-	7, // 1: openmatch.QueryTicketsResponse.tickets:type_name -> openmatch.Ticket
-	6, // 2: openmatch.QueryTicketIdsRequest.pool:type_name -> openmatch.Pool
-	6, // 3: openmatch.QueryBackfillsRequest.pool:type_name -> openmatch.Pool
-	8, // 4: openmatch.QueryBackfillsResponse.backfills:type_name -> openmatch.Backfill
-	0, // 5: openmatch.QueryService.QueryTickets:input_type -> openmatch.QueryTicketsRequest
-	2, // 6: openmatch.QueryService.QueryTicketIds:input_type -> openmatch.QueryTicketIdsRequest
-	4, // 7: openmatch.QueryService.QueryBackfills:input_type -> openmatch.QueryBackfillsRequest
-	1, // 8: openmatch.QueryService.QueryTickets:output_type -> openmatch.QueryTicketsResponse
-	3, // 9: openmatch.QueryService.QueryTicketIds:output_type -> openmatch.QueryTicketIdsResponse
-	5, // 10: openmatch.QueryService.QueryBackfills:output_type -> openmatch.QueryBackfillsResponse
-	8, // [8:11] is the sub-list for method output_type
-	5, // [5:8] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	11, // 0: openmatch.QueryTicketsRequest.pool:type_name -> openmatch.Pool
+	12, // 1: openmatch.QueryTicketsResponse.tickets:type_name -> openmatch.Ticket
+	0,  // 2: openmatch.QueryTicketsResponse.states:type_name -> openmatch.QueryTicketsResponse.State
+	11, // 3: openmatch.ExportTicketsRequest.pool:type_name -> openmatch.Pool
+	12, // 4: openmatch.ExportTicketsResponse.tickets:type_name -> openmatch.Ticket
+	0,  // 5: openmatch.ExportTicketsResponse.states:type_name -> openmatch.QueryTicketsResponse.State
+	11, // 6: openmatch.QueryTicketIdsRequest.pool:type_name -> openmatch.Pool
+	11, // 7: openmatch.QueryBackfillsRequest.pool:type_name -> openmatch.Pool
+	13, // 8: openmatch.QueryBackfillsResponse.backfills:type_name -> openmatch.Backfill
+	14, // 9: openmatch.QueryServerCapacityResponse.capacities:type_name -> openmatch.ServerCapacity
+	1,  // 10: openmatch.QueryService.QueryTickets:input_type -> openmatch.QueryTicketsRequest
+	5,  // 11: openmatch.QueryService.QueryTicketIds:input_type -> openmatch.QueryTicketIdsRequest
+	7,  // 12: openmatch.QueryService.QueryBackfills:input_type -> openmatch.QueryBackfillsRequest
+	3,  // 13: openmatch.QueryService.ExportTickets:input_type -> openmatch.ExportTicketsRequest
+	9,  // 14: openmatch.QueryService.QueryServerCapacity:input_type -> openmatch.QueryServerCapacityRequest
+	2,  // 15: openmatch.QueryService.QueryTickets:output_type -> openmatch.QueryTicketsResponse
+	6,  // 16: openmatch.QueryService.QueryTicketIds:output_type -> openmatch.QueryTicketIdsResponse
+	8,  // 17: openmatch.QueryService.QueryBackfills:output_type -> openmatch.QueryBackfillsResponse
+	4,  // 18: openmatch.QueryService.ExportTickets:output_type -> openmatch.ExportTicketsResponse
+	10, // 19: openmatch.QueryService.QueryServerCapacity:output_type -> openmatch.QueryServerCapacityResponse
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_api_query_proto_init() }
@@ -496,7 +862,7 @@ func file_api_query_proto_init() {
 			}
 		}
 		file_api_query_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryTicketIdsRequest); i {
+			switch v := v.(*ExportTicketsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -508,7 +874,7 @@ func file_api_query_proto_init() {
 			}
 		}
 		file_api_query_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryTicketIdsResponse); i {
+			switch v := v.(*ExportTicketsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -520,7 +886,7 @@ func file_api_query_proto_init() {
 			}
 		}
 		file_api_query_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryBackfillsRequest); i {
+			switch v := v.(*QueryTicketIdsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -532,6 +898,30 @@ func file_api_query_proto_init() {
 			}
 		}
 		file_api_query_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryTicketIdsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_query_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryBackfillsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_query_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryBackfillsResponse); i {
 			case 0:
 				return &v.state
@@ -543,19 +933,44 @@ func file_api_query_proto_init() {
 				return nil
 			}
 		}
+		file_api_query_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryServerCapacityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_query_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryServerCapacityResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_query_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      1,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_query_proto_goTypes,
 		DependencyIndexes: file_api_query_proto_depIdxs,
+		EnumInfos:         file_api_query_proto_enumTypes,
 		MessageInfos:      file_api_query_proto_msgTypes,
 	}.Build()
 	File_api_query_proto = out.File
@@ -590,6 +1005,17 @@ type QueryServiceClient interface {
 	// BETA FEATURE WARNING:  This call and the associated Request and Response
 	// messages are not finalized and still subject to possible change or removal.
 	QueryBackfills(ctx context.Context, in *QueryBackfillsRequest, opts ...grpc.CallOption) (QueryService_QueryBackfillsClient, error)
+	// ExportTickets streams every ticket in the state storage, optionally
+	// filtered by a Pool, for building external indexes, analytics snapshots and
+	// migrations.  Tickets are read from the state storage in batches of
+	// `queryPageSize` as the caller receives them, so a slow caller holds back
+	// the export instead of buffering it.  Tickets created after the export
+	// starts are not exported.
+	//   - Only allowed for the clients listed in the `queryAuditClients` config.
+	ExportTickets(ctx context.Context, in *ExportTicketsRequest, opts ...grpc.CallOption) (QueryService_ExportTicketsClient, error)
+	// QueryServerCapacity returns the unexpired game server capacity published by
+	// allocators.  No capacities means the capacity registry is not in use.
+	QueryServerCapacity(ctx context.Context, in *QueryServerCapacityRequest, opts ...grpc.CallOption) (*QueryServerCapacityResponse, error)
 }
 
 type queryServiceClient struct {
@@ -696,6 +1122,47 @@ func (x *queryServiceQueryBackfillsClient) Recv() (*QueryBackfillsResponse, erro
 	return m, nil
 }
 
+func (c *queryServiceClient) ExportTickets(ctx context.Context, in *ExportTicketsRequest, opts ...grpc.CallOption) (QueryService_ExportTicketsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QueryService_serviceDesc.Streams[3], "/openmatch.QueryService/ExportTickets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queryServiceExportTicketsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QueryService_ExportTicketsClient interface {
+	Recv() (*ExportTicketsResponse, error)
+	grpc.ClientStream
+}
+
+type queryServiceExportTicketsClient struct {
+	grpc.ClientStream
+}
+
+func (x *queryServiceExportTicketsClient) Recv() (*ExportTicketsResponse, error) {
+	m := new(ExportTicketsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *queryServiceClient) QueryServerCapacity(ctx context.Context, in *QueryServerCapacityRequest, opts ...grpc.CallOption) (*QueryServerCapacityResponse, error) {
+	out := new(QueryServerCapacityResponse)
+	err := c.cc.Invoke(ctx, "/openmatch.QueryService/QueryServerCapacity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // QueryServiceServer is the server API for QueryService service.
 type QueryServiceServer interface {
 	// QueryTickets gets a list of Tickets that match all Filters of the input Pool.
@@ -712,6 +1179,17 @@ type QueryServiceServer interface {
 	// BETA FEATURE WARNING:  This call and the associated Request and Response
 	// messages are not finalized and still subject to possible change or removal.
 	QueryBackfills(*QueryBackfillsRequest, QueryService_QueryBackfillsServer) error
+	// ExportTickets streams every ticket in the state storage, optionally
+	// filtered by a Pool, for building external indexes, analytics snapshots and
+	// migrations.  Tickets are read from the state storage in batches of
+	// `queryPageSize` as the caller receives them, so a slow caller holds back
+	// the export instead of buffering it.  Tickets created after the export
+	// starts are not exported.
+	//   - Only allowed for the clients listed in the `queryAuditClients` config.
+	ExportTickets(*ExportTicketsRequest, QueryService_ExportTicketsServer) error
+	// QueryServerCapacity returns the unexpired game server capacity published by
+	// allocators.  No capacities means the capacity registry is not in use.
+	QueryServerCapacity(context.Context, *QueryServerCapacityRequest) (*QueryServerCapacityResponse, error)
 }
 
 // UnimplementedQueryServiceServer can be embedded to have forward compatible implementations.
@@ -727,6 +1205,12 @@ func (*UnimplementedQueryServiceServer) QueryTicketIds(*QueryTicketIdsRequest, Q
 func (*UnimplementedQueryServiceServer) QueryBackfills(*QueryBackfillsRequest, QueryService_QueryBackfillsServer) error {
 	return status.Errorf(codes.Unimplemented, "method QueryBackfills not implemented")
 }
+func (*UnimplementedQueryServiceServer) ExportTickets(*ExportTicketsRequest, QueryService_ExportTicketsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTickets not implemented")
+}
+func (*UnimplementedQueryServiceServer) QueryServerCapacity(context.Context, *QueryServerCapacityRequest) (*QueryServerCapacityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryServerCapacity not implemented")
+}
 
 func RegisterQueryServiceServer(s *grpc.Server, srv QueryServiceServer) {
 	s.RegisterService(&_QueryService_serviceDesc, srv)
@@ -795,10 +1279,54 @@ func (x *queryServiceQueryBackfillsServer) Send(m *QueryBackfillsResponse) error
 	return x.ServerStream.SendMsg(m)
 }
 
+func _QueryService_ExportTickets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTicketsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServiceServer).ExportTickets(m, &queryServiceExportTicketsServer{stream})
+}
+
+type QueryService_ExportTicketsServer interface {
+	Send(*ExportTicketsResponse) error
+	grpc.ServerStream
+}
+
+type queryServiceExportTicketsServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryServiceExportTicketsServer) Send(m *ExportTicketsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _QueryService_QueryServerCapacity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryServerCapacityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).QueryServerCapacity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/openmatch.QueryService/QueryServerCapacity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).QueryServerCapacity(ctx, req.(*QueryServerCapacityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _QueryService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "openmatch.QueryService",
 	HandlerType: (*QueryServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryServerCapacity",
+			Handler:    _QueryService_QueryServerCapacity_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "QueryTickets",
@@ -815,6 +1343,11 @@ var _QueryService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _QueryService_QueryBackfills_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExportTickets",
+			Handler:       _QueryService_ExportTickets_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/query.proto",
 }
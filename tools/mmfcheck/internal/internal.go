@@ -0,0 +1,523 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package internal holds the contract checks run by mmfcheck against a match
+// function, and the fake query service the match function reads tickets from.
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"open-match.dev/open-match/internal/filter"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// maxProposalSize is the largest RunResponse Open Match receives, gRPC's
+// default limit, which the backend's match function and synchronizer clients
+// keep.
+const maxProposalSize = 4 << 20
+
+// UnavailablePool is the name of the pool the fake query service fails
+// queries of, with codes.Unavailable.
+const UnavailablePool = "mmfcheck-unavailable"
+
+// Params for running the checks against a match function.
+type Params struct {
+	// Type is how the match function is called, FunctionConfig_GRPC or
+	// FunctionConfig_REST.
+	Type pb.FunctionConfig_Type
+	// Address is the host:port of the match function.
+	Address string
+	// Tickets is the number of tickets served by the fake query service.
+	Tickets int
+	// Seed seeds the search fields of the tickets, so that runs with the same
+	// seed serve the same tickets.
+	Seed int64
+	// PageSize is the most tickets in one response of the fake query service.
+	// Small pages check that the match function reads every page.
+	PageSize int
+	// Timeout bounds each call of the match function.
+	Timeout time.Duration
+}
+
+// Result is the outcome of one check case.
+type Result struct {
+	// Case is the name of the case.
+	Case string
+	// Failures explain how the match function broke the contract, empty if
+	// it passed.
+	Failures []string
+}
+
+// Passed returns true if the match function passed the case.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// checkCase is a canned profile the match function is run with.
+type checkCase struct {
+	name    string
+	profile *pb.MatchProfile
+	// wantError is set if the match function must fail the call.
+	wantError bool
+}
+
+var checkCases = []checkCase{
+	{
+		name: "multiple pools",
+		profile: &pb.MatchProfile{
+			Name: "mmfcheck-pools",
+			Pools: []*pb.Pool{
+				{
+					Name:                "casual",
+					StringEqualsFilters: []*pb.StringEqualsFilter{{StringArg: "mode", Value: "casual"}},
+				},
+				{
+					Name:                "ranked",
+					StringEqualsFilters: []*pb.StringEqualsFilter{{StringArg: "mode", Value: "ranked"}},
+					DoubleRangeFilters:  []*pb.DoubleRangeFilter{{DoubleArg: "skill", Min: 0, Max: 50}},
+				},
+			},
+		},
+	},
+	{
+		name: "tag pool",
+		profile: &pb.MatchProfile{
+			Name: "mmfcheck-tags",
+			Pools: []*pb.Pool{
+				{
+					Name:              "beginners",
+					TagPresentFilters: []*pb.TagPresentFilter{{Tag: "beginner"}},
+				},
+			},
+		},
+	},
+	{
+		name: "empty pool",
+		profile: &pb.MatchProfile{
+			Name: "mmfcheck-empty",
+			Pools: []*pb.Pool{
+				{
+					Name:               "nobody",
+					DoubleRangeFilters: []*pb.DoubleRangeFilter{{DoubleArg: "skill", Min: 1000, Max: 2000}},
+				},
+			},
+		},
+	},
+	{
+		name:    "no pools",
+		profile: &pb.MatchProfile{Name: "mmfcheck-no-pools"},
+	},
+	{
+		name: "query failure",
+		profile: &pb.MatchProfile{
+			Name:  "mmfcheck-query-failure",
+			Pools: []*pb.Pool{{Name: UnavailablePool}},
+		},
+		wantError: true,
+	},
+}
+
+// SeedTickets returns n tickets, with a "skill" double arg, a "mode" string
+// arg of "casual" or "ranked", and a "beginner" tag on low skill tickets.
+func SeedTickets(seed int64, n int) []*pb.Ticket {
+	r := rand.New(rand.NewSource(seed))
+	created := time.Unix(1600000000, 0)
+	modes := []string{"casual", "ranked"}
+
+	tickets := make([]*pb.Ticket, 0, n)
+	for i := 0; i < n; i++ {
+		createTime, _ := ptypes.TimestampProto(created.Add(time.Duration(i) * time.Second))
+		skill := r.Float64() * 100
+		t := &pb.Ticket{
+			Id: fmt.Sprintf("mmfcheck-%04d", i),
+			SearchFields: &pb.SearchFields{
+				DoubleArgs: map[string]float64{"skill": skill},
+				StringArgs: map[string]string{"mode": modes[r.Intn(len(modes))]},
+			},
+			CreateTime: createTime,
+		}
+		if skill < 30 {
+			t.SearchFields.Tags = []string{"beginner"}
+		}
+		tickets = append(tickets, t)
+	}
+	return tickets
+}
+
+// QueryService is a fake of the Open Match query service, which serves the
+// seeded tickets in pages, and records the pools queried, the tickets of the
+// pages sent, and the streams which ended before their last page was sent.
+type QueryService struct {
+	pb.UnimplementedQueryServiceServer
+
+	tickets  []*pb.Ticket
+	pageSize int
+
+	mu         sync.Mutex
+	pools      []*pb.Pool
+	returned   map[string]bool
+	unfinished []string
+	active     int
+}
+
+// NewQueryService returns a query service serving the tickets, pageSize at a
+// time.
+func NewQueryService(tickets []*pb.Ticket, pageSize int) *QueryService {
+	return &QueryService{
+		tickets:  tickets,
+		pageSize: pageSize,
+		returned: make(map[string]bool),
+	}
+}
+
+// reset forgets the queries recorded so far.
+func (q *QueryService) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pools = nil
+	q.returned = make(map[string]bool)
+	q.unfinished = nil
+}
+
+// wait waits up to the timeout for the streams in progress to end, so that
+// streams the match function stopped reading are recorded before the check.
+func (q *QueryService) wait(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		q.mu.Lock()
+		active := q.active
+		q.mu.Unlock()
+		if active == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// query records the pool, and returns its tickets.  The caller must call done
+// once the stream ends, even on error.
+func (q *QueryService) query(pool *pb.Pool) ([]*pb.Ticket, error) {
+	q.mu.Lock()
+	q.pools = append(q.pools, pool)
+	q.active++
+	q.mu.Unlock()
+
+	if pool.GetName() == UnavailablePool {
+		return nil, status.Error(codes.Unavailable, "mmfcheck failed the query on purpose")
+	}
+	pf, err := filter.NewPoolFilter(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*pb.Ticket
+	for _, t := range q.tickets {
+		if pf.In(t) {
+			tickets = append(tickets, t)
+		}
+	}
+	return tickets, nil
+}
+
+func (q *QueryService) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active--
+}
+
+// sendPages sends the tickets pageSize at a time, recording the tickets of
+// each page sent.  gRPC buffers pages up to the stream's flow control window,
+// so a match function which stops reading the stream early is only caught
+// once the unread pages fill the window, or it cancels the stream.
+func (q *QueryService) sendPages(pool *pb.Pool, tickets []*pb.Ticket, send func([]*pb.Ticket) error) error {
+	for start := 0; start < len(tickets); start += q.pageSize {
+		end := start + q.pageSize
+		if end > len(tickets) {
+			end = len(tickets)
+		}
+		err := send(tickets[start:end])
+
+		q.mu.Lock()
+		if err != nil {
+			q.unfinished = append(q.unfinished, pool.GetName())
+			q.mu.Unlock()
+			return err
+		}
+		for _, t := range tickets[start:end] {
+			q.returned[t.GetId()] = true
+		}
+		q.mu.Unlock()
+	}
+	return nil
+}
+
+// QueryTickets streams the tickets of the pool.
+func (q *QueryService) QueryTickets(req *pb.QueryTicketsRequest, stream pb.QueryService_QueryTicketsServer) error {
+	defer q.done()
+	tickets, err := q.query(req.GetPool())
+	if err != nil {
+		return err
+	}
+	return q.sendPages(req.GetPool(), tickets, func(page []*pb.Ticket) error {
+		return stream.Send(&pb.QueryTicketsResponse{Tickets: page})
+	})
+}
+
+// QueryTicketIds streams the ids of the tickets of the pool.
+func (q *QueryService) QueryTicketIds(req *pb.QueryTicketIdsRequest, stream pb.QueryService_QueryTicketIdsServer) error {
+	defer q.done()
+	tickets, err := q.query(req.GetPool())
+	if err != nil {
+		return err
+	}
+	return q.sendPages(req.GetPool(), tickets, func(page []*pb.Ticket) error {
+		resp := &pb.QueryTicketIdsResponse{}
+		for _, t := range page {
+			resp.Ids = append(resp.Ids, t.GetId())
+		}
+		return stream.Send(resp)
+	})
+}
+
+// QueryBackfills returns no backfills.
+func (q *QueryService) QueryBackfills(req *pb.QueryBackfillsRequest, stream pb.QueryService_QueryBackfillsServer) error {
+	_, err := filter.NewPoolFilter(req.GetPool())
+	return err
+}
+
+// Run serves the fake query service on lis, and runs the match function with
+// each of the canned profiles, checking that it keeps to the contract Open
+// Match expects of it.
+func Run(ctx context.Context, params Params, lis net.Listener) ([]Result, error) {
+	if params.PageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", params.PageSize)
+	}
+	query := NewQueryService(SeedTickets(params.Seed, params.Tickets), params.PageSize)
+	server := grpc.NewServer()
+	pb.RegisterQueryServiceServer(server, query)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	run, closer, err := newRunner(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	results := make([]Result, 0, len(checkCases))
+	for _, c := range checkCases {
+		query.reset()
+		callCtx, cancel := context.WithTimeout(ctx, params.Timeout)
+		proposals, err := run(callCtx, c.profile)
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		query.wait(params.Timeout)
+		results = append(results, check(c, proposals, err, query, params.Timeout))
+	}
+	return results, nil
+}
+
+// check returns the failures of the match function's call for the case.
+func check(c checkCase, proposals []*pb.Match, err error, query *QueryService, timeout time.Duration) Result {
+	r := Result{Case: c.name}
+	fail := func(format string, args ...interface{}) {
+		r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+	}
+
+	// Open Match fails FetchMatches alike for every error status of the match
+	// function, so only whether the call failed is checked, not its code.
+	switch {
+	case status.Code(err) == codes.DeadlineExceeded:
+		fail("the call did not end within %s", timeout)
+	case c.wantError && err == nil:
+		fail("the call ended with OK although the match function's query failed, it must end with an error status")
+	case !c.wantError && err != nil:
+		fail("the call failed: %v", err)
+	}
+
+	query.mu.Lock()
+	defer query.mu.Unlock()
+
+	// The failure is only the match function's own if it queried.
+	if c.wantError && len(query.pools) == 0 {
+		fail("the match function did not query the profile's pools")
+	}
+	for _, name := range query.unfinished {
+		fail("the match function stopped reading the tickets of pool %q before the end of the stream", name)
+	}
+	for _, pool := range query.pools {
+		found := false
+		for _, p := range c.profile.GetPools() {
+			found = found || proto.Equal(pool, p)
+		}
+		if !found {
+			fail("queried pool %q, which is not one of the profile's pools", pool.GetName())
+		}
+	}
+
+	matchIDs := make(map[string]bool)
+	for i, m := range proposals {
+		if m == nil {
+			fail("response %d has no proposal", i)
+			continue
+		}
+		if m.GetMatchId() == "" {
+			fail("proposal %d has no match_id", i)
+		} else if matchIDs[m.GetMatchId()] {
+			fail("match_id %q is used by several proposals, Open Match fails the whole FetchMatches call", m.GetMatchId())
+		}
+		matchIDs[m.GetMatchId()] = true
+
+		if size := proto.Size(&pb.RunResponse{Proposal: m}); size > maxProposalSize {
+			fail("proposal %q is %d bytes, more than the %d bytes of a gRPC message Open Match receives", m.GetMatchId(), size, maxProposalSize)
+		}
+		if m.GetMatchProfile() != c.profile.GetName() {
+			fail("proposal %q has match_profile %q, expected the name of the profile %q", m.GetMatchId(), m.GetMatchProfile(), c.profile.GetName())
+		}
+
+		ticketIDs := make(map[string]bool)
+		for _, t := range m.GetTickets() {
+			if !query.returned[t.GetId()] {
+				fail("proposal %q has ticket %q, which the query service did not return for the profile's pools", m.GetMatchId(), t.GetId())
+			}
+			if ticketIDs[t.GetId()] {
+				fail("proposal %q has ticket %q more than once", m.GetMatchId(), t.GetId())
+			}
+			ticketIDs[t.GetId()] = true
+		}
+	}
+	return r
+}
+
+// runFunc calls the match function with the profile, and returns the
+// proposals it streamed before the call ended.
+type runFunc func(ctx context.Context, profile *pb.MatchProfile) ([]*pb.Match, error)
+
+func newRunner(ctx context.Context, params Params) (runFunc, func(), error) {
+	switch params.Type {
+	case pb.FunctionConfig_GRPC:
+		// Oversized proposals are received, to be reported by the check.
+		conn, err := grpc.DialContext(ctx, params.Address, grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to dial match function %s: %w", params.Address, err)
+		}
+		return grpcRunner(pb.NewMatchFunctionClient(conn)), func() { conn.Close() }, nil
+	case pb.FunctionConfig_REST:
+		return httpRunner(&http.Client{}, "http://"+params.Address), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported match function type %s", params.Type)
+	}
+}
+
+func grpcRunner(client pb.MatchFunctionClient) runFunc {
+	return func(ctx context.Context, profile *pb.MatchProfile) ([]*pb.Match, error) {
+		stream, err := client.Run(ctx, &pb.RunRequest{Profile: profile})
+		if err != nil {
+			return nil, err
+		}
+
+		var proposals []*pb.Match
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return proposals, nil
+			}
+			if err != nil {
+				return proposals, err
+			}
+			proposals = append(proposals, resp.GetProposal())
+		}
+	}
+}
+
+// httpRunner calls the match function the way the backend does over REST: a
+// POST of the JSON RunRequest, answered with a stream of JSON objects, each
+// holding a RunResponse result or an error.
+func httpRunner(client *http.Client, baseURL string) runFunc {
+	return func(ctx context.Context, profile *pb.MatchProfile) ([]*pb.Match, error) {
+		var m jsonpb.Marshaler
+		body, err := m.MarshalToString(&pb.RunRequest{Profile: profile})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest("POST", baseURL+"/v1/matchfunction:run", strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, status.Error(codes.DeadlineExceeded, err.Error())
+			}
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, status.Errorf(codes.Unknown, "match function responded with HTTP status %s", resp.Status)
+		}
+
+		var proposals []*pb.Match
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var item struct {
+				Result json.RawMessage        `json:"result"`
+				Error  map[string]interface{} `json:"error"`
+			}
+			err := dec.Decode(&item)
+			if err == io.EOF {
+				return proposals, nil
+			}
+			if err != nil {
+				if ctx.Err() == context.DeadlineExceeded {
+					return proposals, status.Error(codes.DeadlineExceeded, err.Error())
+				}
+				return proposals, fmt.Errorf("response stream is not a sequence of JSON objects: %w", err)
+			}
+			if len(item.Error) != 0 {
+				return proposals, status.Errorf(codes.Unknown, "match function streamed an error: %v", item.Error)
+			}
+			if len(item.Result) == 0 {
+				return proposals, fmt.Errorf("response stream has an object with neither a result nor an error")
+			}
+			runResp := &pb.RunResponse{}
+			err = jsonpb.UnmarshalString(string(item.Result), runResp)
+			if err != nil {
+				return proposals, fmt.Errorf("result %s is not a RunResponse: %w", item.Result, err)
+			}
+			proposals = append(proposals, runResp.GetProposal())
+		}
+	}
+}
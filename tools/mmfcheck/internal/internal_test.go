@@ -0,0 +1,271 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"open-match.dev/open-match/pkg/matchfunction"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// pairingMMF pairs the tickets of the profile's pools.  buggy breaks the
+// contract every way mmfcheck checks.
+type pairingMMF struct {
+	query pb.QueryServiceClient
+	buggy bool
+}
+
+func (m *pairingMMF) Run(req *pb.RunRequest, stream pb.MatchFunction_RunServer) error {
+	poolTickets, err := matchfunction.QueryPools(stream.Context(), m.query, req.GetProfile().GetPools())
+	if err != nil && !m.buggy {
+		return err
+	}
+
+	var tickets []*pb.Ticket
+	seen := make(map[string]bool)
+	for _, pool := range req.GetProfile().GetPools() {
+		for _, t := range poolTickets[pool.GetName()] {
+			if !seen[t.GetId()] {
+				seen[t.GetId()] = true
+				tickets = append(tickets, t)
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(tickets); i += 2 {
+		match := &pb.Match{
+			MatchId:      fmt.Sprintf("%s-%d", req.GetProfile().GetName(), i),
+			MatchProfile: req.GetProfile().GetName(),
+			Tickets:      tickets[i : i+2],
+		}
+		if m.buggy {
+			match.MatchId = "same"
+			match.MatchProfile = "pairing"
+			match.Tickets = append(match.Tickets, &pb.Ticket{Id: "invented"})
+		}
+		err = stream.Send(&pb.RunResponse{Proposal: match})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.buggy && len(tickets) > 0 {
+		padding, err := ptypes.MarshalAny(&wrappers.BytesValue{Value: make([]byte, 5<<20)})
+		if err != nil {
+			return err
+		}
+		return stream.Send(&pb.RunResponse{Proposal: &pb.Match{
+			MatchId:      "oversized",
+			MatchProfile: req.GetProfile().GetName(),
+			Extensions:   map[string]*any.Any{"padding": padding},
+		}})
+	}
+	return nil
+}
+
+// partialMMF reads the first page of the tickets of each of the profile's
+// pools, and proposes no matches.
+type partialMMF struct {
+	query pb.QueryServiceClient
+}
+
+func (m *partialMMF) Run(req *pb.RunRequest, stream pb.MatchFunction_RunServer) error {
+	for _, pool := range req.GetProfile().GetPools() {
+		qstream, err := m.query.QueryTickets(stream.Context(), &pb.QueryTicketsRequest{Pool: pool})
+		if err != nil {
+			return err
+		}
+		if _, err = qstream.Recv(); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// startMMF serves the match function over gRPC, querying the query service on
+// queryLis, and returns its address.
+func startMMF(t *testing.T, queryLis net.Listener, buggy bool) string {
+	return startMMFServer(t, queryLis, func(query pb.QueryServiceClient) pb.MatchFunctionServer {
+		return &pairingMMF{query: query, buggy: buggy}
+	})
+}
+
+// startMMFServer serves the match function made by newMMF over gRPC, querying
+// the query service on queryLis, and returns its address.
+func startMMFServer(t *testing.T, queryLis net.Listener, newMMF func(pb.QueryServiceClient) pb.MatchFunctionServer) string {
+	// A fixed flow control window, rather than one grown by gRPC, so that
+	// unread pages block the query service.
+	conn, err := grpc.Dial(queryLis.Addr().String(), grpc.WithInsecure(), grpc.WithInitialWindowSize(1<<16), grpc.WithInitialConnWindowSize(1<<16))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMatchFunctionServer(server, newMMF(pb.NewQueryServiceClient(conn)))
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// startHTTPMMF serves the gRPC match function at grpcAddress over REST, and
+// returns its address.
+func startHTTPMMF(t *testing.T, grpcAddress string) string {
+	mux := runtime.NewServeMux()
+	err := pb.RegisterMatchFunctionHandlerFromEndpoint(context.Background(), mux, grpcAddress, []grpc.DialOption{grpc.WithInsecure()})
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := &http.Server{Handler: mux}
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(func() { server.Close() })
+	return lis.Addr().String()
+}
+
+func newParams(typ pb.FunctionConfig_Type, address string) Params {
+	return Params{
+		Type:     typ,
+		Address:  address,
+		Tickets:  50,
+		Seed:     1,
+		PageSize: 7,
+		Timeout:  5 * time.Second,
+	}
+}
+
+func TestRunConforming(t *testing.T) {
+	for _, typ := range []pb.FunctionConfig_Type{pb.FunctionConfig_GRPC, pb.FunctionConfig_REST} {
+		t.Run(typ.String(), func(t *testing.T) {
+			queryLis, err := net.Listen("tcp", "localhost:0")
+			require.NoError(t, err)
+			address := startMMF(t, queryLis, false)
+			if typ == pb.FunctionConfig_REST {
+				address = startHTTPMMF(t, address)
+			}
+
+			results, err := Run(context.Background(), newParams(typ, address), queryLis)
+			require.NoError(t, err)
+			require.Len(t, results, len(checkCases))
+			for _, r := range results {
+				require.True(t, r.Passed(), "%s: %v", r.Case, r.Failures)
+			}
+		})
+	}
+}
+
+func TestRunBuggy(t *testing.T) {
+	queryLis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	address := startMMF(t, queryLis, true)
+
+	results, err := Run(context.Background(), newParams(pb.FunctionConfig_GRPC, address), queryLis)
+	require.NoError(t, err)
+
+	failures := make(map[string][]string)
+	for _, r := range results {
+		failures[r.Case] = r.Failures
+	}
+	require.Contains(t, failures["multiple pools"], `match_id "same" is used by several proposals, Open Match fails the whole FetchMatches call`)
+	require.Contains(t, failures["multiple pools"], `proposal "same" has match_profile "pairing", expected the name of the profile "mmfcheck-pools"`)
+	require.Contains(t, failures["multiple pools"], `proposal "same" has ticket "invented", which the query service did not return for the profile's pools`)
+	oversized := false
+	for _, f := range failures["multiple pools"] {
+		oversized = oversized || (strings.HasPrefix(f, `proposal "oversized" is `) && strings.HasSuffix(f, " bytes, more than the 4194304 bytes of a gRPC message Open Match receives"))
+	}
+	require.True(t, oversized, "%v", failures["multiple pools"])
+	require.Equal(t, []string{"the call ended with OK although the match function's query failed, it must end with an error status"}, failures["query failure"])
+	require.Empty(t, failures["empty pool"])
+	require.Empty(t, failures["no pools"])
+}
+
+func TestRunUnreadStream(t *testing.T) {
+	queryLis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	address := startMMFServer(t, queryLis, func(query pb.QueryServiceClient) pb.MatchFunctionServer {
+		return &partialMMF{query: query}
+	})
+
+	// Enough unread pages to fill the stream's flow control window, and the
+	// query service's write buffer behind it.
+	params := newParams(pb.FunctionConfig_GRPC, address)
+	params.Tickets = 20000
+	params.PageSize = 1
+	results, err := Run(context.Background(), params, queryLis)
+	require.NoError(t, err)
+
+	failures := make(map[string][]string)
+	for _, r := range results {
+		failures[r.Case] = r.Failures
+	}
+	require.Contains(t, failures["multiple pools"], `the match function stopped reading the tickets of pool "casual" before the end of the stream`)
+	require.Contains(t, failures["multiple pools"], `the match function stopped reading the tickets of pool "ranked" before the end of the stream`)
+	require.Empty(t, failures["empty pool"])
+	require.Empty(t, failures["no pools"])
+}
+
+func TestRunTimeout(t *testing.T) {
+	queryLis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	// A match function which never ends its calls.
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMatchFunctionServer(server, hangingMMF{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	params := newParams(pb.FunctionConfig_GRPC, lis.Addr().String())
+	params.Timeout = 100 * time.Millisecond
+	results, err := Run(context.Background(), params, queryLis)
+	require.NoError(t, err)
+	for _, r := range results {
+		require.Contains(t, r.Failures, "the call did not end within 100ms", r.Case)
+	}
+}
+
+type hangingMMF struct{}
+
+func (hangingMMF) Run(req *pb.RunRequest, stream pb.MatchFunction_RunServer) error {
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func TestSeedTickets(t *testing.T) {
+	require.Equal(t, SeedTickets(3, 20), SeedTickets(3, 20))
+	require.NotEqual(t, SeedTickets(3, 20), SeedTickets(4, 20))
+	require.Len(t, SeedTickets(3, 20), 20)
+}
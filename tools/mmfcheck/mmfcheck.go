@@ -0,0 +1,108 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main is the mmfcheck tool, which checks that a match function, in
+// any language, keeps to the contract Open Match expects of it.  It serves a
+// fake query service with a seeded set of tickets, which the match function
+// must be configured to query, and runs the match function with canned
+// profiles.  It exits with status 1 if the match function fails any check.
+//
+// The checks are that each call ends within the timeout, fails when a query
+// fails and succeeds otherwise, queries only the profile's pools and reads
+// their streams to the end, and streams proposals of at most 4MB each, with
+// unique match ids, the profile's name, and distinct tickets returned by the
+// queries.  The code of a failed call isn't checked, as Open Match treats
+// every error status alike.
+//
+// For example, with a match function serving gRPC on port 50502 and querying
+// localhost:50503:
+//
+//	mmfcheck --mmf=localhost:50502 --type=grpc --queryport=50503
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"open-match.dev/open-match/pkg/pb"
+	mmfcheckInternal "open-match.dev/open-match/tools/mmfcheck/internal"
+)
+
+var (
+	mmfFlag       = flag.String("mmf", "localhost:50502", "host:port of the match function.")
+	typeFlag      = flag.String("type", "grpc", "How the match function is called, grpc or rest.")
+	queryPortFlag = flag.Int("queryport", 50503, "Port the fake query service listens on, which the match function must query.")
+	ticketsFlag   = flag.Int("tickets", 100, "Number of tickets served by the fake query service.")
+	seedFlag      = flag.Int64("seed", 1, "Seed of the tickets' search fields.")
+	pageSizeFlag  = flag.Int("pagesize", 10, "Most tickets in one response of the fake query service.")
+	timeoutFlag   = flag.Duration("timeout", 10*time.Second, "Time each call of the match function has to end.")
+)
+
+func main() {
+	flag.Parse()
+	passed, err := checkViaFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+func checkViaFlags() (bool, error) {
+	params := mmfcheckInternal.Params{
+		Address:  *mmfFlag,
+		Tickets:  *ticketsFlag,
+		Seed:     *seedFlag,
+		PageSize: *pageSizeFlag,
+		Timeout:  *timeoutFlag,
+	}
+	switch *typeFlag {
+	case "grpc":
+		params.Type = pb.FunctionConfig_GRPC
+	case "rest":
+		params.Type = pb.FunctionConfig_REST
+	default:
+		return false, fmt.Errorf("--type must be grpc or rest, got %q", *typeFlag)
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *queryPortFlag))
+	if err != nil {
+		return false, fmt.Errorf("cannot listen on query port %d: %w", *queryPortFlag, err)
+	}
+
+	results, err := mmfcheckInternal.Run(context.Background(), params, lis)
+	if err != nil {
+		return false, err
+	}
+
+	passed := true
+	for _, r := range results {
+		if r.Passed() {
+			fmt.Printf("PASS %s\n", r.Case)
+			continue
+		}
+		passed = false
+		fmt.Printf("FAIL %s\n", r.Case)
+		for _, f := range r.Failures {
+			fmt.Printf("    %s\n", f)
+		}
+	}
+	return passed, nil
+}